@@ -0,0 +1,120 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// DefaultBatchWorkers is the batch signing concurrency when batch_workers is unset
+	DefaultBatchWorkers = 8
+	// DefaultMaxBatchSize caps the items of a batch when max_batch_size is unset
+	DefaultMaxBatchSize = 100
+)
+
+type BuildAndSignFunc func(ctx context.Context, req *wallet.BuildAndSignTransactionRequest) (*wallet.BuildAndSignTransactionResponse, error)
+
+// CheckBatchSize rejects empty batches and batches over maxBatchSize items.
+func CheckBatchSize(size int, maxBatchSize int) error {
+	if maxBatchSize <= 0 {
+		maxBatchSize = DefaultMaxBatchSize
+	}
+	if size == 0 {
+		return errors.New("empty batch")
+	}
+	if size > maxBatchSize {
+		return fmt.Errorf("batch of %d transactions exceeds max batch size %d", size, maxBatchSize)
+	}
+	return nil
+}
+
+// BuildAndSignBatch builds and signs every item of the batch with
+// buildAndSign on at most workers goroutines. A failed item is reported in
+// its own result and does not fail the rest of the batch. The batch size is
+// checked by the dispatcher with CheckBatchSize.
+func BuildAndSignBatch(ctx context.Context, req *wallet.BuildAndSignBatchTransactionRequest, workers int, buildAndSign BuildAndSignFunc) *wallet.BuildAndSignBatchTransactionResponse {
+	if workers <= 0 {
+		workers = DefaultBatchWorkers
+	}
+	results := make([]*wallet.TransactionWithSign, len(req.TxMsg))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(req.TxMsg); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = buildAndSignItem(ctx, req, req.TxMsg[i], buildAndSign)
+			}
+		}()
+	}
+	for i := range req.TxMsg {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return BatchResponse(results)
+}
+
+// BatchResponse reports the per item results of a batch, the batch itself
+// succeeds even when some of its items failed.
+func BatchResponse(results []*wallet.TransactionWithSign) *wallet.BuildAndSignBatchTransactionResponse {
+	failed := 0
+	for _, result := range results {
+		if result.Code != wallet.ReturnCode_SUCCESS {
+			failed++
+		}
+	}
+	return &wallet.BuildAndSignBatchTransactionResponse{
+		Code:       wallet.ReturnCode_SUCCESS,
+		Message:    fmt.Sprintf("sign batch transaction finished, %d of %d failed", failed, len(results)),
+		TxWithSign: results,
+	}
+}
+
+func buildAndSignItem(ctx context.Context, batch *wallet.BuildAndSignBatchTransactionRequest, txMsg *wallet.TransactionMessage, buildAndSign BuildAndSignFunc) (result *wallet.TransactionWithSign) {
+	result = &wallet.TransactionWithSign{
+		Code:      wallet.ReturnCode_ERROR,
+		PublicKey: txMsg.PublicKey,
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			log.Error("panic error", "msg", e)
+			log.Debug(string(debug.Stack()))
+			result.Message = fmt.Sprintf("panic: %v", e)
+		}
+	}()
+	if err := ctx.Err(); err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	resp, err := buildAndSign(ctx, &wallet.BuildAndSignTransactionRequest{
+		ConsumerToken:    batch.ConsumerToken,
+		ChainName:        batch.ChainName,
+		Network:          batch.Network,
+		PublicKey:        txMsg.PublicKey,
+		WalletKeyHash:    txMsg.WalletKeyHash,
+		RiskKeyHash:      txMsg.RiskKeyHash,
+		TxBase64Body:     txMsg.TxBase64Body,
+		WalletKeyVersion: txMsg.WalletKeyVersion,
+		RiskKeyVersion:   txMsg.RiskKeyVersion,
+	})
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	result.Code = resp.Code
+	result.Message = resp.Message
+	result.TxMessageHash = resp.TxMessageHash
+	result.TxHash = resp.TxHash
+	result.SignedTx = resp.SignedTx
+	result.DecodedCall = resp.DecodedCall
+	result.PartialSignatures = resp.PartialSignatures
+	return result
+}
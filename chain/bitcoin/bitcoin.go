@@ -7,8 +7,12 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/DQYXACML/wallet-sign/chain"
+	"github.com/DQYXACML/wallet-sign/config"
 	"github.com/DQYXACML/wallet-sign/leveldb"
 	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
 	"github.com/DQYXACML/wallet-sign/ssm"
@@ -16,17 +20,30 @@ import (
 	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
-	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
-	"github.com/btcsuite/btcd/wire"
 	"github.com/ethereum/go-ethereum/log"
 )
 
 const ChainName = "Bitcoin"
 
+// hdPurposes maps the address formats to their BIP44/49/84/86 purpose
+var hdPurposes = map[string]uint32{
+	"p2pkh":  44,
+	"p2sh":   49,
+	"p2wpkh": 84,
+	"p2tr":   86,
+}
+
 type ChainAdaptor struct {
-	db     *leveldb.Keys
-	signer ssm.Signer
+	db           *leveldb.Keys
+	signer       ssm.Signer
+	hdEnable     bool
+	batchWorkers int
+	// maxFeeRate in sat/vB, 0 disables the cap
+	maxFeeRate        uint64
+	changeAddresses   map[string]btcutil.Address
+	musig2SessionTTL  time.Duration
+	maxMusig2Sessions int
 }
 
 func (c *ChainAdaptor) SignTransactionMessage(ctx context.Context, req *wallet.SignTransactionMessageRequest) (*wallet.SignTransactionMessageResponse, error) {
@@ -92,29 +109,32 @@ func (c *ChainAdaptor) CreateKeyPairsExportPublicKeyList(ctx context.Context, re
 	resp := &wallet.CreateKeyPairAndExportPublicKeyResponse{
 		Code: wallet.ReturnCode_ERROR,
 	}
-	if req.KeyNum > 10000 {
-		resp.Message = "Number must be less than 100000"
+	if req.KeyNum > chain.MaxKeyNum {
+		resp.Message = fmt.Sprintf("Number must be less than %d", chain.MaxKeyNum)
 		return resp, nil
 	}
 	var keyList []leveldb.Key
 	var retKeyList []*wallet.ExportPublicKey
 
-	for i := 0; i < int(req.KeyNum); i++ {
-		priKeyStr, pubKeyStr, compressPubkeyStr, err := c.signer.CreateKeyPair()
-		if err != nil {
-			resp.Message = "create key pairs fail"
-			return resp, nil
-		}
-		keyItem := leveldb.Key{
-			PrivateKey: priKeyStr,
-			PubKey:     pubKeyStr,
-		}
+	params, err := networkParams(req.Network)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	keyPairs, err := chain.CreateKeyPairs(c.db, c.signer, c.hdPathTemplate("p2pkh", params), req.KeyNum)
+	if err != nil {
+		log.Error("create key pairs fail", "err", err)
+		resp.Message = "create key pairs fail"
+		return resp, nil
+	}
+	for _, keyPair := range keyPairs {
+		keyPair.Network = params.Name
 		pukAddressItem := &wallet.ExportPublicKey{
-			CompressPublicKey: compressPubkeyStr,
-			PublicKey:         pubKeyStr,
+			CompressPublicKey: keyPair.CompressPubKey,
+			PublicKey:         keyPair.PubKey,
 		}
 		retKeyList = append(retKeyList, pukAddressItem)
-		keyList = append(keyList, keyItem)
+		keyList = append(keyList, keyPair.Key)
 	}
 	isOk := c.db.StoreKeys(keyList)
 	if !isOk {
@@ -135,71 +155,36 @@ func (c *ChainAdaptor) CreateKeyPairsWithAddresses(ctx context.Context, req *wal
 	var keyList []leveldb.Key
 	var retKeyWithAddressList []*wallet.ExportPublicKeyWithAddress
 
-	for i := 0; i < int(req.KeyNum); i++ {
-		priKeyStr, pubKeyStr, compressPubkeyStr, err := c.signer.CreateKeyPair()
+	if _, ok := hdPurposes[req.AddressFormat]; !ok {
+		resp.Message = "Do not support address type"
+		return resp, nil
+	}
+	params, err := networkParams(req.Network)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	keyPairs, err := chain.CreateKeyPairs(c.db, c.signer, c.hdPathTemplate(req.AddressFormat, params), req.KeyNum)
+	if err != nil {
+		log.Error("create key pairs fail", "err", err)
+		resp.Message = "create key pairs fail"
+		return resp, nil
+	}
+	for _, keyPair := range keyPairs {
+		keyPair.Network = params.Name
+		address, err := pubKeyAddress(req.AddressFormat, keyPair.CompressPubKey, params)
 		if err != nil {
-			resp.Message = "create key pairs fail"
-			return resp, nil
-		}
-		keyItem := leveldb.Key{
-			PrivateKey: priKeyStr,
-			PubKey:     pubKeyStr,
-		}
-		var address string
-		compressedPubKeyBytes, _ := hex.DecodeString(compressPubkeyStr)
-		pubKeyHash := btcutil.Hash160(compressedPubKeyBytes)
-		switch req.AddressFormat {
-		case "p2pkh":
-			p2pkhAddr, err := btcutil.NewAddressPubKeyHash(pubKeyHash, &chaincfg.MainNetParams)
-			if err != nil {
-				resp.Message = "create p2pkh address fail"
-				return resp, nil
-			}
-			address = p2pkhAddr.EncodeAddress()
-		case "p2wpkh":
-			witnessAddr, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, &chaincfg.MainNetParams)
-			if err != nil {
-				resp.Message = "create p2wpkh fail"
-				return resp, nil
-			}
-			address = witnessAddr.EncodeAddress()
-		case "p2sh":
-			witnessAddr, _ := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, &chaincfg.MainNetParams)
-			script, err := txscript.PayToAddrScript(witnessAddr)
-			if err != nil {
-				resp.Message = "create p2sh address script fail"
-				return resp, nil
-			}
-			p2shAddr, err := btcutil.NewAddressScriptHash(script, &chaincfg.MainNetParams)
-			if err != nil {
-				resp.Message = "create p2sh address fail"
-				return resp, nil
-			}
-			address = p2shAddr.EncodeAddress()
-		case "p2tr":
-			pubKey, err := btcec.ParsePubKey(compressedPubKeyBytes)
-			if err != nil {
-				resp.Message = "parse public key fail"
-				return resp, nil
-			}
-			taprootPubKey := schnorr.SerializePubKey(pubKey)
-			taprootAddr, err := btcutil.NewAddressTaproot(taprootPubKey, &chaincfg.MainNetParams)
-			if err != nil {
-				resp.Message = "create taproot address fail"
-				return resp, nil
-			}
-			address = taprootAddr.EncodeAddress()
-		default:
-			resp.Message = "Do not support address type"
+			chain.ReleaseKeyPairs(c.db, keyPairs)
+			resp.Message = err.Error()
 			return resp, nil
 		}
 		pukAddressItem := &wallet.ExportPublicKeyWithAddress{
-			CompressPublicKey: compressPubkeyStr,
-			PublicKey:         pubKeyStr,
+			CompressPublicKey: keyPair.CompressPubKey,
+			PublicKey:         keyPair.PubKey,
 			Address:           address,
 		}
 		retKeyWithAddressList = append(retKeyWithAddressList, pukAddressItem)
-		keyList = append(keyList, keyItem)
+		keyList = append(keyList, keyPair.Key)
 	}
 
 	isOk := c.db.StoreKeys(keyList)
@@ -207,11 +192,56 @@ func (c *ChainAdaptor) CreateKeyPairsWithAddresses(ctx context.Context, req *wal
 		resp.Message = "store keys fail"
 		return resp, nil
 	}
+	resp.Code = wallet.ReturnCode_SUCCESS
 	resp.Message = "create key pairs with address success"
 	resp.PublicKeyAddresses = retKeyWithAddressList
 	return resp, nil
 }
 
+// pubKeyAddress is the address of the compressed public key in the address
+// format.
+func pubKeyAddress(addressFormat string, compressPubKey string, params *chaincfg.Params) (string, error) {
+	compressedPubKeyBytes, _ := hex.DecodeString(compressPubKey)
+	pubKeyHash := btcutil.Hash160(compressedPubKeyBytes)
+	switch addressFormat {
+	case "p2pkh":
+		p2pkhAddr, err := btcutil.NewAddressPubKeyHash(pubKeyHash, params)
+		if err != nil {
+			return "", errors.New("create p2pkh address fail")
+		}
+		return p2pkhAddr.EncodeAddress(), nil
+	case "p2wpkh":
+		witnessAddr, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, params)
+		if err != nil {
+			return "", errors.New("create p2wpkh fail")
+		}
+		return witnessAddr.EncodeAddress(), nil
+	case "p2sh":
+		witnessAddr, _ := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, params)
+		script, err := txscript.PayToAddrScript(witnessAddr)
+		if err != nil {
+			return "", errors.New("create p2sh address script fail")
+		}
+		p2shAddr, err := btcutil.NewAddressScriptHash(script, params)
+		if err != nil {
+			return "", errors.New("create p2sh address fail")
+		}
+		return p2shAddr.EncodeAddress(), nil
+	case "p2tr":
+		pubKey, err := btcec.ParsePubKey(compressedPubKeyBytes)
+		if err != nil {
+			return "", errors.New("parse public key fail")
+		}
+		taprootPubKey := schnorr.SerializePubKey(txscript.ComputeTaprootKeyNoScript(pubKey))
+		taprootAddr, err := btcutil.NewAddressTaproot(taprootPubKey, params)
+		if err != nil {
+			return "", errors.New("create taproot address fail")
+		}
+		return taprootAddr.EncodeAddress(), nil
+	}
+	return "", errors.New("Do not support address type")
+}
+
 func (c *ChainAdaptor) BuildAndSignTransaction(ctx context.Context, req *wallet.BuildAndSignTransactionRequest) (*wallet.BuildAndSignTransactionResponse, error) {
 	resp := &wallet.BuildAndSignTransactionResponse{
 		Code: wallet.ReturnCode_ERROR,
@@ -226,88 +256,153 @@ func (c *ChainAdaptor) BuildAndSignTransaction(ctx context.Context, req *wallet.
 		resp.Message = "parse json fail"
 		return resp, nil
 	}
-	txHash, buf, err := c.CalcSignHashes(bitcoinSchema.Vins, bitcoinSchema.Vouts)
+	params, err := networkParams(req.Network)
 	if err != nil {
-		resp.Message = "calc sign hashes fail"
+		resp.Message = err.Error()
 		return resp, nil
 	}
-	log.Info("calc sign hash success", "txHash", txHash, "buf", buf)
-	privKey, isOk := c.db.GetPrivKey(req.PublicKey)
-	if !isOk {
-		resp.Message = "get private key by public key fail"
+	txOuts, fee, err := c.buildOutputs(&bitcoinSchema, params)
+	if err != nil {
+		log.Error("build outputs fail", "err", err)
+		resp.Message = "invalid outputs: " + err.Error()
 		return resp, nil
 	}
-	signture, err := c.signer.SignMessage(privKey, string(buf))
+	rawTx, inputs, err := c.CalcSignHashes(bitcoinSchema.Vins, txOuts, bitcoinSchema.LockTime, req.PublicKey, params)
 	if err != nil {
-		resp.Message = "sign message fail"
+		log.Error("calc sign hashes fail", "err", err)
+		resp.Message = "calc sign hashes fail: " + err.Error()
 		return resp, nil
 	}
+	sigHashes := make([]string, len(inputs))
+	var partialSigs []*PartialSignature
+	complete := true
+	for i, input := range inputs {
+		sigs, err := c.signInput(rawTx, i, input)
+		if err != nil {
+			log.Error("sign vin fail", "index", i, "err", err)
+			resp.Message = fmt.Sprintf("sign vin %d fail: %v", i, err)
+			return resp, nil
+		}
+		sigHashes[i] = hex.EncodeToString(input.SigHash)
+		partialSigs = append(partialSigs, sigs...)
+		complete = complete && input.complete
+	}
+	if !complete {
+		// the txid of segwit multisig inputs does not depend on the witness
+		if err := c.checkFeeRate(withPlaceholderWitness(rawTx, inputs), fee); err != nil {
+			log.Error("check fee rate fail", "err", err)
+			resp.Message = err.Error()
+			return resp, nil
+		}
+		log.Info("sign tx partially", "txHash", rawTx.TxHash().String(), "partialSigs", len(partialSigs))
+		resp.Code = wallet.ReturnCode_SUCCESS
+		resp.Message = "sign tx partially, cosigner signatures missing"
+		resp.TxMessageHash = strings.Join(sigHashes, ",")
+		resp.TxHash = rawTx.TxHash().String()
+		resp.PartialSignatures = partialSignaturesJSON(partialSigs)
+		return resp, nil
+	}
+	if err := verifyInputs(rawTx, bitcoinSchema.Vins, inputs); err != nil {
+		log.Error("verify signed tx fail", "err", err)
+		resp.Message = "verify signed tx fail: " + err.Error()
+		return resp, nil
+	}
+	if err := c.checkFeeRate(rawTx, fee); err != nil {
+		log.Error("check fee rate fail", "err", err)
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	var buf bytes.Buffer
+	if err := rawTx.Serialize(&buf); err != nil {
+		resp.Message = "serialize tx fail"
+		return resp, nil
+	}
+	txHash := rawTx.TxHash().String()
+	log.Info("sign tx success", "txHash", txHash, "vins", len(rawTx.TxIn), "vouts", len(rawTx.TxOut))
 	resp.Code = wallet.ReturnCode_SUCCESS
 	resp.Message = "sign tx success"
-	resp.TxMessageHash = signture
-	resp.TxHash = ""
-	resp.SignedTx = ""
+	resp.TxMessageHash = strings.Join(sigHashes, ",")
+	resp.TxHash = txHash
+	resp.SignedTx = hex.EncodeToString(buf.Bytes())
+	resp.PartialSignatures = partialSignaturesJSON(partialSigs)
 	return resp, nil
 }
 
 func (c *ChainAdaptor) BuildAndSignBatchTransaction(ctx context.Context, req *wallet.BuildAndSignBatchTransactionRequest) (*wallet.BuildAndSignBatchTransactionResponse, error) {
-	//TODO implement me
-	panic("implement me")
+	return chain.BuildAndSignBatch(ctx, req, c.batchWorkers, c.BuildAndSignTransaction), nil
 }
 
-func (c *ChainAdaptor) CalcSignHashes(vins []*Vin, vouts []*Vout) ([][]byte, []byte, error) {
-	if len(vins) == 0 || len(vouts) == 0 {
-		return nil, nil, errors.New("invalid len in or out")
-	}
-	rawTx := wire.NewMsgTx(wire.TxVersion)
-	for _, in := range vins {
-		utxoHash, err := chainhash.NewHashFromStr(in.Hash)
-		if err != nil {
-			return nil, nil, err
-		}
-		txIn := wire.NewTxIn(wire.NewOutPoint(utxoHash, uint32(in.Index)), nil, nil)
-		rawTx.AddTxIn(txIn)
-	}
+func (c *ChainAdaptor) SignPersonalMessage(ctx context.Context, req *wallet.SignPersonalMessageRequest) (*wallet.SignPersonalMessageResponse, error) {
+	return &wallet.SignPersonalMessageResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
+}
 
-	for _, out := range vouts {
-		toAddress, err := btcutil.DecodeAddress(out.Address, &chaincfg.MainNetParams)
-		if err != nil {
-			return nil, nil, err
-		}
-		toPkScript, err := txscript.PayToAddrScript(toAddress)
-		if err != nil {
-			return nil, nil, err
-		}
-		txOut := wire.NewTxOut(int64(out.Amount), toPkScript)
-		rawTx.AddTxOut(txOut)
-	}
-	signHashes := make([][]byte, len(vins))
-	for i, vin := range vins {
-		from := vin.Address
-		fromAddr, err := btcutil.DecodeAddress(from, &chaincfg.MainNetParams)
-		if err != nil {
-			log.Info("decode address error", "from", from, "err", err)
-			return nil, nil, err
-		}
-		fromPkScript, err := txscript.PayToAddrScript(fromAddr)
-		if err != nil {
-			log.Info("pay to addr script err", "err", err)
-			return nil, nil, err
-		}
-		signHash, err := txscript.CalcSignatureHash(fromPkScript, txscript.SigHashAll, rawTx, i)
-		if err != nil {
-			log.Info("Calc signature hash error", "err", err)
-			return nil, nil, err
-		}
-		signHashes[i] = signHash
+func (c *ChainAdaptor) SignTypedData(ctx context.Context, req *wallet.SignTypedDataRequest) (*wallet.SignTypedDataResponse, error) {
+	return &wallet.SignTypedDataResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
+}
+
+func (c *ChainAdaptor) BuildAndSignPermit(ctx context.Context, req *wallet.BuildAndSignPermitRequest) (*wallet.BuildAndSignPermitResponse, error) {
+	return &wallet.BuildAndSignPermitResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
+}
+
+func (c *ChainAdaptor) BuildAndSignUserOperation(ctx context.Context, req *wallet.BuildAndSignUserOperationRequest) (*wallet.BuildAndSignUserOperationResponse, error) {
+	return &wallet.BuildAndSignUserOperationResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
+}
+
+func (c *ChainAdaptor) BuildAndSignSafeTransaction(ctx context.Context, req *wallet.BuildAndSignSafeTransactionRequest) (*wallet.BuildAndSignSafeTransactionResponse, error) {
+	return &wallet.BuildAndSignSafeTransactionResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
+}
+
+// hdPathTemplate uses the BIP44 coin type of the network, 1 for every test
+// network.
+func (c *ChainAdaptor) hdPathTemplate(addressFormat string, params *chaincfg.Params) string {
+	if !c.hdEnable {
+		return ""
 	}
-	buf := bytes.NewBuffer(make([]byte, 0, rawTx.SerializeSize()))
-	return signHashes, buf.Bytes(), nil
+	return fmt.Sprintf("m/%d'/%d'/0'/0/%%d", hdPurposes[addressFormat], params.HDCoinType)
 }
 
-func NewChainAdaptor(db *leveldb.Keys) (chain.IChainAdaptor, error) {
+func NewChainAdaptor(conf *config.Config, db *leveldb.Keys) (chain.IChainAdaptor, error) {
+	signer, err := ssm.NewECDSASigner(conf)
+	if err != nil {
+		log.Error("new signer fail", "err", err)
+		return nil, err
+	}
+	changeAddresses, err := newChangeAddresses(conf.Bitcoin.ChangeAddresses)
+	if err != nil {
+		log.Error("load change addresses fail", "err", err)
+		return nil, err
+	}
+	musig2SessionTTL := time.Duration(conf.Bitcoin.Musig2SessionTTL) * time.Second
+	if musig2SessionTTL == 0 {
+		musig2SessionTTL = DefaultMusig2SessionTTL
+	}
+	maxMusig2Sessions := conf.Bitcoin.MaxMusig2Sessions
+	if maxMusig2Sessions <= 0 {
+		maxMusig2Sessions = DefaultMaxMusig2Sessions
+	}
 	return &ChainAdaptor{
-		db:     db,
-		signer: &ssm.ECDSASigner{},
+		db:                db,
+		signer:            signer,
+		hdEnable:          conf.HdEnable,
+		batchWorkers:      conf.BatchWorkers,
+		maxFeeRate:        conf.Bitcoin.MaxFeeRate,
+		changeAddresses:   changeAddresses,
+		musig2SessionTTL:  musig2SessionTTL,
+		maxMusig2Sessions: maxMusig2Sessions,
 	}, nil
 }
@@ -0,0 +1,218 @@
+package bitcoin
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// witnessScaleFactor is the BIP141 weight of a non witness byte
+const witnessScaleFactor = 4
+
+// newChangeAddresses checks the configured change address of every network
+// and keys them by the network params name.
+func newChangeAddresses(addresses map[string]string) (map[string]btcutil.Address, error) {
+	changeAddresses := make(map[string]btcutil.Address, len(addresses))
+	for network, address := range addresses {
+		params, err := networkParams(network)
+		if err != nil {
+			return nil, err
+		}
+		addr, err := decodeAddress(address, params)
+		if err != nil {
+			return nil, fmt.Errorf("invalid change address: %w", err)
+		}
+		changeAddresses[params.Name] = addr
+	}
+	return changeAddresses, nil
+}
+
+// buildOutputs returns the outputs of the schema after checking that the vins
+// pay the vouts plus exactly the declared fee. With change the remainder goes
+// to the change address of the network instead. Dust outputs are rejected.
+func (c *ChainAdaptor) buildOutputs(schema *BitcoinSchema, params *chaincfg.Params) ([]*wire.TxOut, uint64, error) {
+	fee, err := strconv.ParseUint(schema.Fee, 10, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid fee: %q", schema.Fee)
+	}
+	var totalIn, totalOut uint64
+	for _, in := range schema.Vins {
+		if in.Amount == 0 || in.Amount > btcutil.MaxSatoshi {
+			return nil, 0, fmt.Errorf("invalid vin amount: %d", in.Amount)
+		}
+		totalIn += in.Amount
+	}
+	txOuts := make([]*wire.TxOut, 0, len(schema.Vouts)+1)
+	for _, out := range schema.Vouts {
+		if out.Amount > btcutil.MaxSatoshi {
+			return nil, 0, fmt.Errorf("invalid vout amount: %d", out.Amount)
+		}
+		toAddress, err := decodeAddress(out.Address, params)
+		if err != nil {
+			return nil, 0, err
+		}
+		toPkScript, err := txscript.PayToAddrScript(toAddress)
+		if err != nil {
+			return nil, 0, err
+		}
+		txOuts = append(txOuts, wire.NewTxOut(int64(out.Amount), toPkScript))
+		totalOut += out.Amount
+	}
+	if totalIn < totalOut || totalIn-totalOut < fee {
+		return nil, 0, fmt.Errorf("vins %d do not cover vouts %d and fee %d", totalIn, totalOut, fee)
+	}
+	if change := totalIn - totalOut - fee; schema.Change {
+		changeAddress, ok := c.changeAddresses[params.Name]
+		if !ok {
+			return nil, 0, fmt.Errorf("no change address configured for %s", params.Name)
+		}
+		changePkScript, err := txscript.PayToAddrScript(changeAddress)
+		if err != nil {
+			return nil, 0, err
+		}
+		txOuts = append(txOuts, wire.NewTxOut(int64(change), changePkScript))
+	} else if change != 0 {
+		return nil, 0, fmt.Errorf("vins minus vouts is %d, the declared fee is %d", totalIn-totalOut, fee)
+	}
+	for i, txOut := range txOuts {
+		if isDust(txOut) {
+			return nil, 0, fmt.Errorf("vout %d of %d is dust", i, txOut.Value)
+		}
+	}
+	return txOuts, fee, nil
+}
+
+// checkFeeRate caps the fee rate of the signed transaction by its virtual size.
+func (c *ChainAdaptor) checkFeeRate(tx *wire.MsgTx, fee uint64) error {
+	weight := tx.SerializeSizeStripped()*(witnessScaleFactor-1) + tx.SerializeSize()
+	vsize := uint64((weight + witnessScaleFactor - 1) / witnessScaleFactor)
+	if c.maxFeeRate != 0 && fee > c.maxFeeRate*vsize {
+		return fmt.Errorf("fee rate %.2f sat/vB of %d vbytes is above %d", float64(fee)/float64(vsize), vsize, c.maxFeeRate)
+	}
+	return nil
+}
+
+// isDust is the relay policy dust check at the 1 sat/vB minimum relay fee, an
+// output is dust when spending it costs more than a third of its value.
+func isDust(txOut *wire.TxOut) bool {
+	spendSize := txOut.SerializeSize() + 41
+	if txscript.IsWitnessProgram(txOut.PkScript) {
+		spendSize += 107 / witnessScaleFactor
+	} else {
+		spendSize += 107
+	}
+	return txOut.Value < int64(3*spendSize)
+}
+
+// psbtFee returns the fee of the psbt, its spent outputs minus its outputs.
+// Dust outputs are rejected, a zero value OP_RETURN output is not dust.
+func psbtFee(packet *psbt.Packet, prevOutFetcher txscript.PrevOutputFetcher) (uint64, error) {
+	var totalIn, totalOut uint64
+	for i, txIn := range packet.UnsignedTx.TxIn {
+		prevOut := prevOutFetcher.FetchPrevOutput(txIn.PreviousOutPoint)
+		if prevOut.Value <= 0 || prevOut.Value > btcutil.MaxSatoshi {
+			return 0, fmt.Errorf("invalid psbt input %d amount: %d", i, prevOut.Value)
+		}
+		totalIn += uint64(prevOut.Value)
+	}
+	for i, txOut := range packet.UnsignedTx.TxOut {
+		if txOut.Value < 0 || txOut.Value > btcutil.MaxSatoshi {
+			return 0, fmt.Errorf("invalid psbt output %d amount: %d", i, txOut.Value)
+		}
+		if isDust(txOut) && !(txOut.Value == 0 && txscript.GetScriptClass(txOut.PkScript) == txscript.NullDataTy) {
+			return 0, fmt.Errorf("psbt output %d of %d is dust", i, txOut.Value)
+		}
+		totalOut += uint64(txOut.Value)
+	}
+	if totalIn < totalOut {
+		return 0, fmt.Errorf("psbt inputs %d do not cover outputs %d", totalIn, totalOut)
+	}
+	return totalIn - totalOut, nil
+}
+
+// withPlaceholderPsbtWitness copies the unsigned tx of the psbt with the final
+// scripts of the finalized inputs and a worst case signature script or witness
+// on the others, for the fee rate check before signing.
+func withPlaceholderPsbtWitness(packet *psbt.Packet, prevOutFetcher txscript.PrevOutputFetcher) *wire.MsgTx {
+	sizedTx := packet.UnsignedTx.Copy()
+	for i, txIn := range sizedTx.TxIn {
+		pInput := packet.Inputs[i]
+		if pInput.FinalScriptSig != nil || pInput.FinalScriptWitness != nil {
+			txIn.SignatureScript = pInput.FinalScriptSig
+			if pInput.FinalScriptWitness != nil {
+				witness, err := readWitness(pInput.FinalScriptWitness)
+				if err == nil {
+					txIn.Witness = witness
+				}
+			}
+			continue
+		}
+		pkScript := prevOutFetcher.FetchPrevOutput(txIn.PreviousOutPoint).PkScript
+		switch txscript.GetScriptClass(pkScript) {
+		case txscript.WitnessV1TaprootTy:
+			txIn.Witness = wire.TxWitness{make([]byte, 65)}
+		case txscript.PubKeyHashTy:
+			txIn.SignatureScript, _ = txscript.NewScriptBuilder().AddData(make([]byte, 73)).AddData(make([]byte, 33)).Script()
+		case txscript.WitnessV0PubKeyHashTy:
+			txIn.Witness = wire.TxWitness{make([]byte, 73), make([]byte, 33)}
+		case txscript.WitnessV0ScriptHashTy:
+			txIn.Witness = placeholderScriptWitness(pInput.WitnessScript)
+		case txscript.ScriptHashTy:
+			builder := txscript.NewScriptBuilder()
+			switch txscript.GetScriptClass(pInput.RedeemScript) {
+			case txscript.WitnessV0PubKeyHashTy:
+				txIn.Witness = wire.TxWitness{make([]byte, 73), make([]byte, 33)}
+			case txscript.WitnessV0ScriptHashTy:
+				txIn.Witness = placeholderScriptWitness(pInput.WitnessScript)
+			default:
+				builder.AddOp(txscript.OP_0)
+				for j := 0; j < requiredSigs(pInput.RedeemScript); j++ {
+					builder.AddData(make([]byte, 73))
+				}
+			}
+			txIn.SignatureScript, _ = builder.AddData(pInput.RedeemScript).Script()
+		}
+	}
+	return sizedTx
+}
+
+// placeholderScriptWitness is the worst case witness spending a witness script.
+func placeholderScriptWitness(witnessScript []byte) wire.TxWitness {
+	witness := wire.TxWitness{nil}
+	for j := 0; j < requiredSigs(witnessScript); j++ {
+		witness = append(witness, make([]byte, 73))
+	}
+	return append(witness, witnessScript)
+}
+
+// requiredSigs is the signature count of a multisig script, one otherwise.
+func requiredSigs(script []byte) int {
+	if _, required, err := multisigPubKeys(script); err == nil {
+		return required
+	}
+	return 1
+}
+
+// readWitness decodes a serialized psbt final script witness.
+func readWitness(serialized []byte) (wire.TxWitness, error) {
+	r := bytes.NewReader(serialized)
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	witness := make(wire.TxWitness, 0, count)
+	for j := uint64(0); j < count; j++ {
+		item, err := wire.ReadVarBytes(r, 0, txscript.MaxScriptSize, "witness item")
+		if err != nil {
+			return nil, err
+		}
+		witness = append(witness, item)
+	}
+	return witness, nil
+}
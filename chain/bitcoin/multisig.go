@@ -0,0 +1,351 @@
+package bitcoin
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Multisig address formats
+const (
+	AddressFormatP2WSH     = "p2wsh"
+	AddressFormatP2SHP2WSH = "p2sh-p2wsh"
+)
+
+// PartialSignature is the signature of one multisig cosigner key, PublicKey is
+// the compressed key hex and Signature the DER signature with sighash byte.
+type PartialSignature struct {
+	Vin       int    `json:"vin"`
+	PublicKey string `json:"public_key"`
+	Signature string `json:"signature"`
+}
+
+// CreateMultisigAddress builds the BIP67 sorted m-of-n multisig witness
+// script of the public keys and its p2wsh or p2sh-p2wsh address. At least one
+// of the keys must be in the keystore for this service to cosign.
+func (c *ChainAdaptor) CreateMultisigAddress(ctx context.Context, req *wallet.CreateMultisigAddressRequest) (*wallet.CreateMultisigAddressResponse, error) {
+	resp := &wallet.CreateMultisigAddressResponse{
+		Code: wallet.ReturnCode_ERROR,
+	}
+	params, err := networkParams(req.Network)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	if req.AddressFormat != AddressFormatP2WSH && req.AddressFormat != AddressFormatP2SHP2WSH {
+		resp.Message = "Do not support address type"
+		return resp, nil
+	}
+	witnessScript, pubKeys, err := sortedMultisigScript(req.PublicKeys, int(req.Threshold), params)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	var localKeys []string
+	for _, pubKey := range pubKeys {
+		publicKey := hex.EncodeToString(pubKey.SerializeUncompressed())
+		if _, isOk := c.db.GetPrivKey(publicKey); !isOk {
+			continue
+		}
+		if err := c.checkKeyNetwork(publicKey, params); err != nil {
+			resp.Message = err.Error()
+			return resp, nil
+		}
+		localKeys = append(localKeys, publicKey)
+	}
+	if len(localKeys) == 0 {
+		resp.Message = "none of the public keys is in the keystore"
+		return resp, nil
+	}
+
+	scriptHash := sha256.Sum256(witnessScript)
+	witnessAddr, err := btcutil.NewAddressWitnessScriptHash(scriptHash[:], params)
+	if err != nil {
+		resp.Message = "create p2wsh address fail"
+		return resp, nil
+	}
+	address := witnessAddr.EncodeAddress()
+	if req.AddressFormat == AddressFormatP2SHP2WSH {
+		redeemScript, err := txscript.PayToAddrScript(witnessAddr)
+		if err != nil {
+			resp.Message = "create p2sh redeem script fail"
+			return resp, nil
+		}
+		p2shAddr, err := btcutil.NewAddressScriptHash(redeemScript, params)
+		if err != nil {
+			resp.Message = "create p2sh address fail"
+			return resp, nil
+		}
+		address = p2shAddr.EncodeAddress()
+		resp.RedeemScript = hex.EncodeToString(redeemScript)
+	}
+	for _, pubKey := range pubKeys {
+		resp.PublicKeys = append(resp.PublicKeys, hex.EncodeToString(pubKey.SerializeCompressed()))
+	}
+	log.Info("create multisig address success", "address", address, "threshold", req.Threshold, "keys", len(pubKeys), "localKeys", len(localKeys))
+	resp.Code = wallet.ReturnCode_SUCCESS
+	resp.Message = "create multisig address success"
+	resp.Address = address
+	resp.WitnessScript = hex.EncodeToString(witnessScript)
+	resp.LocalPublicKeys = localKeys
+	return resp, nil
+}
+
+// sortedMultisigScript returns the threshold of n multisig script with the
+// compressed keys in BIP67 lexicographic order.
+func sortedMultisigScript(publicKeys []string, threshold int, params *chaincfg.Params) ([]byte, []*btcec.PublicKey, error) {
+	if len(publicKeys) == 0 || len(publicKeys) > txscript.MaxPubKeysPerMultiSig {
+		return nil, nil, fmt.Errorf("multisig needs 1 to %d public keys", txscript.MaxPubKeysPerMultiSig)
+	}
+	if threshold < 1 || threshold > len(publicKeys) {
+		return nil, nil, fmt.Errorf("invalid threshold %d of %d keys", threshold, len(publicKeys))
+	}
+	pubKeys, err := sortedPubKeys(publicKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+	addrPubKeys := make([]*btcutil.AddressPubKey, len(pubKeys))
+	for i, pubKey := range pubKeys {
+		addrPubKey, err := btcutil.NewAddressPubKey(pubKey.SerializeCompressed(), params)
+		if err != nil {
+			return nil, nil, err
+		}
+		addrPubKeys[i] = addrPubKey
+	}
+	script, err := txscript.MultiSigScript(addrPubKeys, threshold)
+	if err != nil {
+		return nil, nil, err
+	}
+	return script, pubKeys, nil
+}
+
+// sortedPubKeys parses the distinct public keys and sorts them by their
+// compressed encoding.
+func sortedPubKeys(publicKeys []string) ([]*btcec.PublicKey, error) {
+	pubKeys := make([]*btcec.PublicKey, 0, len(publicKeys))
+	seen := make(map[string]bool)
+	for _, publicKey := range publicKeys {
+		pubKeyBytes, err := hex.DecodeString(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key: %s", publicKey)
+		}
+		pubKey, err := btcec.ParsePubKey(pubKeyBytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse public key %s fail: %w", publicKey, err)
+		}
+		id := string(pubKey.SerializeCompressed())
+		if seen[id] {
+			return nil, fmt.Errorf("duplicate public key: %s", publicKey)
+		}
+		seen[id] = true
+		pubKeys = append(pubKeys, pubKey)
+	}
+	sort.Slice(pubKeys, func(i, j int) bool {
+		return bytes.Compare(pubKeys[i].SerializeCompressed(), pubKeys[j].SerializeCompressed()) < 0
+	})
+	return pubKeys, nil
+}
+
+// newMultisigInput checks that the vin address is the p2wsh or p2sh-p2wsh
+// address of its multisig witness script and parses the cosigner signatures.
+func newMultisigInput(in *Vin, fromAddr btcutil.Address, params *chaincfg.Params) (*InputSignHash, error) {
+	witnessScript, err := hex.DecodeString(in.WitnessScript)
+	if err != nil {
+		return nil, fmt.Errorf("invalid witness script: %s", in.WitnessScript)
+	}
+	if isMultisig, err := txscript.IsMultisigScript(witnessScript); err != nil || !isMultisig {
+		return nil, errors.New("witness script is not a multisig script")
+	}
+	pubKeys, required, err := multisigPubKeys(witnessScript)
+	if err != nil {
+		return nil, err
+	}
+	pkScript, err := txscript.PayToAddrScript(fromAddr)
+	if err != nil {
+		return nil, err
+	}
+	scriptHash := sha256.Sum256(witnessScript)
+	witnessAddr, err := btcutil.NewAddressWitnessScriptHash(scriptHash[:], params)
+	if err != nil {
+		return nil, err
+	}
+	input := &InputSignHash{
+		Class:         txscript.GetScriptClass(pkScript),
+		PkScript:      pkScript,
+		WitnessScript: witnessScript,
+		pubKeys:       pubKeys,
+		required:      required,
+		cosignerSigs:  make(map[string][]byte),
+	}
+	expected := scriptHash[:]
+	switch input.Class {
+	case txscript.WitnessV0ScriptHashTy:
+	case txscript.ScriptHashTy:
+		if input.RedeemScript, err = txscript.PayToAddrScript(witnessAddr); err != nil {
+			return nil, err
+		}
+		expected = btcutil.Hash160(input.RedeemScript)
+	default:
+		return nil, fmt.Errorf("unsupported multisig address type: %s", input.Class)
+	}
+	if !bytes.Equal(fromAddr.ScriptAddress(), expected) {
+		return nil, fmt.Errorf("address %s is not the address of the witness script", in.Address)
+	}
+	for publicKey, signature := range in.Signatures {
+		pubKeyBytes, err := hex.DecodeString(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cosigner public key: %s", publicKey)
+		}
+		pubKey, err := btcec.ParsePubKey(pubKeyBytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse cosigner public key %s fail: %w", publicKey, err)
+		}
+		if !scriptHasKey(witnessScript, pubKey.SerializeCompressed()) {
+			return nil, fmt.Errorf("cosigner %s is not a key of the witness script", publicKey)
+		}
+		sig, err := hex.DecodeString(signature)
+		if err != nil || len(sig) == 0 {
+			return nil, fmt.Errorf("invalid cosigner signature: %s", signature)
+		}
+		input.cosignerSigs[string(pubKey.SerializeCompressed())] = sig
+	}
+	return input, nil
+}
+
+// multisigPubKeys returns the keys of a multisig script in script order and
+// the number of signatures it requires.
+func multisigPubKeys(script []byte) ([]*btcec.PublicKey, int, error) {
+	_, required, err := txscript.CalcMultiSigStats(script)
+	if err != nil {
+		return nil, 0, err
+	}
+	pushes, err := txscript.PushedData(script)
+	if err != nil {
+		return nil, 0, err
+	}
+	pubKeys := make([]*btcec.PublicKey, 0, len(pushes))
+	for _, push := range pushes {
+		pubKey, err := btcec.ParsePubKey(push)
+		if err != nil {
+			return nil, 0, fmt.Errorf("parse multisig public key fail: %w", err)
+		}
+		pubKeys = append(pubKeys, pubKey)
+	}
+	return pubKeys, required, nil
+}
+
+// signMultisigInput signs vin i with every keystore key of the witness script
+// and, once the local and cosigner signatures reach the threshold, sets the
+// final witness.
+func (c *ChainAdaptor) signMultisigInput(tx *wire.MsgTx, i int, input *InputSignHash) ([]*PartialSignature, error) {
+	var partialSigs []*PartialSignature
+	var sigs [][]byte
+	for _, pubKey := range input.pubKeys {
+		compressed := pubKey.SerializeCompressed()
+		if sig, ok := input.cosignerSigs[string(compressed)]; ok {
+			if err := verifyCosignerSig(sig, input.SigHash, pubKey); err != nil {
+				return nil, fmt.Errorf("cosigner %x: %w", compressed, err)
+			}
+			sigs = append(sigs, sig)
+			continue
+		}
+		privKey, isOk := c.db.GetPrivKey(hex.EncodeToString(pubKey.SerializeUncompressed()))
+		if !isOk {
+			continue
+		}
+		signature, err := c.signer.SignMessage(privKey, hex.EncodeToString(input.SigHash))
+		if err != nil {
+			return nil, err
+		}
+		sig, err := derSignature(signature)
+		if err != nil {
+			return nil, err
+		}
+		sig = append(sig, byte(txscript.SigHashAll))
+		sigs = append(sigs, sig)
+		partialSigs = append(partialSigs, &PartialSignature{
+			Vin:       i,
+			PublicKey: hex.EncodeToString(compressed),
+			Signature: hex.EncodeToString(sig),
+		})
+	}
+	if len(partialSigs) == 0 {
+		return nil, errors.New("no keystore key of the witness script")
+	}
+	if input.RedeemScript != nil {
+		redeemScript, err := txscript.NewScriptBuilder().AddData(input.RedeemScript).Script()
+		if err != nil {
+			return nil, err
+		}
+		tx.TxIn[i].SignatureScript = redeemScript
+	}
+	if len(sigs) < input.required {
+		return partialSigs, nil
+	}
+	// CHECKMULTISIG pops one extra element and takes exactly required
+	// signatures in key order
+	witness := wire.TxWitness{nil}
+	witness = append(witness, sigs[:input.required]...)
+	tx.TxIn[i].Witness = append(witness, input.WitnessScript)
+	input.complete = true
+	return partialSigs, nil
+}
+
+// partialSignaturesJSON encodes the keystore multisig signatures for the
+// cosigners, empty when no multisig input was signed.
+func partialSignaturesJSON(partialSigs []*PartialSignature) string {
+	if len(partialSigs) == 0 {
+		return ""
+	}
+	b, err := json.Marshal(partialSigs)
+	if err != nil {
+		log.Error("marshal partial signatures fail", "err", err)
+		return ""
+	}
+	return string(b)
+}
+
+func verifyCosignerSig(sig []byte, sigHash []byte, pubKey *btcec.PublicKey) error {
+	if txscript.SigHashType(sig[len(sig)-1]) != txscript.SigHashAll {
+		return errors.New("signature is not SIGHASH_ALL")
+	}
+	signature, err := ecdsa.ParseDERSignature(sig[:len(sig)-1])
+	if err != nil {
+		return err
+	}
+	if !signature.Verify(sigHash, pubKey) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// withPlaceholderWitness copies the tx with a worst case witness on every
+// multisig input still missing signatures, for the fee rate check.
+func withPlaceholderWitness(tx *wire.MsgTx, inputs []*InputSignHash) *wire.MsgTx {
+	sizedTx := tx.Copy()
+	for i, input := range inputs {
+		if input.complete {
+			continue
+		}
+		witness := wire.TxWitness{nil}
+		for j := 0; j < input.required; j++ {
+			witness = append(witness, make([]byte, 73))
+		}
+		sizedTx.TxIn[i].Witness = append(witness, input.WitnessScript)
+	}
+	return sizedTx
+}
@@ -0,0 +1,439 @@
+package bitcoin
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DQYXACML/wallet-sign/leveldb"
+	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
+	"github.com/DQYXACML/wallet-sign/ssm"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr/musig2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// DefaultMusig2SessionTTL is the session lifetime when musig2_session_ttl is unset
+	DefaultMusig2SessionTTL = 10 * time.Minute
+	// DefaultMaxMusig2Sessions caps the open sessions of a key when max_musig2_sessions is unset
+	DefaultMaxMusig2Sessions = 16
+)
+
+// CreateMusig2Address aggregates the BIP327 sorted public keys into the
+// internal key of a p2tr address, tweaked for the optional script tree merkle
+// root or BIP86 without one. At least one of the keys must be in the keystore.
+func (c *ChainAdaptor) CreateMusig2Address(ctx context.Context, req *wallet.CreateMusig2AddressRequest) (*wallet.CreateMusig2AddressResponse, error) {
+	resp := &wallet.CreateMusig2AddressResponse{
+		Code: wallet.ReturnCode_ERROR,
+	}
+	params, err := networkParams(req.Network)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	pubKeys, err := musig2PubKeys(req.PublicKeys)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	scriptRoot, err := decodeMerkleRoot(req.MerkleRoot)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	var localKeys []string
+	for _, pubKey := range pubKeys {
+		publicKey := hex.EncodeToString(pubKey.SerializeUncompressed())
+		if _, isOk := c.db.GetPrivKey(publicKey); !isOk {
+			continue
+		}
+		if err := c.checkKeyNetwork(publicKey, params); err != nil {
+			resp.Message = err.Error()
+			return resp, nil
+		}
+		localKeys = append(localKeys, publicKey)
+	}
+	if len(localKeys) == 0 {
+		resp.Message = "none of the public keys is in the keystore"
+		return resp, nil
+	}
+	aggKey, err := musig2AggregateKey(pubKeys, scriptRoot)
+	if err != nil {
+		resp.Message = "aggregate public keys fail: " + err.Error()
+		return resp, nil
+	}
+	outputKey := schnorr.SerializePubKey(aggKey.FinalKey)
+	taprootAddr, err := btcutil.NewAddressTaproot(outputKey, params)
+	if err != nil {
+		resp.Message = "create p2tr address fail"
+		return resp, nil
+	}
+	for _, pubKey := range pubKeys {
+		resp.PublicKeys = append(resp.PublicKeys, hex.EncodeToString(pubKey.SerializeCompressed()))
+	}
+	log.Info("create musig2 address success", "address", taprootAddr.EncodeAddress(), "keys", len(pubKeys), "localKeys", len(localKeys))
+	resp.Code = wallet.ReturnCode_SUCCESS
+	resp.Message = "create musig2 address success"
+	resp.Address = taprootAddr.EncodeAddress()
+	resp.InternalKey = hex.EncodeToString(schnorr.SerializePubKey(aggKey.PreTweakedKey))
+	resp.OutputKey = hex.EncodeToString(outputKey)
+	resp.LocalPublicKeys = localKeys
+	return resp, nil
+}
+
+// Musig2Nonce is the first signing round, it generates the nonce of the local
+// key for message_hash and persists the secret nonce under a new session id
+// before the public nonce is handed out. The session expires after
+// musig2_session_ttl.
+func (c *ChainAdaptor) Musig2Nonce(ctx context.Context, req *wallet.Musig2NonceRequest) (*wallet.Musig2NonceResponse, error) {
+	resp := &wallet.Musig2NonceResponse{
+		Code: wallet.ReturnCode_ERROR,
+	}
+	musig2Signer, ok := c.signer.(ssm.Musig2Signer)
+	if !ok {
+		resp.Message = "signer does not support musig2"
+		return resp, nil
+	}
+	params, err := networkParams(req.Network)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	pubKeys, err := musig2PubKeys(req.PublicKeys)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	scriptRoot, err := decodeMerkleRoot(req.MerkleRoot)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	msgHash, err := hex.DecodeString(req.MessageHash)
+	if err != nil || len(msgHash) != 32 {
+		resp.Message = "invalid message hash"
+		return resp, nil
+	}
+	localKey, err := musig2LocalKey(pubKeys, req.PublicKey)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	publicKey := hex.EncodeToString(localKey.SerializeUncompressed())
+	privKey, isOk := c.db.GetPrivKey(publicKey)
+	if !isOk {
+		resp.Message = "get private key by public key fail"
+		return resp, nil
+	}
+	if err := c.checkKeyNetwork(publicKey, params); err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	secNonce, pubNonce, err := musig2Signer.Musig2Nonce(privKey, hex.EncodeToString(msgHash))
+	if err != nil {
+		log.Error("generate musig2 nonce fail", "err", err)
+		resp.Message = "generate nonce fail"
+		return resp, nil
+	}
+	sessionId := make([]byte, 16)
+	if _, err := rand.Read(sessionId); err != nil {
+		resp.Message = "generate session id fail"
+		return resp, nil
+	}
+	session := &leveldb.Musig2Session{
+		Network:    params.Name,
+		PublicKey:  hex.EncodeToString(localKey.SerializeCompressed()),
+		MsgHash:    hex.EncodeToString(msgHash),
+		MerkleRoot: hex.EncodeToString(scriptRoot),
+		PubNonce:   pubNonce,
+		SecNonce:   secNonce,
+		ExpiresAt:  time.Now().Add(c.musig2SessionTTL).Unix(),
+	}
+	for _, pubKey := range pubKeys {
+		session.PublicKeys = append(session.PublicKeys, hex.EncodeToString(pubKey.SerializeCompressed()))
+	}
+	if err := c.db.StoreMusig2Session(hex.EncodeToString(sessionId), session, c.maxMusig2Sessions); errors.Is(err, leveldb.ErrTooManySessions) {
+		resp.Message = err.Error()
+		return resp, nil
+	} else if err != nil {
+		log.Error("store musig2 session fail", "err", err)
+		resp.Message = "store session fail"
+		return resp, nil
+	}
+	log.Info("musig2 nonce success", "sessionId", hex.EncodeToString(sessionId), "keys", len(pubKeys))
+	resp.Code = wallet.ReturnCode_SUCCESS
+	resp.Message = "musig2 nonce success"
+	resp.SessionId = hex.EncodeToString(sessionId)
+	resp.PubNonce = pubNonce
+	return resp, nil
+}
+
+// Musig2PartialSign is the second signing round for the public key and message
+// hash of the session. It consumes the session, so its secret nonce signs once,
+// and returns the partial signature of the local key. With the partial
+// signatures of every other key it also returns the final BIP340 signature, a
+// combined signature that does not verify is an error that still carries the
+// partial signature of the consumed session.
+func (c *ChainAdaptor) Musig2PartialSign(ctx context.Context, req *wallet.Musig2PartialSignRequest) (*wallet.Musig2PartialSignResponse, error) {
+	resp := &wallet.Musig2PartialSignResponse{
+		Code: wallet.ReturnCode_ERROR,
+	}
+	musig2Signer, ok := c.signer.(ssm.Musig2Signer)
+	if !ok {
+		resp.Message = "signer does not support musig2"
+		return resp, nil
+	}
+	params, err := networkParams(req.Network)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	session, err := c.db.GetMusig2Session(req.SessionId)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	msgHash, err := hex.DecodeString(req.MessageHash)
+	if err != nil || hex.EncodeToString(msgHash) != session.MsgHash {
+		resp.Message = "message hash is not the hash of the session"
+		return resp, nil
+	}
+	if session.Network != params.Name {
+		resp.Message = fmt.Sprintf("session was created for %s", session.Network)
+		return resp, nil
+	}
+	pubKeys, err := musig2PubKeys(session.PublicKeys)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	if localKey, err := musig2LocalKey(pubKeys, req.PublicKey); err != nil || hex.EncodeToString(localKey.SerializeCompressed()) != session.PublicKey {
+		resp.Message = "public key is not the key of the session"
+		return resp, nil
+	}
+	pubNonces, err := musig2KeyedValues(session.PublicKeys, req.PubNonces, musig2.PubNonceSize)
+	if err != nil {
+		resp.Message = "pub nonces: " + err.Error()
+		return resp, nil
+	}
+	for i, publicKey := range session.PublicKeys {
+		if pubNonces[i] == nil {
+			resp.Message = fmt.Sprintf("missing pub nonce of %s", publicKey)
+			return resp, nil
+		}
+		if publicKey == session.PublicKey && hex.EncodeToString(pubNonces[i]) != session.PubNonce {
+			resp.Message = "pub nonce of the local key is not the session nonce"
+			return resp, nil
+		}
+	}
+	cosignerSigs, err := musig2KeyedValues(session.PublicKeys, req.PartialSignatures, 32)
+	if err != nil {
+		resp.Message = "partial signatures: " + err.Error()
+		return resp, nil
+	}
+	scriptRoot, err := decodeMerkleRoot(session.MerkleRoot)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	var msg [32]byte
+	copy(msg[:], msgHash)
+	nonces := make([][musig2.PubNonceSize]byte, len(pubNonces))
+	for i, pubNonce := range pubNonces {
+		copy(nonces[i][:], pubNonce)
+	}
+	combinedNonce, err := musig2.AggregateNonces(nonces)
+	if err != nil {
+		resp.Message = "aggregate pub nonces fail: " + err.Error()
+		return resp, nil
+	}
+	signOpts := []musig2.SignOption{musig2.WithSortedKeys(), musig2.WithBip86SignTweak()}
+	if scriptRoot != nil {
+		signOpts[1] = musig2.WithTaprootSignTweak(scriptRoot)
+	}
+	// cosigner signatures are checked before the session is consumed
+	partialSigs := make([]*musig2.PartialSignature, 0, len(pubKeys))
+	for i, sig := range cosignerSigs {
+		if sig == nil || session.PublicKeys[i] == session.PublicKey {
+			continue
+		}
+		partialSig := new(musig2.PartialSignature)
+		if err := partialSig.Decode(bytes.NewReader(sig)); err != nil {
+			resp.Message = fmt.Sprintf("invalid partial signature of %s", session.PublicKeys[i])
+			return resp, nil
+		}
+		if !partialSig.Verify(nonces[i], combinedNonce, pubKeys, pubKeys[i], msg, signOpts...) {
+			resp.Message = fmt.Sprintf("invalid partial signature of %s", session.PublicKeys[i])
+			return resp, nil
+		}
+		partialSigs = append(partialSigs, partialSig)
+	}
+
+	session, err = c.db.TakeMusig2Session(req.SessionId)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	localKey, err := musig2LocalKey(pubKeys, session.PublicKey)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	privKey, isOk := c.db.GetPrivKey(hex.EncodeToString(localKey.SerializeUncompressed()))
+	if !isOk {
+		resp.Message = "get private key by public key fail"
+		return resp, nil
+	}
+	pubNonceList := make([]string, len(pubNonces))
+	for i, pubNonce := range pubNonces {
+		pubNonceList[i] = hex.EncodeToString(pubNonce)
+	}
+	partialSig, finalNonce, err := musig2Signer.SignMusig2(privKey, session.SecNonce, session.PublicKeys, pubNonceList, session.MsgHash, session.MerkleRoot)
+	if err != nil {
+		log.Error("musig2 partial sign fail", "err", err)
+		resp.Message = "partial sign fail: " + err.Error()
+		return resp, nil
+	}
+	resp.PartialSignature = partialSig
+	resp.FinalNonce = finalNonce
+	if len(partialSigs) < len(pubKeys)-1 {
+		log.Info("musig2 partial sign success", "sessionId", req.SessionId, "partialSigs", len(partialSigs)+1)
+		resp.Code = wallet.ReturnCode_SUCCESS
+		resp.Message = "musig2 partial sign success"
+		return resp, nil
+	}
+
+	signature, err := combineMusig2Sigs(partialSig, finalNonce, partialSigs, pubKeys, msg, scriptRoot)
+	if err != nil {
+		log.Error("combine musig2 signatures fail", "err", err)
+		resp.Message = "combine signatures fail: " + err.Error()
+		return resp, nil
+	}
+	log.Info("musig2 sign success", "sessionId", req.SessionId, "keys", len(pubKeys))
+	resp.Code = wallet.ReturnCode_SUCCESS
+	resp.Message = "musig2 sign success"
+	resp.Signature = hex.EncodeToString(signature)
+	return resp, nil
+}
+
+// combineMusig2Sigs adds the local partial signature to the verified cosigner
+// ones and checks the result against the aggregated output key.
+func combineMusig2Sigs(localSig string, finalNonce string, partialSigs []*musig2.PartialSignature, pubKeys []*btcec.PublicKey, msg [32]byte, scriptRoot []byte) ([]byte, error) {
+	sig, err := hex.DecodeString(localSig)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(finalNonce)
+	if err != nil {
+		return nil, err
+	}
+	r, err := btcec.ParsePubKey(nonce)
+	if err != nil {
+		return nil, err
+	}
+	partialSig := new(musig2.PartialSignature)
+	if err := partialSig.Decode(bytes.NewReader(sig)); err != nil {
+		return nil, err
+	}
+	combineOpt := musig2.WithBip86TweakedCombine(msg, pubKeys, true)
+	if scriptRoot != nil {
+		combineOpt = musig2.WithTaprootTweakedCombine(msg, pubKeys, scriptRoot, true)
+	}
+	signature := musig2.CombineSigs(r, append(partialSigs, partialSig), combineOpt)
+	aggKey, err := musig2AggregateKey(pubKeys, scriptRoot)
+	if err != nil {
+		return nil, err
+	}
+	if !signature.Verify(msg[:], aggKey.FinalKey) {
+		return nil, errors.New("combined signature does not verify")
+	}
+	return signature.Serialize(), nil
+}
+
+// musig2PubKeys returns the key set of a MuSig2 aggregate in BIP327 KeySort
+// order.
+func musig2PubKeys(publicKeys []string) ([]*btcec.PublicKey, error) {
+	if len(publicKeys) < 2 {
+		return nil, errors.New("musig2 needs at least 2 public keys")
+	}
+	return sortedPubKeys(publicKeys)
+}
+
+func musig2AggregateKey(pubKeys []*btcec.PublicKey, scriptRoot []byte) (*musig2.AggregateKey, error) {
+	tweak := musig2.WithBIP86KeyTweak()
+	if scriptRoot != nil {
+		tweak = musig2.WithTaprootKeyTweak(scriptRoot)
+	}
+	aggKey, _, _, err := musig2.AggregateKeys(pubKeys, true, tweak)
+	return aggKey, err
+}
+
+// musig2LocalKey finds publicKey in the key set.
+func musig2LocalKey(pubKeys []*btcec.PublicKey, publicKey string) (*btcec.PublicKey, error) {
+	pubKeyBytes, err := hex.DecodeString(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %s", publicKey)
+	}
+	localKey, err := btcec.ParsePubKey(pubKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key fail: %w", err)
+	}
+	for _, pubKey := range pubKeys {
+		if pubKey.IsEqual(localKey) {
+			return pubKey, nil
+		}
+	}
+	return nil, errors.New("public key is not in the key set")
+}
+
+// musig2KeyedValues orders the hex values keyed by public key like the key
+// set, nil for a key without value.
+func musig2KeyedValues(publicKeys []string, values map[string]string, size int) ([][]byte, error) {
+	index := make(map[string]int, len(publicKeys))
+	for i, publicKey := range publicKeys {
+		index[publicKey] = i
+	}
+	ordered := make([][]byte, len(publicKeys))
+	for publicKey, value := range values {
+		pubKeyBytes, err := hex.DecodeString(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key: %s", publicKey)
+		}
+		pubKey, err := btcec.ParsePubKey(pubKeyBytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse public key %s fail: %w", publicKey, err)
+		}
+		i, ok := index[hex.EncodeToString(pubKey.SerializeCompressed())]
+		if !ok {
+			return nil, fmt.Errorf("%s is not in the key set", publicKey)
+		}
+		if ordered[i] != nil {
+			return nil, fmt.Errorf("duplicate public key: %s", publicKey)
+		}
+		b, err := hex.DecodeString(value)
+		if err != nil || len(b) != size {
+			return nil, fmt.Errorf("invalid value of %s", publicKey)
+		}
+		ordered[i] = b
+	}
+	return ordered, nil
+}
+
+// decodeMerkleRoot decodes the optional 32 byte script tree merkle root.
+func decodeMerkleRoot(merkleRoot string) ([]byte, error) {
+	if merkleRoot == "" {
+		return nil, nil
+	}
+	scriptRoot, err := hex.DecodeString(merkleRoot)
+	if err != nil || len(scriptRoot) != 32 {
+		return nil, fmt.Errorf("invalid merkle root: %s", merkleRoot)
+	}
+	return scriptRoot, nil
+}
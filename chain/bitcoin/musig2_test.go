@@ -0,0 +1,90 @@
+package bitcoin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+// TestMusig2Sign runs the nonce and partial sign rounds of a 2-of-2 key set
+// and checks the final signature against the output key of the address.
+func TestMusig2Sign(t *testing.T) {
+	c := newTestAdaptor(t)
+	msgHash := sha256.Sum256([]byte("musig2 test message"))
+	messageHash := hex.EncodeToString(msgHash[:])
+	merkleRoot := sha256.Sum256([]byte("musig2 test script tree"))
+	tests := []struct {
+		name       string
+		merkleRoot string
+	}{
+		{name: "bip86"},
+		{name: "script tree", merkleRoot: hex.EncodeToString(merkleRoot[:])},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := []string{createTestKey(t, c, "p2tr").PublicKey, createTestKey(t, c, "p2tr").PublicKey}
+			addrResp, err := c.CreateMusig2Address(context.Background(), &wallet.CreateMusig2AddressRequest{
+				PublicKeys: keys,
+				MerkleRoot: tt.merkleRoot,
+			})
+			if err != nil || addrResp.Code != wallet.ReturnCode_SUCCESS {
+				t.Fatalf("create musig2 address fail: %v %s", err, addrResp.GetMessage())
+			}
+
+			sessionIds := make([]string, len(keys))
+			pubNonces := make(map[string]string, len(keys))
+			for i, publicKey := range keys {
+				nonceResp, err := c.Musig2Nonce(context.Background(), &wallet.Musig2NonceRequest{
+					PublicKey:   publicKey,
+					MessageHash: messageHash,
+					PublicKeys:  keys,
+					MerkleRoot:  tt.merkleRoot,
+				})
+				if err != nil || nonceResp.Code != wallet.ReturnCode_SUCCESS {
+					t.Fatalf("musig2 nonce fail: %v %s", err, nonceResp.GetMessage())
+				}
+				sessionIds[i] = nonceResp.SessionId
+				pubNonces[publicKey] = nonceResp.PubNonce
+			}
+
+			firstResp, err := c.Musig2PartialSign(context.Background(), &wallet.Musig2PartialSignRequest{
+				SessionId:   sessionIds[0],
+				MessageHash: messageHash,
+				PubNonces:   pubNonces,
+				PublicKey:   keys[0],
+			})
+			if err != nil || firstResp.Code != wallet.ReturnCode_SUCCESS {
+				t.Fatalf("first partial sign fail: %v %s", err, firstResp.GetMessage())
+			}
+			if firstResp.Signature != "" {
+				t.Fatal("signature combined without the cosigner partial signature")
+			}
+			lastResp, err := c.Musig2PartialSign(context.Background(), &wallet.Musig2PartialSignRequest{
+				SessionId:         sessionIds[1],
+				MessageHash:       messageHash,
+				PubNonces:         pubNonces,
+				PartialSignatures: map[string]string{keys[0]: firstResp.PartialSignature},
+				PublicKey:         keys[1],
+			})
+			if err != nil || lastResp.Code != wallet.ReturnCode_SUCCESS {
+				t.Fatalf("last partial sign fail: %v %s", err, lastResp.GetMessage())
+			}
+
+			signature, err := schnorr.ParseSignature(mustDecodeHex(t, lastResp.Signature))
+			if err != nil {
+				t.Fatal(err)
+			}
+			outputKey, err := schnorr.ParsePubKey(mustDecodeHex(t, addrResp.OutputKey))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !signature.Verify(msgHash[:], outputKey) {
+				t.Fatal("musig2 signature does not verify against the output key")
+			}
+		})
+	}
+}
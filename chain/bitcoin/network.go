@@ -0,0 +1,65 @@
+package bitcoin
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// networkParams maps the request network to its chain params, an empty
+// network is mainnet.
+func networkParams(network string) (*chaincfg.Params, error) {
+	switch network {
+	case "", "mainnet":
+		return &chaincfg.MainNetParams, nil
+	case "testnet", "testnet3":
+		return &chaincfg.TestNet3Params, nil
+	case "signet":
+		return &chaincfg.SigNetParams, nil
+	case "regtest":
+		return &chaincfg.RegressionNetParams, nil
+	default:
+		return nil, fmt.Errorf("unsupported network: %s", network)
+	}
+}
+
+// HDNetwork returns the network stored with the keys of a bitcoin hd path
+// template, the same CreateKeyPairs stores. network picks the test network of
+// coin type 1, ok is false for the path template of another chain.
+func HDNetwork(pathTemplate string, network string) (name string, ok bool, err error) {
+	var purpose, coinType uint32
+	if _, err := fmt.Sscanf(pathTemplate, "m/%d'/%d'/", &purpose, &coinType); err != nil || !isHDPurpose(purpose) || coinType > 1 {
+		return "", false, nil
+	}
+	params, err := networkParams(network)
+	if err != nil {
+		return "", true, err
+	}
+	if params.HDCoinType != coinType {
+		return "", true, fmt.Errorf("%s keys are not derived at coin type %d", params.Name, coinType)
+	}
+	return params.Name, true, nil
+}
+
+func isHDPurpose(purpose uint32) bool {
+	for _, hdPurpose := range hdPurposes {
+		if hdPurpose == purpose {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeAddress decodes an address and rejects one of another network, the
+// bech32 hrp and the base58 version byte must both match params.
+func decodeAddress(address string, params *chaincfg.Params) (btcutil.Address, error) {
+	addr, err := btcutil.DecodeAddress(address, params)
+	if err != nil {
+		return nil, fmt.Errorf("decode address %s fail: %w", address, err)
+	}
+	if !addr.IsForNet(params) {
+		return nil, fmt.Errorf("address %s is not a %s address", address, params.Name)
+	}
+	return addr, nil
+}
@@ -0,0 +1,379 @@
+package bitcoin
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
+	"github.com/DQYXACML/wallet-sign/ssm"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// SignPsbt adds the signatures of the keystore keys to a BIP174 psbt. An input
+// is signed by every key of its bip32 derivations, taproot internal key and
+// redeem or witness script pubkeys that is in the keystore, plus the request
+// public key when set, a key created for another network is refused. Inputs
+// are only signed with SIGHASH_ALL or SIGHASH_DEFAULT unless allow_any_sighash
+// is set. A psbt with dust outputs or a fee rate above max_fee_rate is refused.
+// With finalize the complete psbt is also extracted to the signed raw
+// transaction.
+func (c *ChainAdaptor) SignPsbt(ctx context.Context, req *wallet.SignPsbtRequest) (*wallet.SignPsbtResponse, error) {
+	resp := &wallet.SignPsbtResponse{
+		Code: wallet.ReturnCode_ERROR,
+	}
+	params, err := networkParams(req.Network)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(req.PsbtBase64), true)
+	if err != nil {
+		log.Error("parse psbt fail", "err", err)
+		resp.Message = "parse psbt fail: " + err.Error()
+		return resp, nil
+	}
+	prevOutFetcher, err := psbtPrevOutFetcher(packet)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	fee, err := psbtFee(packet, prevOutFetcher)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	if err := c.checkFeeRate(withPlaceholderPsbtWitness(packet, prevOutFetcher), fee); err != nil {
+		log.Error("check fee rate fail", "err", err)
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	updater, err := psbt.NewUpdater(packet)
+	if err != nil {
+		resp.Message = "new psbt updater fail: " + err.Error()
+		return resp, nil
+	}
+	sigHashes := txscript.NewTxSigHashes(packet.UnsignedTx, prevOutFetcher)
+	for i := range packet.Inputs {
+		pInput := &packet.Inputs[i]
+		if pInput.FinalScriptSig != nil || pInput.FinalScriptWitness != nil {
+			continue
+		}
+		signed := false
+		for _, pubKey := range psbtInputKeys(pInput, req.PublicKey) {
+			publicKey := hex.EncodeToString(pubKey.SerializeUncompressed())
+			privKey, isOk := c.db.GetPrivKey(publicKey)
+			if !isOk {
+				continue
+			}
+			if err := c.checkKeyNetwork(publicKey, params); err != nil {
+				resp.Message = fmt.Sprintf("psbt input %d: %v", i, err)
+				return resp, nil
+			}
+			ok, err := c.signPsbtInput(updater, sigHashes, prevOutFetcher, i, pubKey, privKey, req.AllowAnySighash)
+			if err != nil {
+				log.Error("sign psbt input fail", "index", i, "err", err)
+				resp.Message = fmt.Sprintf("sign psbt input %d fail: %v", i, err)
+				return resp, nil
+			}
+			signed = signed || ok
+		}
+		if signed {
+			resp.SignedInputs = append(resp.SignedInputs, uint32(i))
+		}
+	}
+
+	if req.Finalize {
+		for i := range packet.Inputs {
+			if _, err := psbt.MaybeFinalize(packet, i); err != nil {
+				log.Warn("finalize psbt input fail", "index", i, "err", err)
+			}
+		}
+		if packet.IsComplete() {
+			tx, err := psbt.Extract(packet)
+			if err != nil {
+				resp.Message = "extract psbt fail: " + err.Error()
+				return resp, nil
+			}
+			if err := verifyTx(tx, prevOutFetcher); err != nil {
+				log.Error("verify psbt tx fail", "err", err)
+				resp.Message = "verify signed tx fail: " + err.Error()
+				return resp, nil
+			}
+			if err := c.checkFeeRate(tx, fee); err != nil {
+				log.Error("check fee rate fail", "err", err)
+				resp.Message = err.Error()
+				return resp, nil
+			}
+			var buf bytes.Buffer
+			if err := tx.Serialize(&buf); err != nil {
+				resp.Message = "serialize tx fail"
+				return resp, nil
+			}
+			resp.SignedTx = hex.EncodeToString(buf.Bytes())
+			resp.TxHash = tx.TxHash().String()
+		}
+	}
+	psbtBase64, err := packet.B64Encode()
+	if err != nil {
+		resp.Message = "encode psbt fail: " + err.Error()
+		return resp, nil
+	}
+	log.Info("sign psbt success", "signedInputs", resp.SignedInputs, "complete", packet.IsComplete(), "txHash", resp.TxHash)
+	resp.Code = wallet.ReturnCode_SUCCESS
+	resp.Message = "sign psbt success"
+	resp.PsbtBase64 = psbtBase64
+	resp.Complete = packet.IsComplete()
+	return resp, nil
+}
+
+// psbtPrevOutFetcher returns the spent outputs from the witness or non witness
+// utxo of every input. The non witness utxo is checked against the outpoint,
+// a witness utxo next to it must be the same output. A witness utxo alone is
+// only taken for a segwit script, the legacy sighash does not commit to the
+// amount it claims.
+func psbtPrevOutFetcher(packet *psbt.Packet) (*txscript.MultiPrevOutFetcher, error) {
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut, len(packet.Inputs))
+	for i, txIn := range packet.UnsignedTx.TxIn {
+		pInput := packet.Inputs[i]
+		outPoint := txIn.PreviousOutPoint
+		switch {
+		case pInput.NonWitnessUtxo != nil:
+			if pInput.NonWitnessUtxo.TxHash() != outPoint.Hash || int(outPoint.Index) >= len(pInput.NonWitnessUtxo.TxOut) {
+				return nil, fmt.Errorf("psbt input %d non witness utxo does not match its outpoint", i)
+			}
+			prevOut := pInput.NonWitnessUtxo.TxOut[outPoint.Index]
+			if witnessUtxo := pInput.WitnessUtxo; witnessUtxo != nil && (witnessUtxo.Value != prevOut.Value || !bytes.Equal(witnessUtxo.PkScript, prevOut.PkScript)) {
+				return nil, fmt.Errorf("psbt input %d witness utxo does not match its non witness utxo", i)
+			}
+			prevOuts[outPoint] = prevOut
+		case pInput.WitnessUtxo != nil:
+			if !isWitnessPrevOut(&pInput, pInput.WitnessUtxo.PkScript) {
+				return nil, fmt.Errorf("psbt input %d spends a non segwit output without its non witness utxo", i)
+			}
+			prevOuts[outPoint] = pInput.WitnessUtxo
+		default:
+			return nil, fmt.Errorf("psbt input %d has no utxo", i)
+		}
+	}
+	return txscript.NewMultiPrevOutFetcher(prevOuts), nil
+}
+
+// isWitnessPrevOut reports whether pkScript is a witness program or a p2sh
+// wrapping the witness program of the input redeem script.
+func isWitnessPrevOut(pInput *psbt.PInput, pkScript []byte) bool {
+	if txscript.IsWitnessProgram(pkScript) {
+		return true
+	}
+	return txscript.IsPayToScriptHash(pkScript) && txscript.IsWitnessProgram(pInput.RedeemScript)
+}
+
+// psbtInputKeys collects the candidate signing keys of an input. An x-only
+// key is tried with both parities.
+func psbtInputKeys(pInput *psbt.PInput, publicKey string) []*btcec.PublicKey {
+	var candidates [][]byte
+	for _, derivation := range pInput.Bip32Derivation {
+		candidates = append(candidates, derivation.PubKey)
+	}
+	xOnlyKeys := [][]byte{pInput.TaprootInternalKey}
+	for _, derivation := range pInput.TaprootBip32Derivation {
+		xOnlyKeys = append(xOnlyKeys, derivation.XOnlyPubKey)
+	}
+	for _, xOnly := range xOnlyKeys {
+		if len(xOnly) == 32 {
+			candidates = append(candidates, append([]byte{0x02}, xOnly...), append([]byte{0x03}, xOnly...))
+		}
+	}
+	for _, script := range [][]byte{pInput.RedeemScript, pInput.WitnessScript} {
+		pushes, err := txscript.PushedData(script)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, pushes...)
+	}
+	if publicKey != "" {
+		if pubKeyBytes, err := hex.DecodeString(publicKey); err == nil {
+			candidates = append(candidates, pubKeyBytes)
+		}
+	}
+
+	var keys []*btcec.PublicKey
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		if len(candidate) != 33 && len(candidate) != 65 {
+			continue
+		}
+		pubKey, err := btcec.ParsePubKey(candidate)
+		if err != nil {
+			continue
+		}
+		id := string(pubKey.SerializeCompressed())
+		if !seen[id] {
+			seen[id] = true
+			keys = append(keys, pubKey)
+		}
+	}
+	return keys
+}
+
+// signPsbtInput signs input i with the key when the key can spend it, it
+// reports false for a key the input script does not commit to.
+func (c *ChainAdaptor) signPsbtInput(updater *psbt.Updater, sigHashes *txscript.TxSigHashes, prevOutFetcher txscript.PrevOutputFetcher, i int, pubKey *btcec.PublicKey, privKey string, allowAnySighash bool) (bool, error) {
+	tx := updater.Upsbt.UnsignedTx
+	pInput := &updater.Upsbt.Inputs[i]
+	prevOut := prevOutFetcher.FetchPrevOutput(tx.TxIn[i].PreviousOutPoint)
+	pkScript := prevOut.PkScript
+	hashType := pInput.SighashType
+
+	if txscript.GetScriptClass(pkScript) == txscript.WitnessV1TaprootTy {
+		if pInput.TaprootKeySpendSig != nil {
+			return false, nil
+		}
+		outputKey := txscript.ComputeTaprootOutputKey(pubKey, pInput.TaprootMerkleRoot)
+		if !bytes.Equal(schnorr.SerializePubKey(outputKey), pkScript[2:]) {
+			return false, nil
+		}
+		if hashType == 0 {
+			hashType = txscript.SigHashDefault
+		}
+		if hashType != txscript.SigHashDefault && hashType != txscript.SigHashAll && !allowAnySighash {
+			return false, fmt.Errorf("sighash type %#x is not allowed", uint32(hashType))
+		}
+		sigHash, err := txscript.CalcTaprootSignatureHash(sigHashes, hashType, tx, i, prevOutFetcher)
+		if err != nil {
+			return false, err
+		}
+		taprootSigner, ok := c.signer.(ssm.TaprootSigner)
+		if !ok {
+			return false, errors.New("signer does not support taproot")
+		}
+		signature, err := taprootSigner.SignTaproot(privKey, hex.EncodeToString(sigHash), hex.EncodeToString(pInput.TaprootMerkleRoot))
+		if err != nil {
+			return false, err
+		}
+		sig, err := hex.DecodeString(signature)
+		if err != nil {
+			return false, err
+		}
+		if hashType != txscript.SigHashDefault {
+			sig = append(sig, byte(hashType))
+		}
+		pInput.TaprootKeySpendSig = sig
+		return true, nil
+	}
+
+	compressed := pubKey.SerializeCompressed()
+	for _, partialSig := range pInput.PartialSigs {
+		if bytes.Equal(partialSig.PubKey, compressed) {
+			return false, nil
+		}
+	}
+	if hashType == 0 {
+		hashType = txscript.SigHashAll
+	}
+	if hashType != txscript.SigHashAll && !allowAnySighash {
+		return false, fmt.Errorf("sighash type %#x is not allowed", uint32(hashType))
+	}
+	sigHash, ok, err := psbtSigHash(pInput, pkScript, prevOut.Value, compressed, sigHashes, hashType, tx, i)
+	if err != nil || !ok {
+		return false, err
+	}
+	signature, err := c.signer.SignMessage(privKey, hex.EncodeToString(sigHash))
+	if err != nil {
+		return false, err
+	}
+	sig, err := derSignature(signature)
+	if err != nil {
+		return false, err
+	}
+	sig = append(sig, byte(hashType))
+	if _, err := updater.Sign(i, sig, compressed, pInput.RedeemScript, pInput.WitnessScript); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// psbtSigHash returns the ecdsa sighash of input i for a p2pkh, p2wpkh, p2wsh
+// or p2sh wrapped pkScript the key can sign.
+func psbtSigHash(pInput *psbt.PInput, pkScript []byte, amount int64, pubKey []byte, sigHashes *txscript.TxSigHashes, hashType txscript.SigHashType, tx *wire.MsgTx, i int) ([]byte, bool, error) {
+	pubKeyHash := btcutil.Hash160(pubKey)
+	switch txscript.GetScriptClass(pkScript) {
+	case txscript.PubKeyHashTy:
+		if !bytes.Equal(pkScript[3:23], pubKeyHash) {
+			return nil, false, nil
+		}
+		sigHash, err := txscript.CalcSignatureHash(pkScript, hashType, tx, i)
+		return sigHash, err == nil, err
+	case txscript.WitnessV0PubKeyHashTy:
+		if !bytes.Equal(pkScript[2:], pubKeyHash) {
+			return nil, false, nil
+		}
+		sigHash, err := txscript.CalcWitnessSigHash(pkScript, sigHashes, hashType, tx, i, amount)
+		return sigHash, err == nil, err
+	case txscript.WitnessV0ScriptHashTy:
+		return witnessScriptSigHash(pInput, pkScript, amount, pubKey, sigHashes, hashType, tx, i)
+	case txscript.ScriptHashTy:
+		redeemScript := pInput.RedeemScript
+		if redeemScript == nil {
+			return nil, false, errors.New("p2sh input without redeem script")
+		}
+		if !bytes.Equal(btcutil.Hash160(redeemScript), pkScript[2:22]) {
+			return nil, false, errors.New("redeem script does not match the input script")
+		}
+		switch txscript.GetScriptClass(redeemScript) {
+		case txscript.WitnessV0PubKeyHashTy:
+			if !bytes.Equal(redeemScript[2:], pubKeyHash) {
+				return nil, false, nil
+			}
+			sigHash, err := txscript.CalcWitnessSigHash(redeemScript, sigHashes, hashType, tx, i, amount)
+			return sigHash, err == nil, err
+		case txscript.WitnessV0ScriptHashTy:
+			return witnessScriptSigHash(pInput, redeemScript, amount, pubKey, sigHashes, hashType, tx, i)
+		}
+		if !scriptHasKey(redeemScript, pubKey) {
+			return nil, false, nil
+		}
+		sigHash, err := txscript.CalcSignatureHash(redeemScript, hashType, tx, i)
+		return sigHash, err == nil, err
+	}
+	return nil, false, nil
+}
+
+func witnessScriptSigHash(pInput *psbt.PInput, program []byte, amount int64, pubKey []byte, sigHashes *txscript.TxSigHashes, hashType txscript.SigHashType, tx *wire.MsgTx, i int) ([]byte, bool, error) {
+	witnessScript := pInput.WitnessScript
+	if witnessScript == nil {
+		return nil, false, errors.New("p2wsh input without witness script")
+	}
+	scriptHash := sha256.Sum256(witnessScript)
+	if !bytes.Equal(scriptHash[:], program[2:]) {
+		return nil, false, errors.New("witness script does not match the input script")
+	}
+	if !scriptHasKey(witnessScript, pubKey) {
+		return nil, false, nil
+	}
+	sigHash, err := txscript.CalcWitnessSigHash(witnessScript, sigHashes, hashType, tx, i, amount)
+	return sigHash, err == nil, err
+}
+
+func scriptHasKey(script []byte, pubKey []byte) bool {
+	pushes, err := txscript.PushedData(script)
+	if err != nil {
+		return false
+	}
+	for _, push := range pushes {
+		if bytes.Equal(push, pubKey) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+package bitcoin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// newTestPsbt returns a base64 psbt spending a p2wpkh utxo of amount held by
+// address to outputs, with the sighash type set on the input.
+func newTestPsbt(t *testing.T, address string, amount int64, outputs []int64, hashType txscript.SigHashType) string {
+	t.Helper()
+	addr, err := btcutil.DecodeAddress(address, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	utxoHash, _ := chainhash.NewHashFromStr("5a7f2b8f0e0d9c3f6b1f3b9b1c0b1e4d2a7e6f3c9d8b7a6f5e4d3c2b1a098765")
+	txOuts := make([]*wire.TxOut, len(outputs))
+	for i, value := range outputs {
+		txOuts[i] = wire.NewTxOut(value, pkScript)
+	}
+	packet, err := psbt.New([]*wire.OutPoint{wire.NewOutPoint(utxoHash, 0)}, txOuts, 2, 0, []uint32{wire.MaxTxInSequenceNum})
+	if err != nil {
+		t.Fatal(err)
+	}
+	updater, err := psbt.NewUpdater(packet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := updater.AddInWitnessUtxo(wire.NewTxOut(amount, pkScript), 0); err != nil {
+		t.Fatal(err)
+	}
+	if hashType != 0 {
+		if err := updater.AddInSighashType(hashType, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	psbtBase64, err := packet.B64Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return psbtBase64
+}
+
+func TestSignPsbtSighashType(t *testing.T) {
+	c := newTestAdaptor(t)
+	key := createTestKey(t, c, "p2wpkh")
+	tests := []struct {
+		name            string
+		hashType        txscript.SigHashType
+		allowAnySighash bool
+		wantErr         bool
+	}{
+		{name: "unset signs all", hashType: 0},
+		{name: "all", hashType: txscript.SigHashAll},
+		{name: "single refused", hashType: txscript.SigHashSingle, wantErr: true},
+		{name: "none anyone can pay refused", hashType: txscript.SigHashNone | txscript.SigHashAnyOneCanPay, wantErr: true},
+		{name: "single allowed", hashType: txscript.SigHashSingle, allowAnySighash: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := c.SignPsbt(context.Background(), &wallet.SignPsbtRequest{
+				PublicKey:       key.PublicKey,
+				PsbtBase64:      newTestPsbt(t, key.Address, 100000, []int64{90000}, tt.hashType),
+				Finalize:        true,
+				AllowAnySighash: tt.allowAnySighash,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				if resp.Code == wallet.ReturnCode_SUCCESS {
+					t.Fatal("psbt signed")
+				}
+				return
+			}
+			if resp.Code != wallet.ReturnCode_SUCCESS || !resp.Complete || resp.SignedTx == "" {
+				t.Fatalf("sign psbt fail: %s", resp.Message)
+			}
+		})
+	}
+}
+
+func TestSignPsbtFee(t *testing.T) {
+	c := newTestAdaptor(t)
+	c.maxFeeRate = 100
+	key := createTestKey(t, c, "p2wpkh")
+	tests := []struct {
+		name    string
+		outputs []int64
+		wantErr bool
+	}{
+		{name: "fee within the cap", outputs: []int64{90000}},
+		{name: "dust output", outputs: []int64{90000, 100}, wantErr: true},
+		{name: "outputs above inputs", outputs: []int64{100001}, wantErr: true},
+		{name: "fee rate above the cap", outputs: []int64{50000}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := c.SignPsbt(context.Background(), &wallet.SignPsbtRequest{
+				PublicKey:  key.PublicKey,
+				PsbtBase64: newTestPsbt(t, key.Address, 100000, tt.outputs, 0),
+				Finalize:   true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				if resp.Code == wallet.ReturnCode_SUCCESS || len(resp.SignedInputs) != 0 {
+					t.Fatal("psbt signed")
+				}
+				return
+			}
+			if resp.Code != wallet.ReturnCode_SUCCESS || !resp.Complete {
+				t.Fatalf("sign psbt fail: %s", resp.Message)
+			}
+		})
+	}
+}
+
+// newTestLegacyPsbt returns a base64 psbt spending output 0 of amount held by
+// address to a single output, with the previous transaction as non witness
+// utxo when nonWitnessUtxo is set and the given witness utxo.
+func newTestLegacyPsbt(t *testing.T, address string, amount int64, nonWitnessUtxo bool, witnessUtxo *wire.TxOut) string {
+	t.Helper()
+	addr, err := btcutil.DecodeAddress(address, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prevTx := wire.NewMsgTx(2)
+	prevTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil))
+	prevTx.AddTxOut(wire.NewTxOut(amount, pkScript))
+	prevTxHash := prevTx.TxHash()
+	packet, err := psbt.New([]*wire.OutPoint{wire.NewOutPoint(&prevTxHash, 0)}, []*wire.TxOut{wire.NewTxOut(amount-10000, pkScript)}, 2, 0, []uint32{wire.MaxTxInSequenceNum})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonWitnessUtxo {
+		packet.Inputs[0].NonWitnessUtxo = prevTx
+	}
+	packet.Inputs[0].WitnessUtxo = witnessUtxo
+	psbtBase64, err := packet.B64Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return psbtBase64
+}
+
+func TestSignPsbtLegacyUtxo(t *testing.T) {
+	c := newTestAdaptor(t)
+	key := createTestKey(t, c, "p2pkh")
+	addr, err := btcutil.DecodeAddress(key.Address, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name           string
+		nonWitnessUtxo bool
+		witnessUtxo    *wire.TxOut
+		wantErr        bool
+	}{
+		{name: "non witness utxo", nonWitnessUtxo: true},
+		// a witness utxo claiming a lower amount hides the real fee
+		{name: "lying witness utxo only", witnessUtxo: wire.NewTxOut(20000, pkScript), wantErr: true},
+		{name: "witness utxo only", witnessUtxo: wire.NewTxOut(100000, pkScript), wantErr: true},
+		{name: "lying witness utxo next to non witness utxo", nonWitnessUtxo: true, witnessUtxo: wire.NewTxOut(20000, pkScript), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := c.SignPsbt(context.Background(), &wallet.SignPsbtRequest{
+				PublicKey:  key.PublicKey,
+				PsbtBase64: newTestLegacyPsbt(t, key.Address, 100000, tt.nonWitnessUtxo, tt.witnessUtxo),
+				Finalize:   true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				if resp.Code == wallet.ReturnCode_SUCCESS || len(resp.SignedInputs) != 0 {
+					t.Fatal("psbt signed")
+				}
+				return
+			}
+			if resp.Code != wallet.ReturnCode_SUCCESS || !resp.Complete || resp.SignedTx == "" {
+				t.Fatalf("sign psbt fail: %s", resp.Message)
+			}
+		})
+	}
+}
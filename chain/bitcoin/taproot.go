@@ -0,0 +1,140 @@
+package bitcoin
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// CreateTaprootAddress commits the internal key to the script tree of the
+// leaves, assembled in order into a balanced tree, and returns the p2tr
+// address with the control block of every leaf for script path spends.
+func (c *ChainAdaptor) CreateTaprootAddress(ctx context.Context, req *wallet.CreateTaprootAddressRequest) (*wallet.CreateTaprootAddressResponse, error) {
+	resp := &wallet.CreateTaprootAddressResponse{
+		Code: wallet.ReturnCode_ERROR,
+	}
+	params, err := networkParams(req.Network)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	internalKey, err := parseInternalKey(req.InternalKey)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	if len(req.Leaves) == 0 {
+		resp.Message = "no tap leaves, use a p2tr key address"
+		return resp, nil
+	}
+	leaves := make([]txscript.TapLeaf, len(req.Leaves))
+	for i, leaf := range req.Leaves {
+		script, err := hex.DecodeString(leaf.Script)
+		if err != nil || len(script) == 0 {
+			resp.Message = fmt.Sprintf("invalid script of leaf %d", i)
+			return resp, nil
+		}
+		leafVersion := txscript.BaseLeafVersion
+		if leaf.LeafVersion != 0 {
+			if leaf.LeafVersion > 0xfe || leaf.LeafVersion&1 != 0 || leaf.LeafVersion == txscript.TaprootAnnexTag {
+				resp.Message = fmt.Sprintf("invalid version %#x of leaf %d", leaf.LeafVersion, i)
+				return resp, nil
+			}
+			leafVersion = txscript.TapscriptLeafVersion(leaf.LeafVersion)
+		}
+		leaves[i] = txscript.NewTapLeaf(leafVersion, script)
+	}
+	tree := txscript.AssembleTaprootScriptTree(leaves...)
+	merkleRoot := tree.RootNode.TapHash()
+	outputKey := schnorr.SerializePubKey(txscript.ComputeTaprootOutputKey(internalKey, merkleRoot[:]))
+	taprootAddr, err := btcutil.NewAddressTaproot(outputKey, params)
+	if err != nil {
+		resp.Message = "create p2tr address fail"
+		return resp, nil
+	}
+	for i, proof := range tree.LeafMerkleProofs {
+		controlBlock := proof.ToControlBlock(internalKey)
+		controlBlockBytes, err := controlBlock.ToBytes()
+		if err != nil {
+			resp.Message = fmt.Sprintf("serialize control block of leaf %d fail", i)
+			return resp, nil
+		}
+		leafHash := proof.TapLeaf.TapHash()
+		resp.Leaves = append(resp.Leaves, &wallet.TapLeafProof{
+			Script:       hex.EncodeToString(proof.TapLeaf.Script),
+			LeafVersion:  uint32(proof.TapLeaf.LeafVersion),
+			LeafHash:     hex.EncodeToString(leafHash[:]),
+			ControlBlock: hex.EncodeToString(controlBlockBytes),
+		})
+	}
+	log.Info("create taproot address success", "address", taprootAddr.EncodeAddress(), "leaves", len(leaves))
+	resp.Code = wallet.ReturnCode_SUCCESS
+	resp.Message = "create taproot address success"
+	resp.Address = taprootAddr.EncodeAddress()
+	resp.OutputKey = hex.EncodeToString(outputKey)
+	resp.MerkleRoot = hex.EncodeToString(merkleRoot[:])
+	return resp, nil
+}
+
+// parseInternalKey accepts an x-only, compressed or uncompressed key.
+func parseInternalKey(internalKey string) (*btcec.PublicKey, error) {
+	keyBytes, err := hex.DecodeString(internalKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid internal key: %s", internalKey)
+	}
+	if len(keyBytes) == schnorr.PubKeyBytesLen {
+		return schnorr.ParsePubKey(keyBytes)
+	}
+	pubKey, err := btcec.ParsePubKey(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse internal key fail: %w", err)
+	}
+	return pubKey, nil
+}
+
+// setTapLeaf checks that the control block commits the leaf script to the
+// taproot output of the vin and that the vin key is a key of the leaf.
+func setTapLeaf(input *InputSignHash, in *Vin, witnessProgram []byte) error {
+	script, err := hex.DecodeString(in.TapLeafScript)
+	if err != nil || len(script) == 0 {
+		return fmt.Errorf("invalid tap leaf script: %s", in.TapLeafScript)
+	}
+	controlBlock, err := hex.DecodeString(in.ControlBlock)
+	if err != nil {
+		return fmt.Errorf("invalid control block: %s", in.ControlBlock)
+	}
+	cb, err := txscript.ParseControlBlock(controlBlock)
+	if err != nil {
+		return fmt.Errorf("parse control block fail: %w", err)
+	}
+	// only base tapscript leaves define CHECKSIG over the BIP342 sighash
+	if cb.LeafVersion != txscript.BaseLeafVersion {
+		return fmt.Errorf("unsupported leaf version %#x", cb.LeafVersion)
+	}
+	if err := txscript.VerifyTaprootLeafCommitment(cb, witnessProgram, script); err != nil {
+		return fmt.Errorf("address %s does not commit to the tap leaf", in.Address)
+	}
+	if !scriptHasKey(script, schnorr.SerializePubKey(input.pubKey)) {
+		return errors.New("public key is not a key of the tap leaf script")
+	}
+	if in.Annex != "" {
+		annex, err := hex.DecodeString(in.Annex)
+		if err != nil || len(annex) == 0 || annex[0] != txscript.TaprootAnnexTag {
+			return fmt.Errorf("invalid annex: %s", in.Annex)
+		}
+		input.annex = annex
+	}
+	input.tapLeaf = txscript.NewBaseTapLeaf(script)
+	leafHash := input.tapLeaf.TapHash()
+	input.LeafHash = leafHash[:]
+	input.controlBlock = controlBlock
+	return nil
+}
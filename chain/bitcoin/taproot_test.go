@@ -0,0 +1,48 @@
+package bitcoin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
+)
+
+// TestCreateTaprootAddressBIP341 checks the output key and address against
+// the scriptPubKey vectors of BIP341.
+func TestCreateTaprootAddressBIP341(t *testing.T) {
+	tests := []struct {
+		name        string
+		internalKey string
+		leaves      []*wallet.TapLeaf
+		outputKey   string
+		address     string
+	}{
+		{
+			name:        "single leaf",
+			internalKey: "187791b6f712a8ea41c8ecdd0ee77fab3e85263b37e1ec18a3651926b3a6cf27",
+			leaves: []*wallet.TapLeaf{
+				{Script: "20d85a959b0290bf19bb89ed43c916be835475d013da4b362117393e25a48229b8ac", LeafVersion: 0xc0},
+			},
+			outputKey: "147c9c57132f6e7ecddba9800bb0c4449251c92a1e60371ee77557b6620f3ea3",
+			address:   "bc1pz37fc4cn9ah8anwm4xqqhvxygjf9rjf2resrw8h8w4tmvcs0863sa2e586",
+		},
+	}
+	c := newTestAdaptor(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := c.CreateTaprootAddress(context.Background(), &wallet.CreateTaprootAddressRequest{
+				InternalKey: tt.internalKey,
+				Leaves:      tt.leaves,
+			})
+			if err != nil || resp.Code != wallet.ReturnCode_SUCCESS {
+				t.Fatalf("create taproot address fail: %v %s", err, resp.GetMessage())
+			}
+			if resp.OutputKey != tt.outputKey {
+				t.Fatalf("output key %s, want %s", resp.OutputKey, tt.outputKey)
+			}
+			if resp.Address != tt.address {
+				t.Fatalf("address %s, want %s", resp.Address, tt.address)
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/DQYXACML/wallet-sign/ssm"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// InputSignHash is the sighash of a vin with the key and prevout it spends.
+// RedeemScript is the witness program of a p2sh-p2wpkh or p2sh-p2wsh input,
+// WitnessScript is set for a multisig input and PublicKey otherwise. LeafHash
+// is the tap leaf of a taproot script path input.
+type InputSignHash struct {
+	PublicKey     string
+	Class         txscript.ScriptClass
+	PkScript      []byte
+	RedeemScript  []byte
+	WitnessScript []byte
+	LeafHash      []byte
+	SigHash       []byte
+
+	pubKey *btcec.PublicKey
+	// taproot key path merkle root, script path leaf, control block and annex
+	merkleRoot   []byte
+	tapLeaf      txscript.TapLeaf
+	controlBlock []byte
+	annex        []byte
+	// multisig keys in script order, signatures required and cosigner
+	// signatures by compressed key
+	pubKeys      []*btcec.PublicKey
+	required     int
+	cosignerSigs map[string][]byte
+	complete     bool
+}
+
+// CalcSignHashes builds the unsigned transaction paying txOuts and the
+// sighash of every vin, legacy for p2pkh, BIP143 for segwit v0, BIP341 for
+// taproot key path and BIP342 for tapscript inputs. A vin without public_key
+// or witness_script spends publicKey. Every address must be of the params
+// network, as must the keys created with one.
+func (c *ChainAdaptor) CalcSignHashes(vins []*Vin, txOuts []*wire.TxOut, lockTime uint32, publicKey string, params *chaincfg.Params) (*wire.MsgTx, []*InputSignHash, error) {
+	if len(vins) == 0 || len(txOuts) == 0 {
+		return nil, nil, errors.New("invalid len in or out")
+	}
+	rawTx := wire.NewMsgTx(wire.TxVersion)
+	rawTx.LockTime = lockTime
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut, len(vins))
+	inputs := make([]*InputSignHash, len(vins))
+	for i, in := range vins {
+		utxoHash, err := chainhash.NewHashFromStr(in.Hash)
+		if err != nil {
+			return nil, nil, err
+		}
+		outPoint := wire.NewOutPoint(utxoHash, uint32(in.Index))
+		if _, ok := prevOuts[*outPoint]; ok {
+			return nil, nil, fmt.Errorf("duplicate vin %s:%d", in.Hash, in.Index)
+		}
+		input, err := newInputSignHash(in, publicKey, params)
+		if err != nil {
+			return nil, nil, fmt.Errorf("vin %d: %w", i, err)
+		}
+		publicKeys := []string{input.PublicKey}
+		for _, pubKey := range input.pubKeys {
+			publicKeys = append(publicKeys, hex.EncodeToString(pubKey.SerializeUncompressed()))
+		}
+		for _, key := range publicKeys {
+			if err := c.checkKeyNetwork(key, params); err != nil {
+				return nil, nil, fmt.Errorf("vin %d: %w", i, err)
+			}
+		}
+		inputs[i] = input
+		prevOuts[*outPoint] = wire.NewTxOut(int64(in.Amount), input.PkScript)
+		txIn := wire.NewTxIn(outPoint, nil, nil)
+		if in.Sequence != nil {
+			txIn.Sequence = *in.Sequence
+			rawTx.Version = 2
+		}
+		rawTx.AddTxIn(txIn)
+	}
+
+	for _, txOut := range txOuts {
+		rawTx.AddTxOut(txOut)
+	}
+
+	prevOutFetcher := txscript.NewMultiPrevOutFetcher(prevOuts)
+	sigHashes := txscript.NewTxSigHashes(rawTx, prevOutFetcher)
+	for i, input := range inputs {
+		sigHash, err := calcSigHash(rawTx, i, input, int64(vins[i].Amount), sigHashes, prevOutFetcher)
+		if err != nil {
+			log.Info("Calc signature hash error", "err", err)
+			return nil, nil, err
+		}
+		input.SigHash = sigHash
+	}
+	return rawTx, inputs, nil
+}
+
+// calcSigHash is the sighash of input i of tx by the class of its prevout.
+func calcSigHash(tx *wire.MsgTx, i int, input *InputSignHash, amount int64, sigHashes *txscript.TxSigHashes, prevOutFetcher txscript.PrevOutputFetcher) ([]byte, error) {
+	switch input.Class {
+	case txscript.PubKeyHashTy:
+		return txscript.CalcSignatureHash(input.PkScript, txscript.SigHashAll, tx, i)
+	case txscript.WitnessV0PubKeyHashTy:
+		return txscript.CalcWitnessSigHash(input.PkScript, sigHashes, txscript.SigHashAll, tx, i, amount)
+	case txscript.ScriptHashTy, txscript.WitnessV0ScriptHashTy:
+		script := input.RedeemScript
+		if input.WitnessScript != nil {
+			script = input.WitnessScript
+		}
+		return txscript.CalcWitnessSigHash(script, sigHashes, txscript.SigHashAll, tx, i, amount)
+	case txscript.WitnessV1TaprootTy:
+		if input.LeafHash == nil {
+			return txscript.CalcTaprootSignatureHash(sigHashes, txscript.SigHashDefault, tx, i, prevOutFetcher)
+		}
+		var opts []txscript.TaprootSigHashOption
+		if input.annex != nil {
+			opts = append(opts, txscript.WithAnnex(input.annex))
+		}
+		return txscript.CalcTapscriptSignaturehash(sigHashes, txscript.SigHashDefault, tx, i, prevOutFetcher, input.tapLeaf, opts...)
+	}
+	return nil, fmt.Errorf("unsupported script class %s", input.Class)
+}
+
+// newInputSignHash checks that the vin address is the p2pkh, p2wpkh,
+// p2sh-p2wpkh or p2tr address of the vin public key, the multisig address of
+// its witness script or a p2tr address committing to its tap leaf.
+func newInputSignHash(in *Vin, publicKey string, params *chaincfg.Params) (*InputSignHash, error) {
+	fromAddr, err := decodeAddress(in.Address, params)
+	if err != nil {
+		return nil, err
+	}
+	if in.WitnessScript != "" {
+		return newMultisigInput(in, fromAddr, params)
+	}
+	if in.PublicKey != "" {
+		publicKey = in.PublicKey
+	}
+	pubKeyBytes, err := hex.DecodeString(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %s", publicKey)
+	}
+	pubKey, err := btcec.ParsePubKey(pubKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key fail: %w", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(fromAddr)
+	if err != nil {
+		return nil, err
+	}
+	input := &InputSignHash{
+		PublicKey: publicKey,
+		Class:     txscript.GetScriptClass(pkScript),
+		PkScript:  pkScript,
+		pubKey:    pubKey,
+	}
+	if input.Class != txscript.WitnessV1TaprootTy && (in.MerkleRoot != "" || in.TapLeafScript != "" || in.ControlBlock != "" || in.Annex != "") {
+		return nil, fmt.Errorf("taproot fields on a %s vin", input.Class)
+	}
+	pubKeyHash := btcutil.Hash160(pubKey.SerializeCompressed())
+	var expected []byte
+	switch input.Class {
+	case txscript.PubKeyHashTy, txscript.WitnessV0PubKeyHashTy:
+		expected = pubKeyHash
+	case txscript.ScriptHashTy:
+		witnessAddr, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, params)
+		if err != nil {
+			return nil, err
+		}
+		if input.RedeemScript, err = txscript.PayToAddrScript(witnessAddr); err != nil {
+			return nil, err
+		}
+		expected = btcutil.Hash160(input.RedeemScript)
+	case txscript.WitnessV1TaprootTy:
+		if in.TapLeafScript != "" || in.ControlBlock != "" {
+			if err := setTapLeaf(input, in, fromAddr.ScriptAddress()); err != nil {
+				return nil, err
+			}
+			return input, nil
+		}
+		if in.Annex != "" {
+			return nil, errors.New("annex is only supported on script path vins")
+		}
+		if input.merkleRoot, err = decodeMerkleRoot(in.MerkleRoot); err != nil {
+			return nil, err
+		}
+		expected = schnorr.SerializePubKey(txscript.ComputeTaprootOutputKey(pubKey, input.merkleRoot))
+	default:
+		return nil, fmt.Errorf("unsupported vin address type: %s", input.Class)
+	}
+	if !bytes.Equal(fromAddr.ScriptAddress(), expected) {
+		return nil, fmt.Errorf("address %s is not owned by the public key", in.Address)
+	}
+	return input, nil
+}
+
+// signInput signs vin i and sets its script sig and witness, a multisig vin
+// returns the signatures of the keystore keys and may still miss some.
+func (c *ChainAdaptor) signInput(tx *wire.MsgTx, i int, input *InputSignHash) ([]*PartialSignature, error) {
+	if input.WitnessScript != nil {
+		return c.signMultisigInput(tx, i, input)
+	}
+	privKey, isOk := c.db.GetPrivKey(input.PublicKey)
+	if !isOk {
+		return nil, errors.New("get private key by public key fail")
+	}
+	if input.Class == txscript.WitnessV1TaprootTy {
+		taprootSigner, ok := c.signer.(ssm.TaprootSigner)
+		if !ok {
+			return nil, errors.New("signer does not support taproot")
+		}
+		var signature string
+		var err error
+		if input.LeafHash != nil {
+			signature, err = taprootSigner.SignSchnorr(privKey, hex.EncodeToString(input.SigHash))
+		} else {
+			signature, err = taprootSigner.SignTaproot(privKey, hex.EncodeToString(input.SigHash), hex.EncodeToString(input.merkleRoot))
+		}
+		if err != nil {
+			return nil, err
+		}
+		sig, err := hex.DecodeString(signature)
+		if err != nil {
+			return nil, err
+		}
+		// SigHashDefault signatures carry no sighash type byte
+		tx.TxIn[i].Witness = wire.TxWitness{sig}
+		if input.LeafHash != nil {
+			tx.TxIn[i].Witness = append(tx.TxIn[i].Witness, input.tapLeaf.Script, input.controlBlock)
+			if input.annex != nil {
+				tx.TxIn[i].Witness = append(tx.TxIn[i].Witness, input.annex)
+			}
+		}
+		input.complete = true
+		return nil, nil
+	}
+
+	signature, err := c.signer.SignMessage(privKey, hex.EncodeToString(input.SigHash))
+	if err != nil {
+		return nil, err
+	}
+	sig, err := derSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	sig = append(sig, byte(txscript.SigHashAll))
+	pubKey := input.pubKey.SerializeCompressed()
+	switch input.Class {
+	case txscript.PubKeyHashTy:
+		tx.TxIn[i].SignatureScript, err = txscript.NewScriptBuilder().AddData(sig).AddData(pubKey).Script()
+	case txscript.WitnessV0PubKeyHashTy:
+		tx.TxIn[i].Witness = wire.TxWitness{sig, pubKey}
+	case txscript.ScriptHashTy:
+		tx.TxIn[i].SignatureScript, err = txscript.NewScriptBuilder().AddData(input.RedeemScript).Script()
+		tx.TxIn[i].Witness = wire.TxWitness{sig, pubKey}
+	}
+	input.complete = err == nil
+	return nil, err
+}
+
+// derSignature converts the signer [R || S || V] signature to low S DER.
+func derSignature(signature string) ([]byte, error) {
+	sig, err := hex.DecodeString(signature)
+	if err != nil || len(sig) != 65 {
+		return nil, fmt.Errorf("invalid signature: %s", signature)
+	}
+	var r, s btcec.ModNScalar
+	if r.SetByteSlice(sig[:32]) || s.SetByteSlice(sig[32:64]) {
+		return nil, errors.New("signature overflows curve order")
+	}
+	return ecdsa.NewSignature(&r, &s).Serialize(), nil
+}
+
+// checkKeyNetwork refuses a keystore key created for another network.
+func (c *ChainAdaptor) checkKeyNetwork(publicKey string, params *chaincfg.Params) error {
+	if meta, ok := c.db.GetKeyMeta(publicKey); ok && meta.Network != "" && meta.Network != params.Name {
+		return fmt.Errorf("key was created for %s", meta.Network)
+	}
+	return nil
+}
+
+// verifyInputs runs the script engine over every signed input.
+func verifyInputs(tx *wire.MsgTx, vins []*Vin, inputs []*InputSignHash) error {
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut, len(vins))
+	for i, txIn := range tx.TxIn {
+		prevOuts[txIn.PreviousOutPoint] = wire.NewTxOut(int64(vins[i].Amount), inputs[i].PkScript)
+	}
+	return verifyTx(tx, txscript.NewMultiPrevOutFetcher(prevOuts))
+}
+
+func verifyTx(tx *wire.MsgTx, prevOutFetcher txscript.PrevOutputFetcher) error {
+	sigHashes := txscript.NewTxSigHashes(tx, prevOutFetcher)
+	for i, txIn := range tx.TxIn {
+		prevOut := prevOutFetcher.FetchPrevOutput(txIn.PreviousOutPoint)
+		engine, err := txscript.NewEngine(prevOut.PkScript, tx, i, txscript.StandardVerifyFlags, nil, sigHashes, prevOut.Value, prevOutFetcher)
+		if err != nil {
+			return err
+		}
+		if err := engine.Execute(); err != nil {
+			return fmt.Errorf("vin %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package bitcoin
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/DQYXACML/wallet-sign/config"
+	"github.com/DQYXACML/wallet-sign/leveldb"
+	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+func newTestAdaptor(t *testing.T) *ChainAdaptor {
+	t.Helper()
+	db, err := leveldb.NewKeyStore(t.TempDir(), "", "test passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	adaptor, err := NewChainAdaptor(&config.Config{}, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return adaptor.(*ChainAdaptor)
+}
+
+func createTestKey(t *testing.T, c *ChainAdaptor, addressFormat string) *wallet.ExportPublicKeyWithAddress {
+	t.Helper()
+	resp, err := c.CreateKeyPairsWithAddresses(context.Background(), &wallet.CreateKeyPairsWithAddressesRequest{
+		KeyNum:        1,
+		AddressFormat: addressFormat,
+	})
+	if err != nil || resp.Code != wallet.ReturnCode_SUCCESS {
+		t.Fatalf("create %s key fail: %v %s", addressFormat, err, resp.GetMessage())
+	}
+	return resp.PublicKeyAddresses[0]
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// TestCalcSigHashBIP143 checks the segwit v0 sighash against the native
+// p2wpkh and p2sh-p2wpkh examples of BIP143.
+func TestCalcSigHashBIP143(t *testing.T) {
+	tests := []struct {
+		name      string
+		unsigned  string
+		index     int
+		publicKey string
+		p2sh      bool
+		prevOuts  []*wire.TxOut
+		pkScript  string
+		sigHash   string
+	}{
+		{
+			name:      "native p2wpkh",
+			unsigned:  "0100000002fff7f7881a8099afa6940d42d1e7f6362bec38171ea3edf433541db4e4ad969f0000000000eeffffffef51e1b804cc89d182d279655c3aa89e815b1b309fe287d9b2b55d57b90ec68a0100000000ffffffff02202cb206000000001976a9148280b37df378db99f66f85c95a783a76ac7a6d5988ac9093510d000000001976a9143bde42dbee7e4dbe6a21b2d50ce2f0167faa815988ac11000000",
+			index:     1,
+			publicKey: "025476c2e83188368da1ff3e292e7acafcdb3566bb0ad253f62fc70f07aeee6357",
+			prevOuts: []*wire.TxOut{
+				wire.NewTxOut(625000000, []byte{}),
+				wire.NewTxOut(600000000, []byte{}),
+			},
+			pkScript: "00141d0f172a0ecb48aee1be1f2687d2963ae33f71a1",
+			sigHash:  "c37af31116d1b27caf68aae9e3ac82f1477929014d5b917657d0eb49478cb670",
+		},
+		{
+			name:      "p2sh-p2wpkh",
+			unsigned:  "0100000001db6b1b20aa0fd7b23880be2ecbd4a98130974cf4748fb66092ac4d3ceb1a54770100000000feffffff02b8b4eb0b000000001976a914a457b684d7f0d539a46a45bbc043f35b59d0d96388ac0008af2f000000001976a914fd270b1ee6abcaea97fea7ad0402e8bd8ad6d77c88ac92040000",
+			index:     0,
+			publicKey: "03ad1d8e89212f0b92c74d23bb710c00662ad1470198ac48c43f7d6f93a2a26873",
+			p2sh:      true,
+			prevOuts: []*wire.TxOut{
+				wire.NewTxOut(1000000000, []byte{}),
+			},
+			pkScript: "a9144733f37cf4db86fbc2efed2500b4f4e49f31202387",
+			sigHash:  "64f3b0f4dd2bb3aa1ce8566d220cc74dda9df97d8490cc81d89d735c92e59fb6",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &chaincfg.MainNetParams
+			tx := wire.NewMsgTx(wire.TxVersion)
+			if err := tx.Deserialize(bytes.NewReader(mustDecodeHex(t, tt.unsigned))); err != nil {
+				t.Fatal(err)
+			}
+			pubKeyHash := btcutil.Hash160(mustDecodeHex(t, tt.publicKey))
+			var addr btcutil.Address
+			addr, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.p2sh {
+				redeemScript, _ := txscript.PayToAddrScript(addr)
+				if addr, err = btcutil.NewAddressScriptHash(redeemScript, params); err != nil {
+					t.Fatal(err)
+				}
+			}
+			input, err := newInputSignHash(&Vin{Address: addr.EncodeAddress(), PublicKey: tt.publicKey}, "", params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hex.EncodeToString(input.PkScript) != tt.pkScript {
+				t.Fatalf("pk script %x, want %s", input.PkScript, tt.pkScript)
+			}
+			prevOuts := make(map[wire.OutPoint]*wire.TxOut, len(tt.prevOuts))
+			for i, prevOut := range tt.prevOuts {
+				prevOuts[tx.TxIn[i].PreviousOutPoint] = prevOut
+			}
+			prevOutFetcher := txscript.NewMultiPrevOutFetcher(prevOuts)
+			sigHashes := txscript.NewTxSigHashes(tx, prevOutFetcher)
+			amount := tt.prevOuts[tt.index].Value
+			sigHash, err := calcSigHash(tx, tt.index, input, amount, sigHashes, prevOutFetcher)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hex.EncodeToString(sigHash) != tt.sigHash {
+				t.Fatalf("sighash %x, want %s", sigHash, tt.sigHash)
+			}
+		})
+	}
+}
+
+// TestBuildAndSignTransactionScriptEngine signs one vin of every supported
+// input type and runs the signed transaction through the script engine, the
+// taproot inputs check the BIP341 and BIP342 sighashes.
+func TestBuildAndSignTransactionScriptEngine(t *testing.T) {
+	c := newTestAdaptor(t)
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		vin  func(t *testing.T) (*Vin, string)
+	}{
+		{
+			name: "p2pkh",
+			vin: func(t *testing.T) (*Vin, string) {
+				key := createTestKey(t, c, "p2pkh")
+				return &Vin{Address: key.Address}, key.PublicKey
+			},
+		},
+		{
+			name: "p2wpkh",
+			vin: func(t *testing.T) (*Vin, string) {
+				key := createTestKey(t, c, "p2wpkh")
+				return &Vin{Address: key.Address}, key.PublicKey
+			},
+		},
+		{
+			name: "p2sh-p2wpkh",
+			vin: func(t *testing.T) (*Vin, string) {
+				key := createTestKey(t, c, "p2sh")
+				return &Vin{Address: key.Address}, key.PublicKey
+			},
+		},
+		{
+			name: "p2tr key path",
+			vin: func(t *testing.T) (*Vin, string) {
+				key := createTestKey(t, c, "p2tr")
+				return &Vin{Address: key.Address}, key.PublicKey
+			},
+		},
+		{
+			name: "p2tr script path",
+			vin: func(t *testing.T) (*Vin, string) {
+				internalKey := createTestKey(t, c, "p2tr")
+				key := createTestKey(t, c, "p2tr")
+				pubKey, err := btcec.ParsePubKey(mustDecodeHex(t, key.CompressPublicKey))
+				if err != nil {
+					t.Fatal(err)
+				}
+				leafScript, err := txscript.NewScriptBuilder().
+					AddData(schnorr.SerializePubKey(pubKey)).
+					AddOp(txscript.OP_CHECKSIG).
+					Script()
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp, err := c.CreateTaprootAddress(ctx, &wallet.CreateTaprootAddressRequest{
+					InternalKey: internalKey.CompressPublicKey,
+					Leaves:      []*wallet.TapLeaf{{Script: hex.EncodeToString(leafScript)}},
+				})
+				if err != nil || resp.Code != wallet.ReturnCode_SUCCESS {
+					t.Fatalf("create taproot address fail: %v %s", err, resp.GetMessage())
+				}
+				return &Vin{
+					Address:       resp.Address,
+					TapLeafScript: resp.Leaves[0].Script,
+					ControlBlock:  resp.Leaves[0].ControlBlock,
+				}, key.PublicKey
+			},
+		},
+		{
+			name: "p2wsh multisig",
+			vin: func(t *testing.T) (*Vin, string) {
+				key := createTestKey(t, c, "p2wpkh")
+				cosigner := createTestKey(t, c, "p2wpkh")
+				resp, err := c.CreateMultisigAddress(ctx, &wallet.CreateMultisigAddressRequest{
+					AddressFormat: AddressFormatP2WSH,
+					Threshold:     1,
+					PublicKeys:    []string{key.CompressPublicKey, cosigner.CompressPublicKey},
+				})
+				if err != nil || resp.Code != wallet.ReturnCode_SUCCESS {
+					t.Fatalf("create multisig address fail: %v %s", err, resp.GetMessage())
+				}
+				return &Vin{Address: resp.Address, WitnessScript: resp.WitnessScript}, key.PublicKey
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vin, publicKey := tt.vin(t)
+			vin.Hash = "5a7f2b8f0e0d9c3f6b1f3b9b1c0b1e4d2a7e6f3c9d8b7a6f5e4d3c2b1a098765"
+			vin.Amount = 100000
+			body, err := json.Marshal(&BitcoinSchema{
+				Fee:   "10000",
+				Vins:  []*Vin{vin},
+				Vouts: []*Vout{{Address: vin.Address, Amount: 90000}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := c.BuildAndSignTransaction(ctx, &wallet.BuildAndSignTransactionRequest{
+				PublicKey:    publicKey,
+				TxBase64Body: base64.StdEncoding.EncodeToString(body),
+			})
+			if err != nil || resp.Code != wallet.ReturnCode_SUCCESS {
+				t.Fatalf("sign fail: %v %s", err, resp.GetMessage())
+			}
+			tx := wire.NewMsgTx(wire.TxVersion)
+			if err := tx.Deserialize(bytes.NewReader(mustDecodeHex(t, resp.SignedTx))); err != nil {
+				t.Fatal(err)
+			}
+			if tx.TxHash().String() != resp.TxHash {
+				t.Fatalf("tx hash %s, want %s", resp.TxHash, tx.TxHash())
+			}
+			addr, err := btcutil.DecodeAddress(vin.Address, &chaincfg.MainNetParams)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pkScript, err := txscript.PayToAddrScript(addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			prevOutFetcher := txscript.NewCannedPrevOutputFetcher(pkScript, int64(vin.Amount))
+			engine, err := txscript.NewEngine(pkScript, tx, 0, txscript.StandardVerifyFlags, nil,
+				txscript.NewTxSigHashes(tx, prevOutFetcher), int64(vin.Amount), prevOutFetcher)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := engine.Execute(); err != nil {
+				t.Fatalf("script engine: %v", err)
+			}
+		})
+	}
+}
@@ -1,10 +1,24 @@
 package bitcoin
 
+// Vin spends the utxo hash:index of amount sats held by address. PublicKey is
+// the key of address, the request public key when empty. A multisig vin has
+// the witness script of address instead and the cosigner signatures so far,
+// keyed by compressed public key. A p2tr vin spends the key path of the
+// public key tweaked with the optional merkle root, or with a tap leaf script
+// and its control block the script path signed by the public key.
 type Vin struct {
-	Address string `json:"address"`
-	Hash    string `json:"hash"`
-	Index   uint64 `json:"index"`
-	Amount  uint64 `json:"amount"`
+	Address       string            `json:"address"`
+	PublicKey     string            `json:"public_key,omitempty"`
+	WitnessScript string            `json:"witness_script,omitempty"`
+	Signatures    map[string]string `json:"signatures,omitempty"`
+	MerkleRoot    string            `json:"merkle_root,omitempty"`
+	TapLeafScript string            `json:"tap_leaf_script,omitempty"`
+	ControlBlock  string            `json:"control_block,omitempty"`
+	Annex         string            `json:"annex,omitempty"`
+	Sequence      *uint32           `json:"sequence,omitempty"`
+	Hash          string            `json:"hash"`
+	Index         uint64            `json:"index"`
+	Amount        uint64            `json:"amount"`
 }
 
 type Vout struct {
@@ -13,9 +27,15 @@ type Vout struct {
 	Index   uint64 `json:"index"`
 }
 
+// BitcoinSchema is the transaction request body. Fee is in sats and must be
+// the vins minus the vouts, unless Change sends that remainder minus the fee
+// to the configured change address. A vin sequence makes a version 2
+// transaction for BIP68 relative timelocks.
 type BitcoinSchema struct {
 	RequestId string  `json:"request_id"`
 	Fee       string  `json:"fee"`
+	Change    bool    `json:"change"`
+	LockTime  uint32  `json:"lock_time,omitempty"`
 	Vins      []*Vin  `json:"vins"`
 	Vouts     []*Vout `json:"vouts"`
 }
@@ -15,4 +15,17 @@ type IChainAdaptor interface {
 	// 完整的签名流程
 	BuildAndSignTransaction(ctx context.Context, req *wallet.BuildAndSignTransactionRequest) (*wallet.BuildAndSignTransactionResponse, error)
 	BuildAndSignBatchTransaction(ctx context.Context, req *wallet.BuildAndSignBatchTransactionRequest) (*wallet.BuildAndSignBatchTransactionResponse, error)
+
+	SignPersonalMessage(ctx context.Context, req *wallet.SignPersonalMessageRequest) (*wallet.SignPersonalMessageResponse, error)
+	SignTypedData(ctx context.Context, req *wallet.SignTypedDataRequest) (*wallet.SignTypedDataResponse, error)
+	BuildAndSignPermit(ctx context.Context, req *wallet.BuildAndSignPermitRequest) (*wallet.BuildAndSignPermitResponse, error)
+	BuildAndSignUserOperation(ctx context.Context, req *wallet.BuildAndSignUserOperationRequest) (*wallet.BuildAndSignUserOperationResponse, error)
+	BuildAndSignSafeTransaction(ctx context.Context, req *wallet.BuildAndSignSafeTransactionRequest) (*wallet.BuildAndSignSafeTransactionResponse, error)
+
+	SignPsbt(ctx context.Context, req *wallet.SignPsbtRequest) (*wallet.SignPsbtResponse, error)
+	CreateMultisigAddress(ctx context.Context, req *wallet.CreateMultisigAddressRequest) (*wallet.CreateMultisigAddressResponse, error)
+	CreateMusig2Address(ctx context.Context, req *wallet.CreateMusig2AddressRequest) (*wallet.CreateMusig2AddressResponse, error)
+	Musig2Nonce(ctx context.Context, req *wallet.Musig2NonceRequest) (*wallet.Musig2NonceResponse, error)
+	Musig2PartialSign(ctx context.Context, req *wallet.Musig2PartialSignRequest) (*wallet.Musig2PartialSignResponse, error)
+	CreateTaprootAddress(ctx context.Context, req *wallet.CreateTaprootAddressRequest) (*wallet.CreateTaprootAddressResponse, error)
 }
@@ -0,0 +1,401 @@
+package ethereum
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const erc20TransferSignature = "transfer(address,uint256)"
+
+var bigIntType = reflect.TypeOf(new(big.Int))
+
+// DecodedCall is the contract call a transaction makes, echoed back so the
+// caller can confirm what was signed.
+type DecodedCall struct {
+	Method    string       `json:"method"`
+	Signature string       `json:"signature"`
+	Selector  string       `json:"selector"`
+	Args      []DecodedArg `json:"args"`
+}
+
+type DecodedArg struct {
+	Name  string      `json:"name,omitempty"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// parseAbiMethod returns the method described by a function signature such as
+// approve(address,uint256), or by a JSON ABI fragment or ABI array. The method
+// name picks the function when the ABI holds more than one.
+func parseAbiMethod(signature string, abiJson json.RawMessage, name string) (*abi.Method, error) {
+	if signature != "" && len(abiJson) != 0 {
+		return nil, errors.New("function and abi are exclusive")
+	}
+	if signature != "" {
+		fragment, err := signatureToFragment(signature)
+		if err != nil {
+			return nil, err
+		}
+		abiJson = fragment
+	}
+	abiJson = bytes.TrimSpace(abiJson)
+	if len(abiJson) > 0 && abiJson[0] == '{' {
+		abiJson = append(append([]byte{'['}, abiJson...), ']')
+	}
+	parsed, err := abi.JSON(bytes.NewReader(abiJson))
+	if err != nil {
+		return nil, fmt.Errorf("parse abi fail: %w", err)
+	}
+	if name != "" {
+		method, ok := parsed.Methods[name]
+		if !ok {
+			return nil, fmt.Errorf("method not found in abi: %s", name)
+		}
+		return &method, nil
+	}
+	if len(parsed.Methods) != 1 {
+		return nil, errors.New("abi holds several methods, set method")
+	}
+	for _, method := range parsed.Methods {
+		return &method, nil
+	}
+	return nil, errors.New("unreachable")
+}
+
+// signatureToFragment turns name(type1,type2) into a JSON ABI fragment, tuple
+// parameters need a full ABI fragment.
+func signatureToFragment(signature string) (json.RawMessage, error) {
+	open := strings.Index(signature, "(")
+	if open <= 0 || !strings.HasSuffix(signature, ")") {
+		return nil, fmt.Errorf("invalid function signature: %s", signature)
+	}
+	params := signature[open+1 : len(signature)-1]
+	if strings.ContainsAny(params, "()") {
+		return nil, errors.New("tuple parameters need an abi fragment")
+	}
+	type input struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	}
+	inputs := []input{}
+	if strings.TrimSpace(params) != "" {
+		for _, param := range strings.Split(params, ",") {
+			fields := strings.Fields(param)
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("invalid function signature: %s", signature)
+			}
+			in := input{Type: fields[0]}
+			if len(fields) > 1 {
+				in.Name = fields[len(fields)-1]
+			}
+			inputs = append(inputs, in)
+		}
+	}
+	return json.Marshal(map[string]interface{}{
+		"type":            "function",
+		"name":            strings.TrimSpace(signature[:open]),
+		"inputs":          inputs,
+		"outputs":         []input{},
+		"stateMutability": "nonpayable",
+	})
+}
+
+// encodeAbiCall ABI encodes the JSON array args as the calldata of method.
+func encodeAbiCall(method *abi.Method, args json.RawMessage) ([]byte, error) {
+	var rawArgs []json.RawMessage
+	if len(args) != 0 {
+		if err := json.Unmarshal(args, &rawArgs); err != nil {
+			return nil, fmt.Errorf("args must be a json array: %w", err)
+		}
+	}
+	if len(rawArgs) != len(method.Inputs) {
+		return nil, fmt.Errorf("%s takes %d args, got %d", method.Sig, len(method.Inputs), len(rawArgs))
+	}
+	values := make([]interface{}, len(rawArgs))
+	for i, input := range method.Inputs {
+		value, err := abiValue(input.Type, rawArgs[i])
+		if err != nil {
+			return nil, fmt.Errorf("arg %d (%s): %w", i, input.Type.String(), err)
+		}
+		values[i] = value.Interface()
+	}
+	packed, err := method.Inputs.Pack(values...)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, method.ID...), packed...), nil
+}
+
+// decodeAbiCall decodes calldata against method, the selector must match.
+func decodeAbiCall(method *abi.Method, data []byte) (*DecodedCall, error) {
+	if len(data) < 4 || !bytes.Equal(data[:4], method.ID) {
+		return nil, fmt.Errorf("calldata selector does not match %s", method.Sig)
+	}
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("decode calldata fail: %w", err)
+	}
+	call := &DecodedCall{
+		Method:    method.RawName,
+		Signature: method.Sig,
+		Selector:  hexutil.Encode(method.ID),
+		Args:      make([]DecodedArg, len(values)),
+	}
+	for i, input := range method.Inputs {
+		call.Args[i] = DecodedArg{
+			Name:  input.Name,
+			Type:  input.Type.String(),
+			Value: formatAbiValue(input.Type, reflect.ValueOf(values[i])),
+		}
+	}
+	return call, nil
+}
+
+// buildCallData returns the hex calldata, decoded against the function or abi
+// when one is given, or the calldata ABI encoded from the JSON args.
+func buildCallData(hexData string, signature string, abiJson json.RawMessage, name string, args json.RawMessage) ([]byte, *DecodedCall, error) {
+	data, err := hexutil.Decode(hexData)
+	if hexData != "" && err != nil {
+		return nil, nil, fmt.Errorf("invalid data: %w", err)
+	}
+	if signature == "" && len(abiJson) == 0 {
+		if len(args) != 0 {
+			return nil, nil, errors.New("args without function or abi")
+		}
+		return data, nil, nil
+	}
+	method, err := parseAbiMethod(signature, abiJson, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(data) != 0 {
+		if len(args) != 0 {
+			return nil, nil, errors.New("data and args are exclusive")
+		}
+	} else if data, err = encodeAbiCall(method, args); err != nil {
+		return nil, nil, err
+	}
+	decodedCall, err := decodeAbiCall(method, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, decodedCall, nil
+}
+
+func decodeSignatureCall(signature string, data []byte) (*DecodedCall, error) {
+	method, err := parseAbiMethod(signature, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return decodeAbiCall(method, data)
+}
+
+// abiValue converts a JSON argument into the Go value abi.Pack expects for t.
+// Integers are decimal or 0x hex strings or JSON numbers, bytes are hex.
+func abiValue(t abi.Type, raw json.RawMessage) (reflect.Value, error) {
+	switch t.T {
+	case abi.AddressTy:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil || !common.IsHexAddress(s) {
+			return reflect.Value{}, fmt.Errorf("invalid address: %s", raw)
+		}
+		return reflect.ValueOf(common.HexToAddress(s)), nil
+	case abi.IntTy, abi.UintTy:
+		n, err := parseAbiInt(raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if !fitsAbiInt(t, n) {
+			return reflect.Value{}, fmt.Errorf("%s out of range for %s", n, t.String())
+		}
+		goType := t.GetType()
+		if goType == bigIntType {
+			return reflect.ValueOf(n), nil
+		}
+		v := reflect.New(goType).Elem()
+		if t.T == abi.IntTy {
+			v.SetInt(n.Int64())
+		} else {
+			v.SetUint(n.Uint64())
+		}
+		return v, nil
+	case abi.BoolTy:
+		var b bool
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid bool: %s", raw)
+		}
+		return reflect.ValueOf(b), nil
+	case abi.StringTy:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid string: %s", raw)
+		}
+		return reflect.ValueOf(s), nil
+	case abi.BytesTy:
+		b, err := parseAbiBytes(raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(b), nil
+	case abi.FixedBytesTy:
+		b, err := parseAbiBytes(raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if len(b) != t.Size {
+			return reflect.Value{}, fmt.Errorf("want %d bytes, got %d", t.Size, len(b))
+		}
+		v := reflect.New(t.GetType()).Elem()
+		reflect.Copy(v, reflect.ValueOf(b))
+		return v, nil
+	case abi.SliceTy, abi.ArrayTy:
+		var elems []json.RawMessage
+		if err := json.Unmarshal(raw, &elems); err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid array: %s", raw)
+		}
+		var v reflect.Value
+		if t.T == abi.SliceTy {
+			v = reflect.MakeSlice(t.GetType(), len(elems), len(elems))
+		} else {
+			if len(elems) != t.Size {
+				return reflect.Value{}, fmt.Errorf("want %d elements, got %d", t.Size, len(elems))
+			}
+			v = reflect.New(t.GetType()).Elem()
+		}
+		for i, elem := range elems {
+			ev, err := abiValue(*t.Elem, elem)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("[%d]: %w", i, err)
+			}
+			v.Index(i).Set(ev)
+		}
+		return v, nil
+	case abi.TupleTy:
+		elems, err := tupleElems(t, raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v := reflect.New(t.TupleType).Elem()
+		for i, elemType := range t.TupleElems {
+			ev, err := abiValue(*elemType, elems[i])
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("%s: %w", t.TupleRawNames[i], err)
+			}
+			v.Field(i).Set(ev)
+		}
+		return v, nil
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported abi type: %s", t.String())
+	}
+}
+
+// tupleElems accepts a tuple as a positional JSON array or as an object keyed
+// by the component names.
+func tupleElems(t abi.Type, raw json.RawMessage) ([]json.RawMessage, error) {
+	var elems []json.RawMessage
+	if err := json.Unmarshal(raw, &elems); err == nil {
+		if len(elems) != len(t.TupleElems) {
+			return nil, fmt.Errorf("want %d tuple fields, got %d", len(t.TupleElems), len(elems))
+		}
+		return elems, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("invalid tuple: %s", raw)
+	}
+	elems = make([]json.RawMessage, len(t.TupleRawNames))
+	for i, name := range t.TupleRawNames {
+		field, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("missing tuple field: %s", name)
+		}
+		elems[i] = field
+	}
+	return elems, nil
+}
+
+func parseAbiInt(raw json.RawMessage) (*big.Int, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		var num json.Number
+		if err := json.Unmarshal(raw, &num); err != nil {
+			return nil, fmt.Errorf("invalid integer: %s", raw)
+		}
+		s = num.String()
+	}
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer: %s", raw)
+	}
+	return n, nil
+}
+
+func fitsAbiInt(t abi.Type, n *big.Int) bool {
+	if t.T == abi.UintTy {
+		return n.Sign() >= 0 && n.BitLen() <= t.Size
+	}
+	if n.Sign() >= 0 {
+		return n.BitLen() < t.Size
+	}
+	return new(big.Int).Not(n).BitLen() < t.Size
+}
+
+func parseAbiBytes(raw json.RawMessage) ([]byte, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("invalid bytes: %s", raw)
+	}
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex bytes: %s", s)
+	}
+	return b, nil
+}
+
+// formatAbiValue renders a decoded value for JSON, integers as decimal strings
+// and bytes as 0x hex.
+func formatAbiValue(t abi.Type, v reflect.Value) interface{} {
+	switch t.T {
+	case abi.AddressTy:
+		return v.Interface().(common.Address).Hex()
+	case abi.IntTy, abi.UintTy:
+		switch n := v.Interface().(type) {
+		case *big.Int:
+			return n.String()
+		}
+		if t.T == abi.IntTy {
+			return strconv.FormatInt(v.Int(), 10)
+		}
+		return strconv.FormatUint(v.Uint(), 10)
+	case abi.BytesTy:
+		return hexutil.Encode(v.Bytes())
+	case abi.FixedBytesTy, abi.HashTy:
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return hexutil.Encode(b)
+	case abi.SliceTy, abi.ArrayTy:
+		out := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out[i] = formatAbiValue(*t.Elem, v.Index(i))
+		}
+		return out
+	case abi.TupleTy:
+		out := make(map[string]interface{}, len(t.TupleElems))
+		for i, elemType := range t.TupleElems {
+			out[t.TupleRawNames[i]] = formatAbiValue(*elemType, v.Field(i))
+		}
+		return out
+	default:
+		return v.Interface()
+	}
+}
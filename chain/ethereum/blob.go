@@ -0,0 +1,132 @@
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/holiman/uint256"
+)
+
+// buildBlobTx builds an EIP-4844 transaction. With blobs the kzg commitments
+// and proofs are computed and the transaction is signed in network wrapper
+// form, otherwise blob_versioned_hashes must name the blobs.
+func buildBlobTx(dynamicFeeTx *Eip1559DynamicFeeTx, call *txCall, chainID *big.Int) (*types.BlobTx, error) {
+	if call.to == nil {
+		return nil, errors.New("blob transaction can not create a contract")
+	}
+	maxPriorityFeePerGas, err := parseUint256("max priority fee", dynamicFeeTx.MaxPriorityFeePerGas)
+	if err != nil {
+		return nil, err
+	}
+	maxFeePerGas, err := parseUint256("max fee", dynamicFeeTx.MaxFeePerGas)
+	if err != nil {
+		return nil, err
+	}
+	maxFeePerBlobGas, err := parseUint256("max fee per blob gas", dynamicFeeTx.MaxFeePerBlobGas)
+	if err != nil {
+		return nil, err
+	}
+	chainIDU256, overflow := uint256.FromBig(chainID)
+	if overflow || chainID.Sign() < 0 {
+		return nil, fmt.Errorf("invalid chain ID: %s", chainID)
+	}
+	value, overflow := uint256.FromBig(call.value)
+	if overflow || call.value.Sign() < 0 {
+		return nil, fmt.Errorf("invalid value: %s", call.value)
+	}
+
+	blobHashes := make([]common.Hash, len(dynamicFeeTx.BlobVersionedHashes))
+	for i, blobHash := range dynamicFeeTx.BlobVersionedHashes {
+		b, err := hexutil.Decode(blobHash)
+		if err != nil || len(b) != common.HashLength || !kzg4844.IsValidVersionedHash(b) {
+			return nil, fmt.Errorf("invalid blob versioned hash: %s", blobHash)
+		}
+		blobHashes[i] = common.BytesToHash(b)
+	}
+	var sidecar *types.BlobTxSidecar
+	if len(dynamicFeeTx.Blobs) != 0 {
+		sidecar, err = buildBlobSidecar(dynamicFeeTx.Blobs, dynamicFeeTx.BlobSidecarVersion)
+		if err != nil {
+			return nil, err
+		}
+		if len(blobHashes) == 0 {
+			blobHashes = sidecar.BlobHashes()
+		} else if err := sidecar.ValidateBlobCommitmentHashes(blobHashes); err != nil {
+			return nil, err
+		}
+	}
+	if len(blobHashes) == 0 {
+		return nil, errors.New("blob transaction without blobs")
+	}
+	return &types.BlobTx{
+		ChainID:    chainIDU256,
+		Nonce:      dynamicFeeTx.Nonce,
+		GasTipCap:  maxPriorityFeePerGas,
+		GasFeeCap:  maxFeePerGas,
+		Gas:        dynamicFeeTx.GasLimit,
+		To:         *call.to,
+		Value:      value,
+		Data:       call.data,
+		AccessList: dynamicFeeTx.AccessList,
+		BlobFeeCap: maxFeePerBlobGas,
+		BlobHashes: blobHashes,
+		Sidecar:    sidecar,
+	}, nil
+}
+
+// buildBlobSidecar computes the commitment and proofs of each hex blob, a
+// blob shorter than 128KiB is zero padded. Version 0 carries one blob proof
+// per blob, version 1 (Osaka) the cell proofs.
+func buildBlobSidecar(hexBlobs []string, version byte) (*types.BlobTxSidecar, error) {
+	if version != types.BlobSidecarVersion0 && version != types.BlobSidecarVersion1 {
+		return nil, fmt.Errorf("unsupported blob sidecar version: %d", version)
+	}
+	blobs := make([]kzg4844.Blob, len(hexBlobs))
+	commitments := make([]kzg4844.Commitment, len(hexBlobs))
+	var proofs []kzg4844.Proof
+	for i, hexBlob := range hexBlobs {
+		b, err := hexutil.Decode(hexBlob)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blob %d: %w", i, err)
+		}
+		if len(b) > len(blobs[i]) {
+			return nil, fmt.Errorf("blob %d is larger than %d bytes", i, len(blobs[i]))
+		}
+		copy(blobs[i][:], b)
+		commitments[i], err = kzg4844.BlobToCommitment(&blobs[i])
+		if err != nil {
+			return nil, fmt.Errorf("compute commitment of blob %d fail: %w", i, err)
+		}
+		if version == types.BlobSidecarVersion1 {
+			cellProofs, err := kzg4844.ComputeCellProofs(&blobs[i])
+			if err != nil {
+				return nil, fmt.Errorf("compute cell proofs of blob %d fail: %w", i, err)
+			}
+			proofs = append(proofs, cellProofs...)
+			continue
+		}
+		proof, err := kzg4844.ComputeBlobProof(&blobs[i], commitments[i])
+		if err != nil {
+			return nil, fmt.Errorf("compute proof of blob %d fail: %w", i, err)
+		}
+		proofs = append(proofs, proof)
+	}
+	return types.NewBlobTxSidecar(version, blobs, commitments, proofs), nil
+}
+
+func parseUint256(name string, value string) (*uint256.Int, error) {
+	n, err := parseBigInt(name, value)
+	if err != nil {
+		return nil, err
+	}
+	u, overflow := uint256.FromBig(n)
+	if overflow || n.Sign() < 0 {
+		return nil, fmt.Errorf("invalid %s: %s", name, value)
+	}
+	return u, nil
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/DQYXACML/wallet-sign/chain"
+	"github.com/DQYXACML/wallet-sign/config"
 	"github.com/DQYXACML/wallet-sign/leveldb"
 	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
 	"github.com/DQYXACML/wallet-sign/ssm"
@@ -20,9 +21,16 @@ import (
 
 const ChainName = "Ethereum"
 
+// HDPathTemplate is the BIP44 derivation path of hd mode keys
+const HDPathTemplate = "m/44'/60'/0'/0/%d"
+
 type ChainAdaptor struct {
-	db     *leveldb.Keys
-	signer ssm.Signer
+	db                *leveldb.Keys
+	signer            ssm.Signer
+	hdEnable          bool
+	batchWorkers      int
+	permitPolicies    *permitPolicies
+	allowAnyChainAuth bool
 }
 
 func (c *ChainAdaptor) SignTransactionMessage(ctx context.Context, req *wallet.SignTransactionMessageRequest) (*wallet.SignTransactionMessageResponse, error) {
@@ -45,6 +53,12 @@ func (c *ChainAdaptor) SignTransactionMessage(ctx context.Context, req *wallet.S
 }
 
 func (c *ChainAdaptor) GetChainSignMethod(ctx context.Context, req *wallet.GetChainSignMethodRequest) (*wallet.GetChainSignMethodResponse, error) {
+	if !isSupportedAssetType(req.AssetType) {
+		return &wallet.GetChainSignMethodResponse{
+			Code: wallet.ReturnCode_ERROR,
+			Msg:  "unsupported asset type: " + req.AssetType,
+		}, nil
+	}
 	return &wallet.GetChainSignMethodResponse{
 		Code:       wallet.ReturnCode_SUCCESS,
 		Msg:        "get sign method success",
@@ -56,6 +70,8 @@ func (c *ChainAdaptor) GetChainSchema(ctx context.Context, req *wallet.GetChainS
 	es := EthereumSchema{
 		RequestId: "0",
 		DynamicFeeTx: Eip1559DynamicFeeTx{
+			TxType:               TxTypeDynamicFee,
+			AssetType:            AssetTypeNative,
 			ChainId:              "",
 			Nonce:                0,
 			FromAddress:          common.Address{}.String(),
@@ -66,17 +82,52 @@ func (c *ChainAdaptor) GetChainSchema(ctx context.Context, req *wallet.GetChainS
 			MaxPriorityFeePerGas: "0",
 			Amount:               "0",
 			ContractAddress:      "",
+			Value:                "0",
+			Data:                 "0x",
 		},
 		ClassicFeeTx: LegacyFeeTx{
+			TxType:          TxTypeLegacy,
 			ChainId:         "0",
 			Nonce:           0,
 			FromAddress:     common.Address{}.String(),
 			ToAddress:       common.Address{}.String(),
 			GasLimit:        0,
-			GasPrice:        0,
+			GasPrice:        "0",
 			Amount:          "0",
 			ContractAddress: "",
 		},
+		SetCodeTx: Eip1559DynamicFeeTx{
+			TxType:               TxTypeSetCode,
+			AssetType:            AssetTypeNative,
+			ChainId:              "0",
+			Nonce:                0,
+			FromAddress:          common.Address{}.String(),
+			ToAddress:            common.Address{}.String(),
+			GasLimit:             0,
+			MaxFeePerGas:         "0",
+			MaxPriorityFeePerGas: "0",
+			Amount:               "0",
+			AuthorizationList: []Authorization{{
+				ChainId: "0",
+				Address: common.Address{}.String(),
+				Nonce:   0,
+			}},
+		},
+		BlobTx: Eip1559DynamicFeeTx{
+			TxType:               TxTypeBlob,
+			AssetType:            AssetTypeNative,
+			ChainId:              "0",
+			Nonce:                0,
+			FromAddress:          common.Address{}.String(),
+			ToAddress:            common.Address{}.String(),
+			GasLimit:             0,
+			MaxFeePerGas:         "0",
+			MaxPriorityFeePerGas: "0",
+			Amount:               "0",
+			MaxFeePerBlobGas:     "0",
+			BlobVersionedHashes:  []string{common.Hash{}.String()},
+			Blobs:                []string{"0x"},
+		},
 	}
 	b, err := json.Marshal(es)
 	if err != nil {
@@ -90,34 +141,30 @@ func (c *ChainAdaptor) GetChainSchema(ctx context.Context, req *wallet.GetChainS
 }
 
 func (c *ChainAdaptor) CreateKeyPairsExportPublicKeyList(ctx context.Context, req *wallet.CreateKeyPairAndExportPublicKeyRequest) (*wallet.CreateKeyPairAndExportPublicKeyResponse, error) {
-	signer := &ssm.ECDSASigner{}
 	resp := &wallet.CreateKeyPairAndExportPublicKeyResponse{
 		Code: wallet.ReturnCode_ERROR,
 	}
-	if req.KeyNum > 10000 {
-		resp.Message = "Number must be less than 100000"
+	if req.KeyNum > chain.MaxKeyNum {
+		resp.Message = fmt.Sprintf("Number must be less than %d", chain.MaxKeyNum)
 		return resp, nil
 	}
 
 	var keyList []leveldb.Key
 	var retKeyList []*wallet.ExportPublicKey
 
-	for i := 0; i < int(req.KeyNum); i++ {
-		priKeyStr, pubKeyStr, compressPubkeyStr, err := signer.CreateKeyPair()
-		if err != nil {
-			resp.Message = "create key pairs fail"
-			return resp, nil
-		}
-		keyItem := leveldb.Key{
-			PrivateKey: priKeyStr,
-			PubKey:     pubKeyStr,
-		}
+	keyPairs, err := chain.CreateKeyPairs(c.db, c.signer, c.hdPathTemplate(), req.KeyNum)
+	if err != nil {
+		log.Error("create key pairs fail", "err", err)
+		resp.Message = "create key pairs fail"
+		return resp, nil
+	}
+	for _, keyPair := range keyPairs {
 		pukItem := &wallet.ExportPublicKey{
-			CompressPublicKey: compressPubkeyStr,
-			PublicKey:         pubKeyStr,
+			CompressPublicKey: keyPair.CompressPubKey,
+			PublicKey:         keyPair.PubKey,
 		}
 		retKeyList = append(retKeyList, pukItem)
-		keyList = append(keyList, keyItem)
+		keyList = append(keyList, keyPair.Key)
 	}
 	isOk := c.db.StoreKeys(keyList)
 	if !isOk {
@@ -131,35 +178,31 @@ func (c *ChainAdaptor) CreateKeyPairsExportPublicKeyList(ctx context.Context, re
 }
 
 func (c *ChainAdaptor) CreateKeyPairsWithAddresses(ctx context.Context, req *wallet.CreateKeyPairsWithAddressesRequest) (*wallet.CreateKeyPairsWithAddressesResponse, error) {
-	signer := &ssm.ECDSASigner{}
 	resp := &wallet.CreateKeyPairsWithAddressesResponse{
 		Code: wallet.ReturnCode_ERROR,
 	}
-	if req.KeyNum > 10000 {
-		resp.Message = "Number must be less than 100000"
+	if req.KeyNum > chain.MaxKeyNum {
+		resp.Message = fmt.Sprintf("Number must be less than %d", chain.MaxKeyNum)
 		return resp, nil
 	}
 	var keyList []leveldb.Key
 	var retKeyWithAddressList []*wallet.ExportPublicKeyWithAddress
 
-	for i := 0; i < int(req.KeyNum); i++ {
-		priKeyStr, pubKeyStr, compressPubkeyStr, err := signer.CreateKeyPair()
-		if err != nil {
-			resp.Message = "create key pairs fail"
-			return resp, nil
-		}
-		keyItem := leveldb.Key{
-			PrivateKey: priKeyStr,
-			PubKey:     pubKeyStr,
-		}
-		publicKeyBytes, err := hex.DecodeString(pubKeyStr)
+	keyPairs, err := chain.CreateKeyPairs(c.db, c.signer, c.hdPathTemplate(), req.KeyNum)
+	if err != nil {
+		log.Error("create key pairs fail", "err", err)
+		resp.Message = "create key pairs fail"
+		return resp, nil
+	}
+	for _, keyPair := range keyPairs {
+		publicKeyBytes, _ := hex.DecodeString(keyPair.PubKey)
 		pukAddressItem := &wallet.ExportPublicKeyWithAddress{
-			PublicKey:         pubKeyStr,
-			CompressPublicKey: compressPubkeyStr,
+			PublicKey:         keyPair.PubKey,
+			CompressPublicKey: keyPair.CompressPubKey,
 			Address:           hex.EncodeToString(crypto.Keccak256(publicKeyBytes[1:])[12:]),
 		}
 		retKeyWithAddressList = append(retKeyWithAddressList, pukAddressItem)
-		keyList = append(keyList, keyItem)
+		keyList = append(keyList, keyPair.Key)
 	}
 	isOk := c.db.StoreKeys(keyList)
 	if !isOk {
@@ -173,15 +216,29 @@ func (c *ChainAdaptor) CreateKeyPairsWithAddresses(ctx context.Context, req *wal
 }
 
 func (c *ChainAdaptor) BuildAndSignTransaction(ctx context.Context, req *wallet.BuildAndSignTransactionRequest) (*wallet.BuildAndSignTransactionResponse, error) {
-	signer := &ssm.ECDSASigner{}
 	resp := &wallet.BuildAndSignTransactionResponse{
 		Code: wallet.ReturnCode_ERROR,
 	}
-	dFeeTx, _, err := c.buildDynamicFeeTx(req.TxBase64Body)
+	tx, err := c.buildTx(req.TxBase64Body, req.PublicKey)
 	if err != nil {
-		return nil, err
+		log.Error("build transaction fail", "err", err)
+		resp.Message = "build transaction fail: " + err.Error()
+		return resp, nil
+	}
+	if tx.authorization != nil {
+		signedAuth, err := json.Marshal(tx.authorization)
+		if err != nil {
+			log.Error("marshal authorization fail", "err", err)
+			resp.Message = "marshal authorization fail"
+			return resp, nil
+		}
+		resp.Code = wallet.ReturnCode_SUCCESS
+		resp.Message = "sign authorization success"
+		resp.SignedTx = string(signedAuth)
+		resp.TxMessageHash = tx.authorization.SigHash().String()
+		return resp, nil
 	}
-	rawTx, err := CreateEip1559UnSignTx(dFeeTx, dFeeTx.ChainID)
+	rawTx, err := CreateUnSignTx(tx.txData, tx.chainID)
 	if err != nil {
 		log.Error("create un sign tx fail", "err", err)
 		resp.Message = "get un sign tx fail"
@@ -194,7 +251,7 @@ func (c *ChainAdaptor) BuildAndSignTransaction(ctx context.Context, req *wallet.
 		return resp, nil
 	}
 
-	signature, err := signer.SignMessage(privKey, rawTx)
+	signature, err := c.signer.SignMessage(privKey, rawTx)
 	if err != nil {
 		log.Error("sign transaction fail", "err", err)
 		resp.Message = "sign transaction fail"
@@ -208,15 +265,15 @@ func (c *ChainAdaptor) BuildAndSignTransaction(ctx context.Context, req *wallet.
 		return resp, nil
 	}
 
-	eip1559Signer, signedTx, signAndHandledTx, txHash, err := CreateEip1559SignedTx(dFeeTx, inputSignatureByteList, dFeeTx.ChainID)
+	txSigner, signedTx, signAndHandledTx, txHash, err := CreateSignedTx(tx.txData, inputSignatureByteList, tx.chainID)
 	if err != nil {
 		log.Error("create signed tx fail", "err", err)
 		resp.Message = "create signed tx fail"
 		return resp, nil
 	}
 	log.Info("sign transaction success",
-		"eip1559Signer", eip1559Signer,
-		"signedTx", signedTx,
+		"txSigner", txSigner,
+		"txType", signedTx.Type(),
 		"signAndHandledTx", signAndHandledTx,
 		"txHash", txHash,
 	)
@@ -225,73 +282,247 @@ func (c *ChainAdaptor) BuildAndSignTransaction(ctx context.Context, req *wallet.
 	resp.SignedTx = signAndHandledTx
 	resp.TxHash = txHash
 	resp.TxMessageHash = rawTx
+	if tx.decodedCall != nil {
+		decodedCall, err := json.Marshal(tx.decodedCall)
+		if err != nil {
+			log.Error("marshal decoded call fail", "err", err)
+		}
+		resp.DecodedCall = string(decodedCall)
+	}
 	return resp, nil
 }
 
 func (c *ChainAdaptor) BuildAndSignBatchTransaction(ctx context.Context, req *wallet.BuildAndSignBatchTransactionRequest) (*wallet.BuildAndSignBatchTransactionResponse, error) {
-	//TODO implement me
-	panic("implement me")
+	return chain.BuildAndSignBatch(ctx, req, c.batchWorkers, c.BuildAndSignTransaction), nil
+}
+
+func (c *ChainAdaptor) SignPsbt(ctx context.Context, req *wallet.SignPsbtRequest) (*wallet.SignPsbtResponse, error) {
+	return &wallet.SignPsbtResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
+}
+
+func (c *ChainAdaptor) CreateMultisigAddress(ctx context.Context, req *wallet.CreateMultisigAddressRequest) (*wallet.CreateMultisigAddressResponse, error) {
+	return &wallet.CreateMultisigAddressResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
+}
+
+func (c *ChainAdaptor) CreateMusig2Address(ctx context.Context, req *wallet.CreateMusig2AddressRequest) (*wallet.CreateMusig2AddressResponse, error) {
+	return &wallet.CreateMusig2AddressResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
+}
+
+func (c *ChainAdaptor) Musig2Nonce(ctx context.Context, req *wallet.Musig2NonceRequest) (*wallet.Musig2NonceResponse, error) {
+	return &wallet.Musig2NonceResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
+}
+
+func (c *ChainAdaptor) Musig2PartialSign(ctx context.Context, req *wallet.Musig2PartialSignRequest) (*wallet.Musig2PartialSignResponse, error) {
+	return &wallet.Musig2PartialSignResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
 }
 
-func (c *ChainAdaptor) buildDynamicFeeTx(base64Tx string) (*types.DynamicFeeTx, *Eip1559DynamicFeeTx, error) {
+func (c *ChainAdaptor) CreateTaprootAddress(ctx context.Context, req *wallet.CreateTaprootAddressRequest) (*wallet.CreateTaprootAddressResponse, error) {
+	return &wallet.CreateTaprootAddressResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
+}
+
+// unsignedTx is a built transaction waiting for its signature, or the signed
+// authorization of an authorization tx_type.
+type unsignedTx struct {
+	txData        types.TxData
+	chainID       *big.Int
+	decodedCall   *DecodedCall
+	authorization *types.SetCodeAuthorization
+}
+
+// txCall is the destination, value and calldata of a transaction, a nil
+// destination creates a contract.
+type txCall struct {
+	to          *common.Address
+	value       *big.Int
+	data        []byte
+	decodedCall *DecodedCall
+}
+
+// buildTx builds the unsigned transaction of the type named by tx_type, an
+// empty tx_type is an EIP-1559 dynamic fee transaction.
+func (c *ChainAdaptor) buildTx(base64Tx string, publicKey string) (*unsignedTx, error) {
 	txReqJsonByte, err := base64.StdEncoding.DecodeString(base64Tx)
 	if err != nil {
 		log.Error("decode string fail", "err", err)
-		return nil, nil, err
+		return nil, err
 	}
 	var dynamicFeeTx Eip1559DynamicFeeTx
 	if err := json.Unmarshal(txReqJsonByte, &dynamicFeeTx); err != nil {
 		log.Error("parse json fail", "err", err)
-		return nil, nil, err
+		return nil, err
 	}
-	chainID := new(big.Int)
-	maxPriorityFeePerGas := new(big.Int)
-	maxFeePerGas := new(big.Int)
-	amount := new(big.Int)
-
-	if _, ok := chainID.SetString(dynamicFeeTx.ChainId, 10); !ok {
-		return nil, nil, fmt.Errorf("invalid chain ID: %s", dynamicFeeTx.ChainId)
+	if dynamicFeeTx.TxType == TxTypeAuthorization {
+		if len(dynamicFeeTx.AuthorizationList) != 1 {
+			return nil, errors.New("authorization tx type signs exactly one authorization")
+		}
+		authList, err := c.signAuthorizations(dynamicFeeTx.AuthorizationList, publicKey)
+		if err != nil {
+			return nil, err
+		}
+		return &unsignedTx{authorization: &authList[0]}, nil
 	}
-	if _, ok := maxPriorityFeePerGas.SetString(dynamicFeeTx.MaxPriorityFeePerGas, 10); !ok {
-		return nil, nil, fmt.Errorf("invalid max priority fee: %s", dynamicFeeTx.MaxPriorityFeePerGas)
+	chainID, err := parseBigInt("chain ID", dynamicFeeTx.ChainId)
+	if err != nil {
+		return nil, err
 	}
-	if _, ok := maxFeePerGas.SetString(dynamicFeeTx.MaxFeePerGas, 10); !ok {
-		return nil, nil, fmt.Errorf("invalid max fee: %s", dynamicFeeTx.MaxFeePerGas)
+	call, err := buildTxCall(&dynamicFeeTx)
+	if err != nil {
+		return nil, err
 	}
-	if _, ok := amount.SetString(dynamicFeeTx.Amount, 10); !ok {
-		return nil, nil, fmt.Errorf("invalid amount: %s", dynamicFeeTx.Amount)
+	tx := &unsignedTx{chainID: chainID, decodedCall: call.decodedCall}
+
+	switch dynamicFeeTx.TxType {
+	case TxTypeLegacy:
+		gasPrice, err := parseBigInt("gas price", dynamicFeeTx.GasPrice)
+		if err != nil {
+			return nil, err
+		}
+		tx.txData = &types.LegacyTx{
+			Nonce:    dynamicFeeTx.Nonce,
+			GasPrice: gasPrice,
+			Gas:      dynamicFeeTx.GasLimit,
+			To:       call.to,
+			Value:    call.value,
+			Data:     call.data,
+		}
+	case TxTypeAccessList:
+		gasPrice, err := parseBigInt("gas price", dynamicFeeTx.GasPrice)
+		if err != nil {
+			return nil, err
+		}
+		tx.txData = &types.AccessListTx{
+			ChainID:    chainID,
+			Nonce:      dynamicFeeTx.Nonce,
+			GasPrice:   gasPrice,
+			Gas:        dynamicFeeTx.GasLimit,
+			To:         call.to,
+			Value:      call.value,
+			Data:       call.data,
+			AccessList: dynamicFeeTx.AccessList,
+		}
+	case TxTypeDynamicFee, "":
+		maxPriorityFeePerGas, err := parseBigInt("max priority fee", dynamicFeeTx.MaxPriorityFeePerGas)
+		if err != nil {
+			return nil, err
+		}
+		maxFeePerGas, err := parseBigInt("max fee", dynamicFeeTx.MaxFeePerGas)
+		if err != nil {
+			return nil, err
+		}
+		tx.txData = &types.DynamicFeeTx{
+			ChainID:    chainID,
+			Nonce:      dynamicFeeTx.Nonce,
+			GasTipCap:  maxPriorityFeePerGas,
+			GasFeeCap:  maxFeePerGas,
+			Gas:        dynamicFeeTx.GasLimit,
+			To:         call.to,
+			Value:      call.value,
+			Data:       call.data,
+			AccessList: dynamicFeeTx.AccessList,
+		}
+	case TxTypeSetCode:
+		authList, err := c.signAuthorizations(dynamicFeeTx.AuthorizationList, publicKey)
+		if err != nil {
+			return nil, err
+		}
+		if tx.txData, err = buildSetCodeTx(&dynamicFeeTx, call, chainID, authList); err != nil {
+			return nil, err
+		}
+	case TxTypeBlob:
+		if tx.txData, err = buildBlobTx(&dynamicFeeTx, call, chainID); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported tx type: %s", dynamicFeeTx.TxType)
 	}
+	return tx, nil
+}
 
-	toAddress := common.HexToAddress(dynamicFeeTx.ToAddress)
-	var finalToAddress common.Address
-	var finalAmount *big.Int
-	var buildData []byte
-	log.Info("contract address check",
-		"contractAddress", dynamicFeeTx.ContractAddress,
-		"isEthTransfer", isEthTransfer(&dynamicFeeTx),
-	)
-	if isEthTransfer(&dynamicFeeTx) {
-		finalToAddress = toAddress
-		finalAmount = amount
-	} else {
+// buildTxCall returns the destination, value and calldata of the asset_type.
+func buildTxCall(dynamicFeeTx *Eip1559DynamicFeeTx) (*txCall, error) {
+	switch dynamicFeeTx.AssetType {
+	case AssetTypeContractCall:
+		value := big.NewInt(0)
+		if dynamicFeeTx.Value != "" {
+			var err error
+			if value, err = parseBigInt("value", dynamicFeeTx.Value); err != nil {
+				return nil, err
+			}
+		}
+		data, decodedCall, err := buildContractCallData(dynamicFeeTx)
+		if err != nil {
+			return nil, err
+		}
+		if dynamicFeeTx.ToAddress == "" {
+			if len(data) == 0 {
+				return nil, errors.New("contract creation without init code")
+			}
+			return &txCall{value: value, data: data}, nil
+		}
+		if !common.IsHexAddress(dynamicFeeTx.ToAddress) {
+			return nil, fmt.Errorf("invalid to address: %s", dynamicFeeTx.ToAddress)
+		}
+		toAddress := common.HexToAddress(dynamicFeeTx.ToAddress)
+		return &txCall{to: &toAddress, value: value, data: data, decodedCall: decodedCall}, nil
+	case AssetTypeErc721, AssetTypeErc1155:
+		return buildNftTransfer(dynamicFeeTx)
+	case AssetTypeNative, AssetTypeErc20, "":
+		amount, err := parseBigInt("amount", dynamicFeeTx.Amount)
+		if err != nil {
+			return nil, err
+		}
+		toAddress := common.HexToAddress(dynamicFeeTx.ToAddress)
+		log.Info("contract address check",
+			"contractAddress", dynamicFeeTx.ContractAddress,
+			"isEthTransfer", isEthTransfer(dynamicFeeTx),
+		)
+		if isEthTransfer(dynamicFeeTx) {
+			return &txCall{to: &toAddress, value: amount}, nil
+		}
 		contractAddress := common.HexToAddress(dynamicFeeTx.ContractAddress)
-		buildData = BuildErc20Data(toAddress, amount)
-		finalToAddress = contractAddress
-		finalAmount = big.NewInt(0)
+		data := BuildErc20Data(toAddress, amount)
+		decodedCall, err := decodeSignatureCall(erc20TransferSignature, data)
+		if err != nil {
+			return nil, err
+		}
+		return &txCall{to: &contractAddress, value: big.NewInt(0), data: data, decodedCall: decodedCall}, nil
+	default:
+		return nil, fmt.Errorf("unsupported asset type: %s", dynamicFeeTx.AssetType)
 	}
+}
 
-	dFeeTx := &types.DynamicFeeTx{
-		ChainID:   chainID,
-		Nonce:     dynamicFeeTx.Nonce,
-		GasTipCap: maxPriorityFeePerGas,
-		GasFeeCap: maxFeePerGas,
-		Gas:       dynamicFeeTx.GasLimit,
-		To:        &finalToAddress,
-		Value:     finalAmount,
-		Data:      buildData,
+// buildContractCallData returns the calldata of a contract_call.
+func buildContractCallData(dynamicFeeTx *Eip1559DynamicFeeTx) ([]byte, *DecodedCall, error) {
+	if dynamicFeeTx.ToAddress == "" && (dynamicFeeTx.Function != "" || len(dynamicFeeTx.Abi) != 0) {
+		return nil, nil, errors.New("function or abi needs a to address")
 	}
+	return buildCallData(dynamicFeeTx.Data, dynamicFeeTx.Function, dynamicFeeTx.Abi, dynamicFeeTx.Method, dynamicFeeTx.Args)
+}
 
-	return dFeeTx, &dynamicFeeTx, nil
+func parseBigInt(name string, value string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s: %s", name, value)
+	}
+	return n, nil
 }
 
 func isEthTransfer(tx *Eip1559DynamicFeeTx) bool {
@@ -303,9 +534,30 @@ func isEthTransfer(tx *Eip1559DynamicFeeTx) bool {
 	return false
 }
 
-func NewChainAdaptor(db *leveldb.Keys) (chain.IChainAdaptor, error) {
+func (c *ChainAdaptor) hdPathTemplate() string {
+	if !c.hdEnable {
+		return ""
+	}
+	return HDPathTemplate
+}
+
+func NewChainAdaptor(conf *config.Config, db *leveldb.Keys) (chain.IChainAdaptor, error) {
+	signer, err := ssm.NewECDSASigner(conf)
+	if err != nil {
+		log.Error("new signer fail", "err", err)
+		return nil, err
+	}
+	policies, err := newPermitPolicies(conf.PermitPolicies)
+	if err != nil {
+		log.Error("load permit policies fail", "err", err)
+		return nil, err
+	}
 	return &ChainAdaptor{
-		db:     db,
-		signer: &ssm.ECDSASigner{},
+		db:                db,
+		signer:            signer,
+		hdEnable:          conf.HdEnable,
+		batchWorkers:      conf.BatchWorkers,
+		permitPolicies:    policies,
+		allowAnyChainAuth: conf.AllowAnyChainAuthorization,
 	}, nil
 }
@@ -0,0 +1,112 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// SignPersonalMessage signs an EIP-191 personal_sign message. Like wallets do,
+// a 0x prefixed hex message is signed as raw bytes and any other message as
+// utf-8 text.
+func (c *ChainAdaptor) SignPersonalMessage(ctx context.Context, req *wallet.SignPersonalMessageRequest) (*wallet.SignPersonalMessageResponse, error) {
+	resp := &wallet.SignPersonalMessageResponse{
+		Code: wallet.ReturnCode_ERROR,
+	}
+	message := []byte(req.Message)
+	if strings.HasPrefix(req.Message, "0x") {
+		if b, err := hexutil.Decode(req.Message); err == nil {
+			message = b
+		}
+	}
+	messageHash := accounts.TextHash(message)
+	signature, err := c.signDigest(req.PublicKey, messageHash)
+	if err != nil {
+		log.Error("sign personal message fail", "err", err)
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	log.Info("sign personal message success", "publicKey", req.PublicKey, "messageHash", hexutil.Encode(messageHash))
+	resp.Code = wallet.ReturnCode_SUCCESS
+	resp.Message = "sign personal message success"
+	resp.Signature = signature
+	resp.MessageHash = hexutil.Encode(messageHash)
+	return resp, nil
+}
+
+// SignTypedData signs EIP-712 typed data in the eth_signTypedData_v4 JSON
+// form and returns the domain and primary type for auditing.
+func (c *ChainAdaptor) SignTypedData(ctx context.Context, req *wallet.SignTypedDataRequest) (*wallet.SignTypedDataResponse, error) {
+	resp := &wallet.SignTypedDataResponse{
+		Code: wallet.ReturnCode_ERROR,
+	}
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal([]byte(req.TypedData), &typedData); err != nil {
+		log.Error("parse typed data fail", "err", err)
+		resp.Message = "parse typed data fail: " + err.Error()
+		return resp, nil
+	}
+	if c.permitPolicies != nil && isPermitPrimaryType(typedData.PrimaryType) {
+		resp.Message = "permits are policy checked, sign with buildAndSignPermit"
+		return resp, nil
+	}
+	messageHash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		log.Error("hash typed data fail", "err", err)
+		resp.Message = "hash typed data fail: " + err.Error()
+		return resp, nil
+	}
+	domain, err := json.Marshal(typedData.Domain.Map())
+	if err != nil {
+		log.Error("marshal domain fail", "err", err)
+		resp.Message = "marshal domain fail"
+		return resp, nil
+	}
+	signature, err := c.signDigest(req.PublicKey, messageHash)
+	if err != nil {
+		log.Error("sign typed data fail", "err", err)
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	log.Info("sign typed data success",
+		"publicKey", req.PublicKey,
+		"domain", string(domain),
+		"primaryType", typedData.PrimaryType,
+		"messageHash", hexutil.Encode(messageHash),
+	)
+	resp.Code = wallet.ReturnCode_SUCCESS
+	resp.Message = "sign typed data success"
+	resp.Signature = signature
+	resp.MessageHash = hexutil.Encode(messageHash)
+	resp.Domain = string(domain)
+	resp.PrimaryType = typedData.PrimaryType
+	return resp, nil
+}
+
+// signDigest signs a message digest and returns the 0x hex r || s || v
+// signature with v 27 or 28, as personal_sign and eth_signTypedData do.
+func (c *ChainAdaptor) signDigest(publicKey string, digest []byte) (string, error) {
+	privKey, isOk := c.db.GetPrivKey(publicKey)
+	if !isOk {
+		return "", errors.New("get private key by public key fail")
+	}
+	signature, err := c.signer.SignMessage(privKey, hexutil.Encode(digest))
+	if err != nil {
+		return "", errors.New("sign message fail")
+	}
+	sig, err := hex.DecodeString(signature)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return "", errors.New("decode signature failed")
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return hexutil.Encode(sig), nil
+}
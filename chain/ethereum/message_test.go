@@ -0,0 +1,79 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/DQYXACML/wallet-sign/config"
+	"github.com/DQYXACML/wallet-sign/leveldb"
+	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// cowPrivateKey is keccak256("cow"), the signer of the EIP-712 example
+const cowPrivateKey = "c85ef7d79691fe79573b1a7064c19c1a9819ebdbd1faaab1a8ec92344438aaf4"
+
+// newTestAdaptor returns an adaptor over a fresh key store holding the
+// private key, and the public key to sign with.
+func newTestAdaptor(t *testing.T, privateKey string) (*ChainAdaptor, string) {
+	t.Helper()
+	db, err := leveldb.NewKeyStore(t.TempDir(), "", "test passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := crypto.HexToECDSA(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey := hex.EncodeToString(crypto.FromECDSAPub(&key.PublicKey))
+	if !db.StoreKeys([]leveldb.Key{{PrivateKey: privateKey, PubKey: publicKey}}) {
+		t.Fatal("store key fail")
+	}
+	adaptor, err := NewChainAdaptor(&config.Config{}, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return adaptor.(*ChainAdaptor), publicKey
+}
+
+// TestSignTypedDataEIP712 checks the digest and signature of the Mail example
+// of EIP-712.
+func TestSignTypedDataEIP712(t *testing.T) {
+	tests := []struct {
+		name        string
+		typedData   string
+		messageHash string
+		signature   string
+		primaryType string
+	}{
+		{
+			name:        "ether mail",
+			typedData:   `{"types":{"EIP712Domain":[{"name":"name","type":"string"},{"name":"version","type":"string"},{"name":"chainId","type":"uint256"},{"name":"verifyingContract","type":"address"}],"Person":[{"name":"name","type":"string"},{"name":"wallet","type":"address"}],"Mail":[{"name":"from","type":"Person"},{"name":"to","type":"Person"},{"name":"contents","type":"string"}]},"primaryType":"Mail","domain":{"name":"Ether Mail","version":"1","chainId":1,"verifyingContract":"0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"},"message":{"from":{"name":"Cow","wallet":"0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},"to":{"name":"Bob","wallet":"0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},"contents":"Hello, Bob!"}}`,
+			messageHash: "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2",
+			signature:   "0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b915621c",
+			primaryType: "Mail",
+		},
+	}
+	c, publicKey := newTestAdaptor(t, cowPrivateKey)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := c.SignTypedData(context.Background(), &wallet.SignTypedDataRequest{
+				PublicKey: publicKey,
+				TypedData: tt.typedData,
+			})
+			if err != nil || resp.Code != wallet.ReturnCode_SUCCESS {
+				t.Fatalf("sign typed data fail: %v %s", err, resp.GetMessage())
+			}
+			if resp.MessageHash != tt.messageHash {
+				t.Fatalf("message hash %s, want %s", resp.MessageHash, tt.messageHash)
+			}
+			if resp.Signature != tt.signature {
+				t.Fatalf("signature %s, want %s", resp.Signature, tt.signature)
+			}
+			if resp.PrimaryType != tt.primaryType {
+				t.Fatalf("primary type %s, want %s", resp.PrimaryType, tt.primaryType)
+			}
+		})
+	}
+}
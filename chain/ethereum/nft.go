@@ -0,0 +1,105 @@
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	erc721TransferSignature       = "safeTransferFrom(address,address,uint256)"
+	erc1155TransferSignature      = "safeTransferFrom(address,address,uint256,uint256,bytes)"
+	erc1155BatchTransferSignature = "safeBatchTransferFrom(address,address,uint256[],uint256[],bytes)"
+)
+
+// buildNftTransfer builds the safeTransferFrom call of an erc721 or erc1155
+// asset_type from from_address to to_address. An ERC-1155 transfer sends
+// amount of token_id, or amounts of token_ids with safeBatchTransferFrom, and
+// passes data to the receiver hook.
+func buildNftTransfer(dynamicFeeTx *Eip1559DynamicFeeTx) (*txCall, error) {
+	if isEthTransfer(dynamicFeeTx) {
+		return nil, errors.New("contract address is required")
+	}
+	contractAddress, err := parseAddress("contract address", dynamicFeeTx.ContractAddress)
+	if err != nil {
+		return nil, err
+	}
+	from, err := parseAddress("from address", dynamicFeeTx.FromAddress)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseAddress("to address", dynamicFeeTx.ToAddress)
+	if err != nil {
+		return nil, err
+	}
+	if dynamicFeeTx.TokenId != "" && len(dynamicFeeTx.TokenIds) != 0 {
+		return nil, errors.New("token_id and token_ids are exclusive")
+	}
+
+	var signature string
+	var args []interface{}
+	switch dynamicFeeTx.AssetType {
+	case AssetTypeErc721:
+		if len(dynamicFeeTx.TokenIds) != 0 {
+			return nil, errors.New("erc721 transfers one token_id per transaction")
+		}
+		tokenId, err := parseBigInt("token id", dynamicFeeTx.TokenId)
+		if err != nil {
+			return nil, err
+		}
+		signature, args = erc721TransferSignature, []interface{}{from, to, tokenId}
+	case AssetTypeErc1155:
+		data, err := hexutil.Decode(dynamicFeeTx.Data)
+		if dynamicFeeTx.Data != "" && err != nil {
+			return nil, fmt.Errorf("invalid data: %w", err)
+		}
+		if data == nil {
+			data = []byte{}
+		}
+		if len(dynamicFeeTx.TokenIds) == 0 {
+			tokenId, err := parseBigInt("token id", dynamicFeeTx.TokenId)
+			if err != nil {
+				return nil, err
+			}
+			amount, err := parseBigInt("amount", dynamicFeeTx.Amount)
+			if err != nil {
+				return nil, err
+			}
+			signature, args = erc1155TransferSignature, []interface{}{from, to, tokenId, amount, data}
+			break
+		}
+		if len(dynamicFeeTx.Amounts) != len(dynamicFeeTx.TokenIds) {
+			return nil, fmt.Errorf("%d token_ids with %d amounts", len(dynamicFeeTx.TokenIds), len(dynamicFeeTx.Amounts))
+		}
+		tokenIds := make([]*big.Int, len(dynamicFeeTx.TokenIds))
+		amounts := make([]*big.Int, len(dynamicFeeTx.Amounts))
+		for i := range dynamicFeeTx.TokenIds {
+			if tokenIds[i], err = parseBigInt("token id", dynamicFeeTx.TokenIds[i]); err != nil {
+				return nil, err
+			}
+			if amounts[i], err = parseBigInt("amount", dynamicFeeTx.Amounts[i]); err != nil {
+				return nil, err
+			}
+		}
+		signature, args = erc1155BatchTransferSignature, []interface{}{from, to, tokenIds, amounts, data}
+	default:
+		return nil, fmt.Errorf("unsupported asset type: %s", dynamicFeeTx.AssetType)
+	}
+
+	method, err := parseAbiMethod(signature, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	packed, err := method.Inputs.Pack(args...)
+	if err != nil {
+		return nil, err
+	}
+	callData := append(append([]byte{}, method.ID...), packed...)
+	decodedCall, err := decodeAbiCall(method, callData)
+	if err != nil {
+		return nil, err
+	}
+	return &txCall{to: &contractAddress, value: big.NewInt(0), data: callData, decodedCall: decodedCall}, nil
+}
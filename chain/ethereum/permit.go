@@ -0,0 +1,400 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/DQYXACML/wallet-sign/config"
+	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// sign_type values of a permit request
+const (
+	SignTypeEip2612Permit       = "eip2612_permit"
+	SignTypePermit2Single       = "permit2_single"
+	SignTypePermit2TransferFrom = "permit2_transfer_from"
+)
+
+// Permit2Address is the Uniswap Permit2 contract, the same on every chain
+const Permit2Address = "0x000000000022D473030F116dDEE9F6B43aC78BA3"
+
+var (
+	maxUint48  = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 48), big.NewInt(1))
+	maxUint160 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 160), big.NewInt(1))
+)
+
+// PermitMessage is the permit request body. token is the verifying contract
+// of an EIP-2612 permit and the permitted token of a Permit2 permit, whose
+// verifying contract is permit2_address. The owner must be the address of the
+// signing key and deadline is the sigDeadline of a PermitSingle.
+type PermitMessage struct {
+	SignType       string `json:"sign_type"`
+	ChainId        string `json:"chain_id"`
+	Token          string `json:"token"`
+	TokenName      string `json:"token_name,omitempty"`
+	TokenVersion   string `json:"token_version,omitempty"`
+	Permit2Address string `json:"permit2_address,omitempty"`
+	Owner          string `json:"owner"`
+	Spender        string `json:"spender"`
+	Amount         string `json:"amount"`
+	Nonce          string `json:"nonce"`
+	Deadline       string `json:"deadline"`
+	Expiration     string `json:"expiration,omitempty"`
+}
+
+type permitPolicy struct {
+	spenders  map[common.Address]bool
+	maxAmount *big.Int
+}
+
+// permitPolicies holds the permit_policies by token, nil when permits are not
+// restricted.
+type permitPolicies struct {
+	tokens   map[common.Address]*permitPolicy
+	anyToken *permitPolicy
+}
+
+func newPermitPolicies(policyConfigs []config.PermitPolicyConfig) (*permitPolicies, error) {
+	if len(policyConfigs) == 0 {
+		return nil, nil
+	}
+	policies := &permitPolicies{tokens: make(map[common.Address]*permitPolicy)}
+	for _, pc := range policyConfigs {
+		policy := &permitPolicy{}
+		if len(pc.Spenders) != 0 {
+			policy.spenders = make(map[common.Address]bool, len(pc.Spenders))
+			for _, spender := range pc.Spenders {
+				if !common.IsHexAddress(spender) {
+					return nil, fmt.Errorf("permit policy %s: invalid spender %s", pc.Token, spender)
+				}
+				policy.spenders[common.HexToAddress(spender)] = true
+			}
+		}
+		if pc.MaxAmount != "" {
+			maxAmount, ok := new(big.Int).SetString(pc.MaxAmount, 10)
+			if !ok || maxAmount.Sign() < 0 {
+				return nil, fmt.Errorf("permit policy %s: invalid max_amount %s", pc.Token, pc.MaxAmount)
+			}
+			policy.maxAmount = maxAmount
+		}
+		if pc.Token == "*" {
+			if policies.anyToken != nil {
+				return nil, errors.New("duplicate permit policy for *")
+			}
+			policies.anyToken = policy
+			continue
+		}
+		if !common.IsHexAddress(pc.Token) {
+			return nil, fmt.Errorf("permit policy: invalid token %s", pc.Token)
+		}
+		token := common.HexToAddress(pc.Token)
+		if _, ok := policies.tokens[token]; ok {
+			return nil, fmt.Errorf("duplicate permit policy for %s", pc.Token)
+		}
+		policies.tokens[token] = policy
+	}
+	return policies, nil
+}
+
+// check rejects a permit the policy of its token does not allow.
+func (p *permitPolicies) check(token common.Address, spender common.Address, amount *big.Int) error {
+	if p == nil {
+		return nil
+	}
+	policy, ok := p.tokens[token]
+	if !ok {
+		policy = p.anyToken
+	}
+	if policy == nil {
+		return fmt.Errorf("no permit policy for token %s", token.Hex())
+	}
+	if policy.spenders != nil && !policy.spenders[spender] {
+		return fmt.Errorf("spender %s not allowed for token %s", spender.Hex(), token.Hex())
+	}
+	if policy.maxAmount != nil && amount.Cmp(policy.maxAmount) > 0 {
+		return fmt.Errorf("amount %s exceeds max amount %s for token %s", amount, policy.maxAmount, token.Hex())
+	}
+	return nil
+}
+
+// BuildAndSignPermit builds the EIP-712 typed data of an EIP-2612 permit or a
+// Permit2 PermitSingle/PermitTransferFrom and signs it with the owner key.
+func (c *ChainAdaptor) BuildAndSignPermit(ctx context.Context, req *wallet.BuildAndSignPermitRequest) (*wallet.BuildAndSignPermitResponse, error) {
+	resp := &wallet.BuildAndSignPermitResponse{
+		Code: wallet.ReturnCode_ERROR,
+	}
+	permitJsonByte, err := base64.StdEncoding.DecodeString(req.PermitBase64Body)
+	if err != nil {
+		log.Error("decode string fail", "err", err)
+		resp.Message = "decode base64 string fail"
+		return resp, nil
+	}
+	var permit PermitMessage
+	if err := json.Unmarshal(permitJsonByte, &permit); err != nil {
+		log.Error("parse json fail", "err", err)
+		resp.Message = "parse permit fail: " + err.Error()
+		return resp, nil
+	}
+	owner, err := publicKeyToAddress(req.PublicKey)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	typedData, err := c.buildPermit(&permit, owner)
+	if err != nil {
+		log.Error("build permit fail", "err", err, "signType", permit.SignType)
+		resp.Message = "build permit fail: " + err.Error()
+		return resp, nil
+	}
+	messageHash, _, err := apitypes.TypedDataAndHash(*typedData)
+	if err != nil {
+		log.Error("hash typed data fail", "err", err)
+		resp.Message = "hash typed data fail: " + err.Error()
+		return resp, nil
+	}
+	signature, err := c.signDigest(req.PublicKey, messageHash)
+	if err != nil {
+		log.Error("sign permit fail", "err", err)
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	typedDataJson, err := json.Marshal(typedData)
+	if err != nil {
+		log.Error("marshal typed data fail", "err", err)
+	}
+	sig, _ := hexutil.Decode(signature)
+	log.Info("sign permit success",
+		"signType", permit.SignType,
+		"owner", owner,
+		"token", permit.Token,
+		"spender", permit.Spender,
+		"amount", permit.Amount,
+		"messageHash", hexutil.Encode(messageHash),
+	)
+	resp.Code = wallet.ReturnCode_SUCCESS
+	resp.Message = "sign permit success"
+	resp.Signature = signature
+	resp.V = uint32(sig[crypto.RecoveryIDOffset])
+	resp.R = hexutil.Encode(sig[:32])
+	resp.S = hexutil.Encode(sig[32:64])
+	resp.MessageHash = hexutil.Encode(messageHash)
+	resp.TypedData = string(typedDataJson)
+	return resp, nil
+}
+
+// buildPermit checks the permit against the policy and returns its typed data.
+func (c *ChainAdaptor) buildPermit(permit *PermitMessage, owner common.Address) (*apitypes.TypedData, error) {
+	if !common.IsHexAddress(permit.Owner) || common.HexToAddress(permit.Owner) != owner {
+		return nil, fmt.Errorf("owner %s is not the signing key %s", permit.Owner, owner.Hex())
+	}
+	chainID, err := parseBigInt("chain ID", permit.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	token, err := parseAddress("token", permit.Token)
+	if err != nil {
+		return nil, err
+	}
+	spender, err := parseAddress("spender", permit.Spender)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseBigInt("amount", permit.Amount)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := parseBigInt("nonce", permit.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	deadline, err := parseBigInt("deadline", permit.Deadline)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.permitPolicies.check(token, spender, amount); err != nil {
+		return nil, err
+	}
+
+	switch permit.SignType {
+	case SignTypeEip2612Permit:
+		if permit.TokenName == "" {
+			return nil, errors.New("token_name is required")
+		}
+		return &apitypes.TypedData{
+			Types: apitypes.Types{
+				"EIP712Domain": eip712DomainType(permit.TokenVersion != ""),
+				"Permit": {
+					{Name: "owner", Type: "address"},
+					{Name: "spender", Type: "address"},
+					{Name: "value", Type: "uint256"},
+					{Name: "nonce", Type: "uint256"},
+					{Name: "deadline", Type: "uint256"},
+				},
+			},
+			PrimaryType: "Permit",
+			Domain: apitypes.TypedDataDomain{
+				Name:              permit.TokenName,
+				Version:           permit.TokenVersion,
+				ChainId:           (*math.HexOrDecimal256)(chainID),
+				VerifyingContract: token.Hex(),
+			},
+			Message: apitypes.TypedDataMessage{
+				"owner":    owner.Hex(),
+				"spender":  spender.Hex(),
+				"value":    amount.String(),
+				"nonce":    nonce.String(),
+				"deadline": deadline.String(),
+			},
+		}, nil
+	case SignTypePermit2Single:
+		if amount.Cmp(maxUint160) > 0 {
+			return nil, fmt.Errorf("amount overflows uint160: %s", amount)
+		}
+		if nonce.Cmp(maxUint48) > 0 {
+			return nil, fmt.Errorf("nonce overflows uint48: %s", nonce)
+		}
+		expiration, err := parseBigInt("expiration", permit.Expiration)
+		if err != nil {
+			return nil, err
+		}
+		if expiration.Cmp(maxUint48) > 0 {
+			return nil, fmt.Errorf("expiration overflows uint48: %s", expiration)
+		}
+		domain, err := permit2Domain(permit, chainID)
+		if err != nil {
+			return nil, err
+		}
+		return &apitypes.TypedData{
+			Types: apitypes.Types{
+				"EIP712Domain": eip712DomainType(false),
+				"PermitSingle": {
+					{Name: "details", Type: "PermitDetails"},
+					{Name: "spender", Type: "address"},
+					{Name: "sigDeadline", Type: "uint256"},
+				},
+				"PermitDetails": {
+					{Name: "token", Type: "address"},
+					{Name: "amount", Type: "uint160"},
+					{Name: "expiration", Type: "uint48"},
+					{Name: "nonce", Type: "uint48"},
+				},
+			},
+			PrimaryType: "PermitSingle",
+			Domain:      domain,
+			Message: apitypes.TypedDataMessage{
+				"details": map[string]interface{}{
+					"token":      token.Hex(),
+					"amount":     amount.String(),
+					"expiration": expiration.String(),
+					"nonce":      nonce.String(),
+				},
+				"spender":     spender.Hex(),
+				"sigDeadline": deadline.String(),
+			},
+		}, nil
+	case SignTypePermit2TransferFrom:
+		domain, err := permit2Domain(permit, chainID)
+		if err != nil {
+			return nil, err
+		}
+		return &apitypes.TypedData{
+			Types: apitypes.Types{
+				"EIP712Domain": eip712DomainType(false),
+				"PermitTransferFrom": {
+					{Name: "permitted", Type: "TokenPermissions"},
+					{Name: "spender", Type: "address"},
+					{Name: "nonce", Type: "uint256"},
+					{Name: "deadline", Type: "uint256"},
+				},
+				"TokenPermissions": {
+					{Name: "token", Type: "address"},
+					{Name: "amount", Type: "uint256"},
+				},
+			},
+			PrimaryType: "PermitTransferFrom",
+			Domain:      domain,
+			Message: apitypes.TypedDataMessage{
+				"permitted": map[string]interface{}{
+					"token":  token.Hex(),
+					"amount": amount.String(),
+				},
+				"spender":  spender.Hex(),
+				"nonce":    nonce.String(),
+				"deadline": deadline.String(),
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported sign type: %s", permit.SignType)
+	}
+}
+
+// isPermitPrimaryType reports typed data granting a token allowance, which
+// must not bypass the permit policies through signTypedData.
+func isPermitPrimaryType(primaryType string) bool {
+	switch primaryType {
+	case "Permit", "PermitSingle", "PermitBatch", "PermitTransferFrom", "PermitBatchTransferFrom",
+		"PermitWitnessTransferFrom", "PermitBatchWitnessTransferFrom":
+		return true
+	}
+	return false
+}
+
+// eip712DomainType is the domain of a token permit, tokens like USDC sign a
+// version while Permit2 has none.
+func eip712DomainType(withVersion bool) []apitypes.Type {
+	domainType := []apitypes.Type{{Name: "name", Type: "string"}}
+	if withVersion {
+		domainType = append(domainType, apitypes.Type{Name: "version", Type: "string"})
+	}
+	return append(domainType,
+		apitypes.Type{Name: "chainId", Type: "uint256"},
+		apitypes.Type{Name: "verifyingContract", Type: "address"},
+	)
+}
+
+func permit2Domain(permit *PermitMessage, chainID *big.Int) (apitypes.TypedDataDomain, error) {
+	permit2 := common.HexToAddress(Permit2Address)
+	if permit.Permit2Address != "" {
+		var err error
+		if permit2, err = parseAddress("permit2 address", permit.Permit2Address); err != nil {
+			return apitypes.TypedDataDomain{}, err
+		}
+	}
+	return apitypes.TypedDataDomain{
+		Name:              "Permit2",
+		ChainId:           (*math.HexOrDecimal256)(chainID),
+		VerifyingContract: permit2.Hex(),
+	}, nil
+}
+
+func parseAddress(name string, value string) (common.Address, error) {
+	if !common.IsHexAddress(value) {
+		return common.Address{}, fmt.Errorf("invalid %s: %s", name, value)
+	}
+	return common.HexToAddress(value), nil
+}
+
+func publicKeyToAddress(publicKey string) (common.Address, error) {
+	publicKeyBytes, err := hex.DecodeString(publicKey)
+	if err != nil {
+		return common.Address{}, errors.New("invalid public key")
+	}
+	pubKey, err := crypto.UnmarshalPubkey(publicKeyBytes)
+	if err != nil {
+		if pubKey, err = crypto.DecompressPubkey(publicKeyBytes); err != nil {
+			return common.Address{}, errors.New("invalid public key")
+		}
+	}
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
@@ -0,0 +1,224 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Safe operation values
+const (
+	SafeOperationCall         = 0
+	SafeOperationDelegateCall = 1
+)
+
+// SafeTransaction is the Safe transaction request body. The data is hex, or
+// ABI encoded from function or abi and args like a contract_call. Safes older
+// than 1.3.0 sign a domain without chain id, an empty safe_version is 1.3.0.
+type SafeTransaction struct {
+	SafeAddress    string          `json:"safe_address"`
+	SafeVersion    string          `json:"safe_version,omitempty"`
+	ChainId        string          `json:"chain_id"`
+	To             string          `json:"to"`
+	Value          string          `json:"value"`
+	Data           string          `json:"data,omitempty"`
+	Function       string          `json:"function,omitempty"`
+	Abi            json.RawMessage `json:"abi,omitempty"`
+	Method         string          `json:"method,omitempty"`
+	Args           json.RawMessage `json:"args,omitempty"`
+	Operation      uint8           `json:"operation"`
+	SafeTxGas      string          `json:"safe_tx_gas"`
+	BaseGas        string          `json:"base_gas"`
+	GasPrice       string          `json:"gas_price"`
+	GasToken       string          `json:"gas_token,omitempty"`
+	RefundReceiver string          `json:"refund_receiver,omitempty"`
+	Nonce          string          `json:"nonce"`
+}
+
+// BuildAndSignSafeTransaction signs the EIP-712 SafeTx hash with one owner key.
+// The signature is in the Safe packed r || s || v form with v 27 or 28, the
+// coordinator sorts the owner signatures by owner address for execTransaction.
+func (c *ChainAdaptor) BuildAndSignSafeTransaction(ctx context.Context, req *wallet.BuildAndSignSafeTransactionRequest) (*wallet.BuildAndSignSafeTransactionResponse, error) {
+	resp := &wallet.BuildAndSignSafeTransactionResponse{
+		Code: wallet.ReturnCode_ERROR,
+	}
+	safeTxJsonByte, err := base64.StdEncoding.DecodeString(req.SafeTxBase64Body)
+	if err != nil {
+		log.Error("decode string fail", "err", err)
+		resp.Message = "decode base64 string fail"
+		return resp, nil
+	}
+	var safeTx SafeTransaction
+	if err := json.Unmarshal(safeTxJsonByte, &safeTx); err != nil {
+		log.Error("parse json fail", "err", err)
+		resp.Message = "parse safe transaction fail: " + err.Error()
+		return resp, nil
+	}
+	owner, err := publicKeyToAddress(req.PublicKey)
+	if err != nil {
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	typedData, decodedCall, err := buildSafeTx(&safeTx)
+	if err != nil {
+		log.Error("build safe transaction fail", "err", err)
+		resp.Message = "build safe transaction fail: " + err.Error()
+		return resp, nil
+	}
+	safeTxHash, _, err := apitypes.TypedDataAndHash(*typedData)
+	if err != nil {
+		log.Error("hash safe transaction fail", "err", err)
+		resp.Message = "hash safe transaction fail: " + err.Error()
+		return resp, nil
+	}
+	signature, err := c.signDigest(req.PublicKey, safeTxHash)
+	if err != nil {
+		log.Error("sign safe transaction fail", "err", err)
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	if decodedCall != nil {
+		b, err := json.Marshal(decodedCall)
+		if err != nil {
+			log.Error("marshal decoded call fail", "err", err)
+		}
+		resp.DecodedCall = string(b)
+	}
+	log.Info("sign safe transaction success",
+		"safe", safeTx.SafeAddress,
+		"owner", owner,
+		"to", safeTx.To,
+		"operation", safeTx.Operation,
+		"nonce", safeTx.Nonce,
+		"safeTxHash", hexutil.Encode(safeTxHash),
+	)
+	resp.Code = wallet.ReturnCode_SUCCESS
+	resp.Message = "sign safe transaction success"
+	resp.SafeTxHash = hexutil.Encode(safeTxHash)
+	resp.Signature = signature
+	resp.Owner = owner.Hex()
+	return resp, nil
+}
+
+// buildSafeTx returns the SafeTx typed data and the decoded call when the
+// data was ABI encoded.
+func buildSafeTx(safeTx *SafeTransaction) (*apitypes.TypedData, *DecodedCall, error) {
+	safeAddress, err := parseAddress("safe address", safeTx.SafeAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+	chainID, err := parseBigInt("chain ID", safeTx.ChainId)
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := parseAddress("to", safeTx.To)
+	if err != nil {
+		return nil, nil, err
+	}
+	if safeTx.Operation != SafeOperationCall && safeTx.Operation != SafeOperationDelegateCall {
+		return nil, nil, fmt.Errorf("invalid operation: %d", safeTx.Operation)
+	}
+	gasToken, refundReceiver := common.Address{}, common.Address{}
+	if safeTx.GasToken != "" {
+		if gasToken, err = parseAddress("gas token", safeTx.GasToken); err != nil {
+			return nil, nil, err
+		}
+	}
+	if safeTx.RefundReceiver != "" {
+		if refundReceiver, err = parseAddress("refund receiver", safeTx.RefundReceiver); err != nil {
+			return nil, nil, err
+		}
+	}
+	numbers := make(map[string]*big.Int)
+	for name, value := range map[string]string{
+		"value":     safeTx.Value,
+		"safeTxGas": safeTx.SafeTxGas,
+		"baseGas":   safeTx.BaseGas,
+		"gasPrice":  safeTx.GasPrice,
+		"nonce":     safeTx.Nonce,
+	} {
+		if value == "" {
+			value = "0"
+		}
+		if numbers[name], err = parseBigInt(name, value); err != nil {
+			return nil, nil, err
+		}
+	}
+	data, decodedCall, err := buildCallData(safeTx.Data, safeTx.Function, safeTx.Abi, safeTx.Method, safeTx.Args)
+	if err != nil {
+		return nil, nil, err
+	}
+	domainType := []apitypes.Type{{Name: "verifyingContract", Type: "address"}}
+	domain := apitypes.TypedDataDomain{VerifyingContract: safeAddress.Hex()}
+	withChainID, err := safeDomainHasChainID(safeTx.SafeVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+	if withChainID {
+		domainType = []apitypes.Type{{Name: "chainId", Type: "uint256"}, {Name: "verifyingContract", Type: "address"}}
+		domain.ChainId = (*math.HexOrDecimal256)(chainID)
+	}
+	return &apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": domainType,
+			"SafeTx": {
+				{Name: "to", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "data", Type: "bytes"},
+				{Name: "operation", Type: "uint8"},
+				{Name: "safeTxGas", Type: "uint256"},
+				{Name: "baseGas", Type: "uint256"},
+				{Name: "gasPrice", Type: "uint256"},
+				{Name: "gasToken", Type: "address"},
+				{Name: "refundReceiver", Type: "address"},
+				{Name: "nonce", Type: "uint256"},
+			},
+		},
+		PrimaryType: "SafeTx",
+		Domain:      domain,
+		Message: apitypes.TypedDataMessage{
+			"to":             to.Hex(),
+			"value":          numbers["value"].String(),
+			"data":           hexutil.Encode(data),
+			"operation":      strconv.Itoa(int(safeTx.Operation)),
+			"safeTxGas":      numbers["safeTxGas"].String(),
+			"baseGas":        numbers["baseGas"].String(),
+			"gasPrice":       numbers["gasPrice"].String(),
+			"gasToken":       gasToken.Hex(),
+			"refundReceiver": refundReceiver.Hex(),
+			"nonce":          numbers["nonce"].String(),
+		},
+	}, decodedCall, nil
+}
+
+// safeDomainHasChainID reports whether the Safe version signs the chain id in
+// its domain, which Safe added in 1.3.0.
+func safeDomainHasChainID(version string) (bool, error) {
+	if version == "" {
+		return true, nil
+	}
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) < 2 {
+		return false, fmt.Errorf("invalid safe version: %s", version)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false, fmt.Errorf("invalid safe version: %s", version)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false, fmt.Errorf("invalid safe version: %s", version)
+	}
+	return major > 1 || (major == 1 && minor >= 3), nil
+}
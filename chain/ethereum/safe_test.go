@@ -0,0 +1,110 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Safe contract type hashes, DOMAIN_SEPARATOR_TYPEHASH from 1.3.0 on and the
+// one of older Safes without chain id
+var (
+	safeTxTypeHash           = common.HexToHash("0xbb8310d486368db6bd6f849402fdd73ad53d316b5a4b2644ad6efe0f941286d8")
+	safeDomainTypeHash       = common.HexToHash("0x47e79534a245952e8b16893a336b85a3d9ea9fa8c573f3d803afb92a79469218")
+	safeLegacyDomainTypeHash = common.HexToHash("0x035aff83d86937d35b32e04f0ddc6ff469290eef2f1b692d8a815c89404d4749")
+)
+
+// safeGetTransactionHash is Safe.getTransactionHash written out as the
+// contract encodes it.
+func safeGetTransactionHash(safe common.Address, chainID int64, withChainID bool, to common.Address, value int64, data []byte, operation uint8, safeTxGas, baseGas, gasPrice int64, gasToken, refundReceiver common.Address, nonce int64) []byte {
+	word := func(n int64) []byte { return common.LeftPadBytes(big.NewInt(n).Bytes(), 32) }
+	var domainSeparator []byte
+	if withChainID {
+		domainSeparator = crypto.Keccak256(safeDomainTypeHash[:], word(chainID), common.LeftPadBytes(safe[:], 32))
+	} else {
+		domainSeparator = crypto.Keccak256(safeLegacyDomainTypeHash[:], common.LeftPadBytes(safe[:], 32))
+	}
+	safeTxHash := crypto.Keccak256(bytes.Join([][]byte{
+		safeTxTypeHash[:],
+		common.LeftPadBytes(to[:], 32),
+		word(value),
+		crypto.Keccak256(data),
+		word(int64(operation)),
+		word(safeTxGas),
+		word(baseGas),
+		word(gasPrice),
+		common.LeftPadBytes(gasToken[:], 32),
+		common.LeftPadBytes(refundReceiver[:], 32),
+		word(nonce),
+	}, nil))
+	return crypto.Keccak256([]byte{0x19, 0x01}, domainSeparator, safeTxHash)
+}
+
+func TestBuildAndSignSafeTransactionHash(t *testing.T) {
+	safe := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	to := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	gasToken := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	refundReceiver := common.HexToAddress("0x4444444444444444444444444444444444444444")
+	transfer := hexutil.MustDecode("0xa9059cbb00000000000000000000000022222222222222222222222222222222222222220000000000000000000000000000000000000000000000000de0b6b3a7640000")
+	tests := []struct {
+		name    string
+		body    string
+		safeTx  []byte
+		decoded bool
+	}{
+		{
+			name:   "eth transfer",
+			body:   `{"safe_address":"` + safe.Hex() + `","chain_id":"1","to":"` + to.Hex() + `","value":"1000000000000000000","operation":0,"safe_tx_gas":"0","base_gas":"0","gas_price":"0","nonce":"7"}`,
+			safeTx: safeGetTransactionHash(safe, 1, true, to, 1000000000000000000, nil, 0, 0, 0, 0, common.Address{}, common.Address{}, 7),
+		},
+		{
+			name:    "token transfer with refund",
+			body:    `{"safe_address":"` + safe.Hex() + `","chain_id":"137","to":"` + to.Hex() + `","value":"0","function":"transfer(address,uint256)","args":["` + to.Hex() + `","1000000000000000000"],"operation":0,"safe_tx_gas":"50000","base_gas":"21000","gas_price":"3","gas_token":"` + gasToken.Hex() + `","refund_receiver":"` + refundReceiver.Hex() + `","nonce":"42"}`,
+			safeTx:  safeGetTransactionHash(safe, 137, true, to, 0, transfer, 0, 50000, 21000, 3, gasToken, refundReceiver, 42),
+			decoded: true,
+		},
+		{
+			name:   "delegate call on a 1.2.0 safe",
+			body:   `{"safe_address":"` + safe.Hex() + `","safe_version":"1.2.0","chain_id":"1","to":"` + to.Hex() + `","value":"0","data":"0xdeadbeef","operation":1,"safe_tx_gas":"0","base_gas":"0","gas_price":"0","nonce":"0"}`,
+			safeTx: safeGetTransactionHash(safe, 1, false, to, 0, hexutil.MustDecode("0xdeadbeef"), 1, 0, 0, 0, common.Address{}, common.Address{}, 0),
+		},
+	}
+	c, publicKey := newTestAdaptor(t, cowPrivateKey)
+	owner, err := publicKeyToAddress(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := c.BuildAndSignSafeTransaction(context.Background(), &wallet.BuildAndSignSafeTransactionRequest{
+				PublicKey:        publicKey,
+				SafeTxBase64Body: base64.StdEncoding.EncodeToString([]byte(tt.body)),
+			})
+			if err != nil || resp.Code != wallet.ReturnCode_SUCCESS {
+				t.Fatalf("sign safe transaction fail: %v %s", err, resp.GetMessage())
+			}
+			if resp.SafeTxHash != hexutil.Encode(tt.safeTx) {
+				t.Fatalf("safe tx hash %s, want %s", resp.SafeTxHash, hexutil.Encode(tt.safeTx))
+			}
+			if (resp.DecodedCall != "") != tt.decoded {
+				t.Fatalf("decoded call %q", resp.DecodedCall)
+			}
+			sig := hexutil.MustDecode(resp.Signature)
+			sig[crypto.RecoveryIDOffset] -= 27
+			pubKey, err := crypto.SigToPub(tt.safeTx, sig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if crypto.PubkeyToAddress(*pubKey) != owner || resp.Owner != owner.Hex() {
+				t.Fatalf("signed by %s, want %s", crypto.PubkeyToAddress(*pubKey), owner)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package ethereum
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/holiman/uint256"
+)
+
+// Authorization is an EIP-7702 authorization tuple delegating the code of an
+// account to address. Without r and s it is signed with the request public
+// key, a public_key set on the tuple must be that key. r and s are decimal or
+// 0x hex. chain_id 0 is valid on every chain and only accepted when
+// allow_any_chain_authorization is set.
+type Authorization struct {
+	ChainId   string `json:"chain_id"`
+	Address   string `json:"address"`
+	Nonce     uint64 `json:"nonce"`
+	PublicKey string `json:"public_key,omitempty"`
+	YParity   uint8  `json:"y_parity,omitempty"`
+	R         string `json:"r,omitempty"`
+	S         string `json:"s,omitempty"`
+}
+
+// signAuthorizations returns the signed authorization list, signing the
+// tuples that do not carry a signature yet.
+func (c *ChainAdaptor) signAuthorizations(auths []Authorization, publicKey string) ([]types.SetCodeAuthorization, error) {
+	signedAuths := make([]types.SetCodeAuthorization, len(auths))
+	for i, auth := range auths {
+		chainID, err := parseUint256("authorization chain ID", auth.ChainId)
+		if err != nil {
+			return nil, fmt.Errorf("authorization %d: %w", i, err)
+		}
+		if chainID.IsZero() && !c.allowAnyChainAuth {
+			return nil, fmt.Errorf("authorization %d: chain ID 0 is not allowed", i)
+		}
+		if !common.IsHexAddress(auth.Address) {
+			return nil, fmt.Errorf("authorization %d: invalid address: %s", i, auth.Address)
+		}
+		setCodeAuth := types.SetCodeAuthorization{
+			ChainID: *chainID,
+			Address: common.HexToAddress(auth.Address),
+			Nonce:   auth.Nonce,
+		}
+		if auth.R != "" || auth.S != "" {
+			r, ok := new(big.Int).SetString(auth.R, 0)
+			if !ok || r.Sign() <= 0 || r.BitLen() > 256 {
+				return nil, fmt.Errorf("authorization %d: invalid r: %s", i, auth.R)
+			}
+			s, ok := new(big.Int).SetString(auth.S, 0)
+			if !ok || s.Sign() <= 0 || s.BitLen() > 256 {
+				return nil, fmt.Errorf("authorization %d: invalid s: %s", i, auth.S)
+			}
+			setCodeAuth.V = auth.YParity
+			setCodeAuth.R.SetFromBig(r)
+			setCodeAuth.S.SetFromBig(s)
+		} else {
+			if auth.PublicKey != "" && auth.PublicKey != publicKey {
+				return nil, fmt.Errorf("authorization %d: public key is not the request public key", i)
+			}
+			if setCodeAuth, err = c.signAuthorization(setCodeAuth, publicKey); err != nil {
+				return nil, fmt.Errorf("authorization %d: %w", i, err)
+			}
+		}
+		authority, err := setCodeAuth.Authority()
+		if err != nil {
+			return nil, fmt.Errorf("authorization %d: %w", i, err)
+		}
+		log.Info("set code authorization", "authority", authority, "delegate", setCodeAuth.Address, "chainId", auth.ChainId, "nonce", auth.Nonce)
+		signedAuths[i] = setCodeAuth
+	}
+	return signedAuths, nil
+}
+
+// signAuthorization signs the authorization hash keccak256(0x05 || rlp([chain_id, address, nonce])).
+func (c *ChainAdaptor) signAuthorization(auth types.SetCodeAuthorization, publicKey string) (types.SetCodeAuthorization, error) {
+	privKey, isOk := c.db.GetPrivKey(publicKey)
+	if !isOk {
+		return auth, errors.New("get private key by public key fail")
+	}
+	signature, err := c.signer.SignMessage(privKey, auth.SigHash().Hex())
+	if err != nil {
+		log.Error("sign authorization fail", "err", err)
+		return auth, errors.New("sign authorization fail")
+	}
+	sig, err := hex.DecodeString(signature)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return auth, errors.New("decode signature failed")
+	}
+	auth.R.SetBytes(sig[:32])
+	auth.S.SetBytes(sig[32:64])
+	auth.V = sig[64]
+	return auth, nil
+}
+
+// buildSetCodeTx builds an EIP-7702 transaction, it must call an existing
+// account and carry at least one authorization.
+func buildSetCodeTx(dynamicFeeTx *Eip1559DynamicFeeTx, call *txCall, chainID *big.Int, authList []types.SetCodeAuthorization) (*types.SetCodeTx, error) {
+	if call.to == nil {
+		return nil, errors.New("set code transaction can not create a contract")
+	}
+	if len(authList) == 0 {
+		return nil, errors.New("set code transaction without authorization list")
+	}
+	maxPriorityFeePerGas, err := parseUint256("max priority fee", dynamicFeeTx.MaxPriorityFeePerGas)
+	if err != nil {
+		return nil, err
+	}
+	maxFeePerGas, err := parseUint256("max fee", dynamicFeeTx.MaxFeePerGas)
+	if err != nil {
+		return nil, err
+	}
+	chainIDU256, overflow := uint256.FromBig(chainID)
+	if overflow || chainID.Sign() < 0 {
+		return nil, fmt.Errorf("invalid chain ID: %s", chainID)
+	}
+	value, overflow := uint256.FromBig(call.value)
+	if overflow || call.value.Sign() < 0 {
+		return nil, fmt.Errorf("invalid value: %s", call.value)
+	}
+	return &types.SetCodeTx{
+		ChainID:    chainIDU256,
+		Nonce:      dynamicFeeTx.Nonce,
+		GasTipCap:  maxPriorityFeePerGas,
+		GasFeeCap:  maxFeePerGas,
+		Gas:        dynamicFeeTx.GasLimit,
+		To:         *call.to,
+		Value:      value,
+		Data:       call.data,
+		AccessList: dynamicFeeTx.AccessList,
+		AuthList:   authList,
+	}, nil
+}
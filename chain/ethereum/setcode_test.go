@@ -0,0 +1,76 @@
+package ethereum
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSignAuthorizations(t *testing.T) {
+	c, publicKey := newTestAdaptor(t, cowPrivateKey)
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPublicKey := hex.EncodeToString(crypto.FromECDSAPub(&key.PublicKey))
+	const delegate = "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+
+	tests := []struct {
+		name     string
+		auth     Authorization
+		anyChain bool
+		wantErr  string
+	}{
+		{
+			name: "request key",
+			auth: Authorization{ChainId: "1", Address: delegate, Nonce: 7},
+		},
+		{
+			name: "same public key",
+			auth: Authorization{ChainId: "1", Address: delegate, Nonce: 7, PublicKey: publicKey},
+		},
+		{
+			name:    "other public key",
+			auth:    Authorization{ChainId: "1", Address: delegate, Nonce: 7, PublicKey: otherPublicKey},
+			wantErr: "public key is not the request public key",
+		},
+		{
+			name:    "any chain",
+			auth:    Authorization{ChainId: "0", Address: delegate, Nonce: 7},
+			wantErr: "chain ID 0 is not allowed",
+		},
+		{
+			name:     "any chain allowed",
+			auth:     Authorization{ChainId: "0", Address: delegate, Nonce: 7},
+			anyChain: true,
+		},
+	}
+	owner, err := publicKeyToAddress(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.allowAnyChainAuth = tt.anyChain
+			auths, err := c.signAuthorizations([]Authorization{tt.auth}, publicKey)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			authority, err := auths[0].Authority()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if authority != owner {
+				t.Fatalf("authority %s, want %s", authority, owner)
+			}
+		})
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/rlp"
 	"math/big"
 )
 
@@ -26,7 +25,9 @@ func BuildErc20Data(toAddress common.Address, amount *big.Int) []byte {
 	return data
 }
 
-func CreateEip1559UnSignTx(txData *types.DynamicFeeTx, chainId *big.Int) (string, error) {
+// CreateUnSignTx returns the hash to sign, EIP-155 protected for legacy
+// transactions.
+func CreateUnSignTx(txData types.TxData, chainId *big.Int) (string, error) {
 	tx := types.NewTx(txData)
 	// 签名者
 	signer := types.LatestSignerForChainID(chainId)
@@ -34,16 +35,16 @@ func CreateEip1559UnSignTx(txData *types.DynamicFeeTx, chainId *big.Int) (string
 	return txHash.String(), nil
 }
 
-func CreateEip1559SignedTx(txData *types.DynamicFeeTx, signature []byte, chainId *big.Int) (types.Signer, *types.Transaction, string, string, error) {
+func CreateSignedTx(txData types.TxData, signature []byte, chainId *big.Int) (types.Signer, *types.Transaction, string, string, error) {
 	tx := types.NewTx(txData)
 	signer := types.LatestSignerForChainID(chainId)
 	signedTx, err := tx.WithSignature(signer, signature)
 	if err != nil {
 		return nil, nil, "", "", errors.New("tx with signature fail")
 	}
-	signedTxData, err := rlp.EncodeToBytes(signedTx)
+	signedTxData, err := signedTx.MarshalBinary()
 	if err != nil {
 		return nil, nil, "", "", errors.New("encode tx to byte fail")
 	}
-	return signer, signedTx, "0x" + hex.EncodeToString(signedTxData)[4:], signedTx.Hash().String(), nil
+	return signer, signedTx, "0x" + hex.EncodeToString(signedTxData), signedTx.Hash().String(), nil
 }
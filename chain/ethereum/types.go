@@ -1,31 +1,101 @@
 package ethereum
 
+import (
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// tx_type values, an empty tx_type is a dynamic fee transaction
+const (
+	TxTypeLegacy     = "legacy"
+	TxTypeAccessList = "access_list"
+	TxTypeDynamicFee = "dynamic_fee"
+	TxTypeBlob       = "blob"
+	TxTypeSetCode    = "set_code"
+	// TxTypeAuthorization signs the single EIP-7702 authorization of
+	// authorization_list instead of a transaction
+	TxTypeAuthorization = "authorization"
+)
+
+// asset_type values, an empty asset_type is a native or erc20 transfer picked
+// by contract_address
+const (
+	AssetTypeNative       = "native"
+	AssetTypeErc20        = "erc20"
+	AssetTypeErc721       = "erc721"
+	AssetTypeErc1155      = "erc1155"
+	AssetTypeContractCall = "contract_call"
+)
+
+func isSupportedAssetType(assetType string) bool {
+	switch assetType {
+	case "", AssetTypeNative, AssetTypeErc20, AssetTypeErc721, AssetTypeErc1155, AssetTypeContractCall:
+		return true
+	}
+	return false
+}
+
+// Eip1559DynamicFeeTx is the transaction request body of every tx_type, the
+// legacy and access list types take gas_price instead of the 1559 fee caps.
+// A contract_call sends value and hex data to to_address, or creates a
+// contract when to_address is empty. An erc721 or erc1155 asset_type sends
+// token_id, or token_ids with their amounts in an ERC-1155 batch, of
+// contract_address. Instead of hex data a contract_call may
+// name a function signature like approve(address,uint256) or a JSON abi
+// fragment, with method picking the function of a full abi, and the JSON args
+// to encode. A blob transaction adds max_fee_per_blob_gas and the blob
+// versioned hashes, or the hex blobs themselves to sign it with its sidecar.
+// A set_code transaction carries the EIP-7702 authorization_list, the co-approval
+// hash of the body covers every delegation target.
 type Eip1559DynamicFeeTx struct {
-	ChainId              string `json:"chain_id"`
-	Nonce                uint64 `json:"nonce"`
-	FromAddress          string `json:"from_address"`
-	ToAddress            string `json:"to_address"`
-	GasLimit             uint64 `json:"gas_limit"`
-	Gas                  uint64 `json:"Gas"`
-	MaxFeePerGas         string `json:"max_fee_per_gas"`
-	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas"`
-	Amount               string `json:"amount"`
-	ContractAddress      string `json:"contract_address"`
+	TxType               string           `json:"tx_type"`
+	AssetType            string           `json:"asset_type"`
+	ChainId              string           `json:"chain_id"`
+	Nonce                uint64           `json:"nonce"`
+	FromAddress          string           `json:"from_address"`
+	ToAddress            string           `json:"to_address"`
+	GasLimit             uint64           `json:"gas_limit"`
+	Gas                  uint64           `json:"Gas"`
+	GasPrice             string           `json:"gas_price,omitempty"`
+	MaxFeePerGas         string           `json:"max_fee_per_gas"`
+	MaxPriorityFeePerGas string           `json:"max_priority_fee_per_gas"`
+	Amount               string           `json:"amount"`
+	ContractAddress      string           `json:"contract_address"`
+	TokenId              string           `json:"token_id,omitempty"`
+	TokenIds             []string         `json:"token_ids,omitempty"`
+	Amounts              []string         `json:"amounts,omitempty"`
+	Value                string           `json:"value,omitempty"`
+	Data                 string           `json:"data,omitempty"`
+	Function             string           `json:"function,omitempty"`
+	Abi                  json.RawMessage  `json:"abi,omitempty"`
+	Method               string           `json:"method,omitempty"`
+	Args                 json.RawMessage  `json:"args,omitempty"`
+	AccessList           types.AccessList `json:"access_list,omitempty"`
+	MaxFeePerBlobGas     string           `json:"max_fee_per_blob_gas,omitempty"`
+	BlobVersionedHashes  []string         `json:"blob_versioned_hashes,omitempty"`
+	Blobs                []string         `json:"blobs,omitempty"`
+	BlobSidecarVersion   byte             `json:"blob_sidecar_version,omitempty"`
+	AuthorizationList    []Authorization  `json:"authorization_list,omitempty"`
 }
 
 type LegacyFeeTx struct {
-	ChainId         string `json:"chain_id"`
-	Nonce           uint64 `json:"nonce"`
-	FromAddress     string `json:"from_address"`
-	ToAddress       string `json:"to_address"`
-	GasLimit        uint64 `json:"gas_limit"`
-	GasPrice        uint64 `json:"gas_price"`
-	Amount          string `json:"amount"`
-	ContractAddress string `json:"contract_address"`
+	TxType          string           `json:"tx_type"`
+	ChainId         string           `json:"chain_id"`
+	Nonce           uint64           `json:"nonce"`
+	FromAddress     string           `json:"from_address"`
+	ToAddress       string           `json:"to_address"`
+	GasLimit        uint64           `json:"gas_limit"`
+	GasPrice        string           `json:"gas_price"`
+	Amount          string           `json:"amount"`
+	ContractAddress string           `json:"contract_address"`
+	AccessList      types.AccessList `json:"access_list,omitempty"`
 }
 
 type EthereumSchema struct {
 	RequestId    string              `json:"request_id"`
 	DynamicFeeTx Eip1559DynamicFeeTx `json:"dynamic_fee_tx"`
 	ClassicFeeTx LegacyFeeTx         `json:"classic_fee_tx"`
+	SetCodeTx    Eip1559DynamicFeeTx `json:"set_code_tx"`
+	BlobTx       Eip1559DynamicFeeTx `json:"blob_tx"`
 }
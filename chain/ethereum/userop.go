@@ -0,0 +1,372 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// entry_point_version values
+const (
+	EntryPointV06 = "v0.6"
+	EntryPointV07 = "v0.7"
+)
+
+// EntryPoint contracts, entry_point_version may be omitted for these
+const (
+	EntryPointV06Address = "0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789"
+	EntryPointV07Address = "0x0000000071727De22E5E9d8BAf0edAc6f37da032"
+)
+
+// sign_mode values, eth_sign signs the EIP-191 prefixed userOpHash like
+// SimpleAccount and most ECDSA accounts verify it.
+const (
+	UserOpSignModeEthSign = "eth_sign"
+	UserOpSignModeRaw     = "raw"
+)
+
+// UserOperationRequest is the user operation request body.
+type UserOperationRequest struct {
+	EntryPoint        string        `json:"entry_point"`
+	EntryPointVersion string        `json:"entry_point_version,omitempty"`
+	ChainId           string        `json:"chain_id"`
+	SignMode          string        `json:"sign_mode,omitempty"`
+	UserOperation     UserOperation `json:"user_operation"`
+}
+
+// UserOperation accepts a v0.6 UserOperation, and a v0.7 user operation either
+// in the unpacked rpc form or as a PackedUserOperation with accountGasLimits,
+// gasFees, initCode and paymasterAndData.
+type UserOperation struct {
+	Sender                        common.Address  `json:"sender"`
+	Nonce                         *hexutil.Big    `json:"nonce"`
+	InitCode                      hexutil.Bytes   `json:"initCode,omitempty"`
+	Factory                       *common.Address `json:"factory,omitempty"`
+	FactoryData                   hexutil.Bytes   `json:"factoryData,omitempty"`
+	CallData                      hexutil.Bytes   `json:"callData"`
+	AccountGasLimits              hexutil.Bytes   `json:"accountGasLimits,omitempty"`
+	CallGasLimit                  *hexutil.Big    `json:"callGasLimit,omitempty"`
+	VerificationGasLimit          *hexutil.Big    `json:"verificationGasLimit,omitempty"`
+	PreVerificationGas            *hexutil.Big    `json:"preVerificationGas"`
+	GasFees                       hexutil.Bytes   `json:"gasFees,omitempty"`
+	MaxFeePerGas                  *hexutil.Big    `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas          *hexutil.Big    `json:"maxPriorityFeePerGas,omitempty"`
+	PaymasterAndData              hexutil.Bytes   `json:"paymasterAndData,omitempty"`
+	Paymaster                     *common.Address `json:"paymaster,omitempty"`
+	PaymasterVerificationGasLimit *hexutil.Big    `json:"paymasterVerificationGasLimit,omitempty"`
+	PaymasterPostOpGasLimit       *hexutil.Big    `json:"paymasterPostOpGasLimit,omitempty"`
+	PaymasterData                 hexutil.Bytes   `json:"paymasterData,omitempty"`
+	Signature                     hexutil.Bytes   `json:"signature"`
+}
+
+// userOperationV06 is the eth_sendUserOperation form of a v0.6 user operation
+type userOperationV06 struct {
+	Sender               common.Address `json:"sender"`
+	Nonce                *hexutil.Big   `json:"nonce"`
+	InitCode             hexutil.Bytes  `json:"initCode"`
+	CallData             hexutil.Bytes  `json:"callData"`
+	CallGasLimit         *hexutil.Big   `json:"callGasLimit"`
+	VerificationGasLimit *hexutil.Big   `json:"verificationGasLimit"`
+	PreVerificationGas   *hexutil.Big   `json:"preVerificationGas"`
+	MaxFeePerGas         *hexutil.Big   `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas"`
+	PaymasterAndData     hexutil.Bytes  `json:"paymasterAndData"`
+	Signature            hexutil.Bytes  `json:"signature"`
+}
+
+// userOperationV07 is the eth_sendUserOperation form of a v0.7 user operation
+type userOperationV07 struct {
+	Sender                        common.Address  `json:"sender"`
+	Nonce                         *hexutil.Big    `json:"nonce"`
+	Factory                       *common.Address `json:"factory,omitempty"`
+	FactoryData                   hexutil.Bytes   `json:"factoryData,omitempty"`
+	CallData                      hexutil.Bytes   `json:"callData"`
+	CallGasLimit                  *hexutil.Big    `json:"callGasLimit"`
+	VerificationGasLimit          *hexutil.Big    `json:"verificationGasLimit"`
+	PreVerificationGas            *hexutil.Big    `json:"preVerificationGas"`
+	MaxFeePerGas                  *hexutil.Big    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas          *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	Paymaster                     *common.Address `json:"paymaster,omitempty"`
+	PaymasterVerificationGasLimit *hexutil.Big    `json:"paymasterVerificationGasLimit,omitempty"`
+	PaymasterPostOpGasLimit       *hexutil.Big    `json:"paymasterPostOpGasLimit,omitempty"`
+	PaymasterData                 hexutil.Bytes   `json:"paymasterData,omitempty"`
+	Signature                     hexutil.Bytes   `json:"signature"`
+}
+
+// BuildAndSignUserOperation computes the EntryPoint getUserOpHash of an
+// ERC-4337 user operation, signs it and returns the user operation with its
+// signature set, ready for eth_sendUserOperation.
+func (c *ChainAdaptor) BuildAndSignUserOperation(ctx context.Context, req *wallet.BuildAndSignUserOperationRequest) (*wallet.BuildAndSignUserOperationResponse, error) {
+	resp := &wallet.BuildAndSignUserOperationResponse{
+		Code: wallet.ReturnCode_ERROR,
+	}
+	userOpJsonByte, err := base64.StdEncoding.DecodeString(req.UserOpBase64Body)
+	if err != nil {
+		log.Error("decode string fail", "err", err)
+		resp.Message = "decode base64 string fail"
+		return resp, nil
+	}
+	var userOpReq UserOperationRequest
+	if err := json.Unmarshal(userOpJsonByte, &userOpReq); err != nil {
+		log.Error("parse json fail", "err", err)
+		resp.Message = "parse user operation fail: " + err.Error()
+		return resp, nil
+	}
+	userOpHash, sendForm, err := userOpReq.hash()
+	if err != nil {
+		log.Error("hash user operation fail", "err", err)
+		resp.Message = "hash user operation fail: " + err.Error()
+		return resp, nil
+	}
+	digest := userOpHash
+	switch userOpReq.SignMode {
+	case UserOpSignModeEthSign, "":
+		digest = accounts.TextHash(userOpHash)
+	case UserOpSignModeRaw:
+	default:
+		resp.Message = "unsupported sign mode: " + userOpReq.SignMode
+		return resp, nil
+	}
+	signature, err := c.signDigest(req.PublicKey, digest)
+	if err != nil {
+		log.Error("sign user operation fail", "err", err)
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	sig, _ := hexutil.Decode(signature)
+	switch userOp := sendForm.(type) {
+	case *userOperationV06:
+		userOp.Signature = sig
+	case *userOperationV07:
+		userOp.Signature = sig
+	}
+	userOperation, err := json.Marshal(sendForm)
+	if err != nil {
+		log.Error("marshal user operation fail", "err", err)
+		resp.Message = "marshal user operation fail"
+		return resp, nil
+	}
+	log.Info("sign user operation success",
+		"sender", userOpReq.UserOperation.Sender,
+		"entryPoint", userOpReq.EntryPoint,
+		"userOpHash", hexutil.Encode(userOpHash),
+	)
+	resp.Code = wallet.ReturnCode_SUCCESS
+	resp.Message = "sign user operation success"
+	resp.UserOpHash = hexutil.Encode(userOpHash)
+	resp.Signature = signature
+	resp.UserOperation = string(userOperation)
+	return resp, nil
+}
+
+// hash returns keccak256(abi.encode(keccak256(pack(userOp)), entryPoint, chainId))
+// and the eth_sendUserOperation form of the user operation.
+func (r *UserOperationRequest) hash() ([]byte, interface{}, error) {
+	entryPoint, err := parseAddress("entry point", r.EntryPoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	chainID, err := parseBigInt("chain ID", r.ChainId)
+	if err != nil {
+		return nil, nil, err
+	}
+	version := r.EntryPointVersion
+	if version == "" {
+		switch entryPoint {
+		case common.HexToAddress(EntryPointV06Address):
+			version = EntryPointV06
+		case common.HexToAddress(EntryPointV07Address):
+			version = EntryPointV07
+		default:
+			return nil, nil, errors.New("entry_point_version is required for an unknown entry point")
+		}
+	}
+	userOp := &r.UserOperation
+	if userOp.Nonce == nil || userOp.PreVerificationGas == nil {
+		return nil, nil, errors.New("nonce and preVerificationGas are required")
+	}
+	var packed []byte
+	var sendForm interface{}
+	switch version {
+	case EntryPointV06:
+		packed, sendForm, err = userOp.packV06()
+	case EntryPointV07:
+		packed, sendForm, err = userOp.packV07()
+	default:
+		return nil, nil, fmt.Errorf("unsupported entry point version: %s", version)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	userOpHash := crypto.Keccak256(crypto.Keccak256(packed), abiWord(entryPoint.Bytes()), abiWord(chainID.Bytes()))
+	return userOpHash, sendForm, nil
+}
+
+func (u *UserOperation) packV06() ([]byte, *userOperationV06, error) {
+	if u.AccountGasLimits != nil || u.GasFees != nil || u.Factory != nil || u.Paymaster != nil {
+		return nil, nil, errors.New("v0.7 fields in a v0.6 user operation")
+	}
+	if u.CallGasLimit == nil || u.VerificationGasLimit == nil || u.MaxFeePerGas == nil || u.MaxPriorityFeePerGas == nil {
+		return nil, nil, errors.New("gas limits and fees are required")
+	}
+	packed := concatWords(
+		abiWord(u.Sender.Bytes()),
+		abiWord(u.Nonce.ToInt().Bytes()),
+		crypto.Keccak256(u.InitCode),
+		crypto.Keccak256(u.CallData),
+		abiWord(u.CallGasLimit.ToInt().Bytes()),
+		abiWord(u.VerificationGasLimit.ToInt().Bytes()),
+		abiWord(u.PreVerificationGas.ToInt().Bytes()),
+		abiWord(u.MaxFeePerGas.ToInt().Bytes()),
+		abiWord(u.MaxPriorityFeePerGas.ToInt().Bytes()),
+		crypto.Keccak256(u.PaymasterAndData),
+	)
+	return packed, &userOperationV06{
+		Sender:               u.Sender,
+		Nonce:                u.Nonce,
+		InitCode:             nonNilBytes(u.InitCode),
+		CallData:             nonNilBytes(u.CallData),
+		CallGasLimit:         u.CallGasLimit,
+		VerificationGasLimit: u.VerificationGasLimit,
+		PreVerificationGas:   u.PreVerificationGas,
+		MaxFeePerGas:         u.MaxFeePerGas,
+		MaxPriorityFeePerGas: u.MaxPriorityFeePerGas,
+		PaymasterAndData:     nonNilBytes(u.PaymasterAndData),
+	}, nil
+}
+
+func (u *UserOperation) packV07() ([]byte, *userOperationV07, error) {
+	if err := u.unpackV07(); err != nil {
+		return nil, nil, err
+	}
+	if u.CallGasLimit == nil || u.VerificationGasLimit == nil || u.MaxFeePerGas == nil || u.MaxPriorityFeePerGas == nil {
+		return nil, nil, errors.New("gas limits and fees are required")
+	}
+	for name, value := range map[string]*hexutil.Big{
+		"callGasLimit":                  u.CallGasLimit,
+		"verificationGasLimit":          u.VerificationGasLimit,
+		"maxFeePerGas":                  u.MaxFeePerGas,
+		"maxPriorityFeePerGas":          u.MaxPriorityFeePerGas,
+		"paymasterVerificationGasLimit": u.PaymasterVerificationGasLimit,
+		"paymasterPostOpGasLimit":       u.PaymasterPostOpGasLimit,
+	} {
+		if value != nil && value.ToInt().BitLen() > 128 {
+			return nil, nil, fmt.Errorf("%s overflows uint128", name)
+		}
+	}
+	var initCode []byte
+	if u.Factory != nil {
+		initCode = append(u.Factory.Bytes(), u.FactoryData...)
+	}
+	var paymasterAndData []byte
+	sendForm := &userOperationV07{
+		Sender:               u.Sender,
+		Nonce:                u.Nonce,
+		CallData:             nonNilBytes(u.CallData),
+		CallGasLimit:         u.CallGasLimit,
+		VerificationGasLimit: u.VerificationGasLimit,
+		PreVerificationGas:   u.PreVerificationGas,
+		MaxFeePerGas:         u.MaxFeePerGas,
+		MaxPriorityFeePerGas: u.MaxPriorityFeePerGas,
+	}
+	if u.Factory != nil {
+		sendForm.Factory = u.Factory
+		sendForm.FactoryData = nonNilBytes(u.FactoryData)
+	}
+	if u.Paymaster != nil {
+		if u.PaymasterVerificationGasLimit == nil || u.PaymasterPostOpGasLimit == nil {
+			return nil, nil, errors.New("paymaster gas limits are required")
+		}
+		paymasterAndData = append(u.Paymaster.Bytes(), uint128Bytes(u.PaymasterVerificationGasLimit)...)
+		paymasterAndData = append(paymasterAndData, uint128Bytes(u.PaymasterPostOpGasLimit)...)
+		paymasterAndData = append(paymasterAndData, u.PaymasterData...)
+		sendForm.Paymaster = u.Paymaster
+		sendForm.PaymasterVerificationGasLimit = u.PaymasterVerificationGasLimit
+		sendForm.PaymasterPostOpGasLimit = u.PaymasterPostOpGasLimit
+		sendForm.PaymasterData = nonNilBytes(u.PaymasterData)
+	}
+	accountGasLimits := append(uint128Bytes(u.VerificationGasLimit), uint128Bytes(u.CallGasLimit)...)
+	gasFees := append(uint128Bytes(u.MaxPriorityFeePerGas), uint128Bytes(u.MaxFeePerGas)...)
+	packed := concatWords(
+		abiWord(u.Sender.Bytes()),
+		abiWord(u.Nonce.ToInt().Bytes()),
+		crypto.Keccak256(initCode),
+		crypto.Keccak256(u.CallData),
+		accountGasLimits,
+		abiWord(u.PreVerificationGas.ToInt().Bytes()),
+		gasFees,
+		crypto.Keccak256(paymasterAndData),
+	)
+	return packed, sendForm, nil
+}
+
+// unpackV07 fills the unpacked v0.7 fields from the PackedUserOperation ones.
+func (u *UserOperation) unpackV07() error {
+	if u.AccountGasLimits != nil || u.GasFees != nil {
+		if len(u.AccountGasLimits) != 32 || len(u.GasFees) != 32 {
+			return errors.New("accountGasLimits and gasFees must be 32 bytes")
+		}
+		if u.CallGasLimit != nil || u.VerificationGasLimit != nil || u.MaxFeePerGas != nil || u.MaxPriorityFeePerGas != nil {
+			return errors.New("packed and unpacked gas fields are exclusive")
+		}
+		u.VerificationGasLimit = (*hexutil.Big)(new(big.Int).SetBytes(u.AccountGasLimits[:16]))
+		u.CallGasLimit = (*hexutil.Big)(new(big.Int).SetBytes(u.AccountGasLimits[16:]))
+		u.MaxPriorityFeePerGas = (*hexutil.Big)(new(big.Int).SetBytes(u.GasFees[:16]))
+		u.MaxFeePerGas = (*hexutil.Big)(new(big.Int).SetBytes(u.GasFees[16:]))
+	}
+	if len(u.InitCode) != 0 {
+		if u.Factory != nil {
+			return errors.New("initCode and factory are exclusive")
+		}
+		if len(u.InitCode) < common.AddressLength {
+			return errors.New("initCode shorter than a factory address")
+		}
+		factory := common.BytesToAddress(u.InitCode[:common.AddressLength])
+		u.Factory = &factory
+		u.FactoryData = u.InitCode[common.AddressLength:]
+	}
+	if len(u.PaymasterAndData) != 0 {
+		if u.Paymaster != nil {
+			return errors.New("paymasterAndData and paymaster are exclusive")
+		}
+		if len(u.PaymasterAndData) < common.AddressLength+32 {
+			return errors.New("paymasterAndData shorter than paymaster and gas limits")
+		}
+		paymaster := common.BytesToAddress(u.PaymasterAndData[:common.AddressLength])
+		u.Paymaster = &paymaster
+		u.PaymasterVerificationGasLimit = (*hexutil.Big)(new(big.Int).SetBytes(u.PaymasterAndData[20:36]))
+		u.PaymasterPostOpGasLimit = (*hexutil.Big)(new(big.Int).SetBytes(u.PaymasterAndData[36:52]))
+		u.PaymasterData = u.PaymasterAndData[52:]
+	}
+	return nil
+}
+
+func abiWord(b []byte) []byte {
+	return common.LeftPadBytes(b, 32)
+}
+
+func uint128Bytes(n *hexutil.Big) []byte {
+	return common.LeftPadBytes(n.ToInt().Bytes(), 16)
+}
+
+func concatWords(words ...[]byte) []byte {
+	return bytes.Join(words, nil)
+}
+
+func nonNilBytes(b hexutil.Bytes) hexutil.Bytes {
+	if b == nil {
+		return hexutil.Bytes{}
+	}
+	return b
+}
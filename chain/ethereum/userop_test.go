@@ -0,0 +1,130 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// entryPointUserOpHash is EntryPoint.getUserOpHash, the abi.encode of the
+// packed user operation hash with the entry point and chain id.
+func entryPointUserOpHash(t *testing.T, types []string, values []interface{}, entryPoint common.Address, chainID int64) []byte {
+	t.Helper()
+	pack := func(types []string, values ...interface{}) []byte {
+		var args abi.Arguments
+		for _, typ := range types {
+			abiType, err := abi.NewType(typ, "", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			args = append(args, abi.Argument{Type: abiType})
+		}
+		packed, err := args.Pack(values...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return packed
+	}
+	packedUserOp := crypto.Keccak256(pack(types, values...))
+	return crypto.Keccak256(pack([]string{"bytes32", "address", "uint256"}, common.BytesToHash(packedUserOp), entryPoint, big.NewInt(chainID)))
+}
+
+// v0.6 UserOperationLib.pack
+var userOpV06Types = []string{"address", "uint256", "bytes32", "bytes32", "uint256", "uint256", "uint256", "uint256", "uint256", "bytes32"}
+
+// v0.7 UserOperationLib.encode of a PackedUserOperation
+var userOpV07Types = []string{"address", "uint256", "bytes32", "bytes32", "bytes32", "uint256", "bytes32", "bytes32"}
+
+func TestBuildAndSignUserOperationHash(t *testing.T) {
+	sender := common.HexToAddress("0x1306b01bC3e4AD202612D3843387e94737673F53")
+	factory := common.HexToAddress("0x9406Cc6185a346906296840746125a0E44976454")
+	paymaster := common.HexToAddress("0x5555555555555555555555555555555555555555")
+	callData := hexutil.MustDecode("0xb61d27f60000000000000000000000002222222222222222222222222222222222222222000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000600000000000000000000000000000000000000000000000000000000000000000")
+	factoryData := hexutil.MustDecode("0x5fbfb9cf00000000000000000000000022222222222222222222222222222222222222220000000000000000000000000000000000000000000000000000000000000000")
+	initCode := append(factory.Bytes(), factoryData...)
+	paymasterData := hexutil.MustDecode("0x1234")
+	// 16 byte verificationGasLimit || callGasLimit and maxPriorityFeePerGas || maxFeePerGas
+	accountGasLimits := common.HexToHash("0x00000000000000000000000000030d4000000000000000000000000000011170")
+	gasFees := common.HexToHash("0x0000000000000000000000003b9aca00000000000000000000000002540be400")
+	paymasterAndData := append(append(paymaster.Bytes(), hexutil.MustDecode("0x000000000000000000000000000186a000000000000000000000000000004e20")...), paymasterData...)
+	v06EntryPoint := common.HexToAddress(EntryPointV06Address)
+	v07EntryPoint := common.HexToAddress(EntryPointV07Address)
+	tests := []struct {
+		name       string
+		body       string
+		userOpHash []byte
+	}{
+		{
+			name: "v0.6 with init code",
+			body: `{"entry_point":"` + EntryPointV06Address + `","chain_id":"1","user_operation":{"sender":"` + sender.Hex() + `","nonce":"0x5","initCode":"` + hexutil.Encode(initCode) + `","callData":"` + hexutil.Encode(callData) + `","callGasLimit":"0x11170","verificationGasLimit":"0x30d40","preVerificationGas":"0xb5fc","maxFeePerGas":"0x2540be400","maxPriorityFeePerGas":"0x3b9aca00","paymasterAndData":"0x","signature":"0x"}}`,
+			userOpHash: entryPointUserOpHash(t, userOpV06Types, []interface{}{
+				sender, big.NewInt(5), common.BytesToHash(crypto.Keccak256(initCode)), common.BytesToHash(crypto.Keccak256(callData)),
+				big.NewInt(0x11170), big.NewInt(0x30d40), big.NewInt(0xb5fc), big.NewInt(0x2540be400), big.NewInt(0x3b9aca00),
+				common.BytesToHash(crypto.Keccak256(nil)),
+			}, v06EntryPoint, 1),
+		},
+		{
+			name: "v0.7 unpacked with paymaster",
+			body: `{"entry_point":"` + EntryPointV07Address + `","chain_id":"8453","user_operation":{"sender":"` + sender.Hex() + `","nonce":"0x5","factory":"` + factory.Hex() + `","factoryData":"` + hexutil.Encode(factoryData) + `","callData":"` + hexutil.Encode(callData) + `","callGasLimit":"0x11170","verificationGasLimit":"0x30d40","preVerificationGas":"0xb5fc","maxFeePerGas":"0x2540be400","maxPriorityFeePerGas":"0x3b9aca00","paymaster":"` + paymaster.Hex() + `","paymasterVerificationGasLimit":"0x186a0","paymasterPostOpGasLimit":"0x4e20","paymasterData":"` + hexutil.Encode(paymasterData) + `","signature":"0x"}}`,
+			userOpHash: entryPointUserOpHash(t, userOpV07Types, []interface{}{
+				sender, big.NewInt(5), common.BytesToHash(crypto.Keccak256(initCode)), common.BytesToHash(crypto.Keccak256(callData)),
+				accountGasLimits, big.NewInt(0xb5fc), gasFees, common.BytesToHash(crypto.Keccak256(paymasterAndData)),
+			}, v07EntryPoint, 8453),
+		},
+		{
+			name: "v0.7 packed with paymaster",
+			body: `{"entry_point":"` + EntryPointV07Address + `","chain_id":"8453","user_operation":{"sender":"` + sender.Hex() + `","nonce":"0x5","initCode":"` + hexutil.Encode(initCode) + `","callData":"` + hexutil.Encode(callData) + `","accountGasLimits":"` + accountGasLimits.Hex() + `","preVerificationGas":"0xb5fc","gasFees":"` + gasFees.Hex() + `","paymasterAndData":"` + hexutil.Encode(paymasterAndData) + `","signature":"0x"}}`,
+			userOpHash: entryPointUserOpHash(t, userOpV07Types, []interface{}{
+				sender, big.NewInt(5), common.BytesToHash(crypto.Keccak256(initCode)), common.BytesToHash(crypto.Keccak256(callData)),
+				accountGasLimits, big.NewInt(0xb5fc), gasFees, common.BytesToHash(crypto.Keccak256(paymasterAndData)),
+			}, v07EntryPoint, 8453),
+		},
+	}
+	c, publicKey := newTestAdaptor(t, cowPrivateKey)
+	owner, err := publicKeyToAddress(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := c.BuildAndSignUserOperation(context.Background(), &wallet.BuildAndSignUserOperationRequest{
+				PublicKey:        publicKey,
+				UserOpBase64Body: base64.StdEncoding.EncodeToString([]byte(tt.body)),
+			})
+			if err != nil || resp.Code != wallet.ReturnCode_SUCCESS {
+				t.Fatalf("sign user operation fail: %v %s", err, resp.GetMessage())
+			}
+			if resp.UserOpHash != hexutil.Encode(tt.userOpHash) {
+				t.Fatalf("user op hash %s, want %s", resp.UserOpHash, hexutil.Encode(tt.userOpHash))
+			}
+			var userOp struct {
+				Signature hexutil.Bytes `json:"signature"`
+			}
+			if err := json.Unmarshal([]byte(resp.UserOperation), &userOp); err != nil {
+				t.Fatal(err)
+			}
+			if hexutil.Encode(userOp.Signature) != resp.Signature {
+				t.Fatalf("user operation signature %x, want %s", userOp.Signature, resp.Signature)
+			}
+			// eth_sign mode signs the EIP-191 prefixed user op hash
+			sig := hexutil.MustDecode(resp.Signature)
+			sig[crypto.RecoveryIDOffset] -= 27
+			pubKey, err := crypto.SigToPub(accounts.TextHash(tt.userOpHash), sig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if crypto.PubkeyToAddress(*pubKey) != owner {
+				t.Fatalf("signed by %s, want %s", crypto.PubkeyToAddress(*pubKey), owner)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DQYXACML/wallet-sign/leveldb"
+	"github.com/DQYXACML/wallet-sign/ssm"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// MaxKeyNum caps the key pairs created by a single request
+const MaxKeyNum = 10000
+
+// KeyPair is a created key ready to be stored, with the compressed public key
+// the rpc responses export next to the full one.
+type KeyPair struct {
+	leveldb.Key
+	CompressPubKey string
+}
+
+// CreateKeyPairs creates keyNum key pairs with the chain signer. When
+// pathTemplate is set, hd mode is on and the keys are derived from the sealed
+// seed at the next free child indexes of the template, e.g. m/44'/60'/0'/0/%d.
+// The indexes are reserved until the keys are stored, a caller that does not
+// store them must give them back with ReleaseKeyPairs.
+func CreateKeyPairs(db *leveldb.Keys, signer ssm.Signer, pathTemplate string, keyNum uint64) ([]*KeyPair, error) {
+	if keyNum > MaxKeyNum {
+		return nil, fmt.Errorf("number must be less than %d", MaxKeyNum)
+	}
+	if pathTemplate == "" {
+		keyList := make([]*KeyPair, 0, keyNum)
+		for i := 0; i < int(keyNum); i++ {
+			priKeyStr, pubKeyStr, compressPubKeyStr, err := signer.CreateKeyPair()
+			if err != nil {
+				return nil, err
+			}
+			keyList = append(keyList, &KeyPair{
+				Key: leveldb.Key{
+					PrivateKey: priKeyStr,
+					PubKey:     pubKeyStr,
+				},
+				CompressPubKey: compressPubKeyStr,
+			})
+		}
+		return keyList, nil
+	}
+	hdSigner, ok := signer.(ssm.HDSigner)
+	if !ok {
+		return nil, errors.New("signer does not support hd derivation")
+	}
+	seed, err := db.GetSeed()
+	if err != nil {
+		log.Error("get hd seed fail", "err", err)
+		return nil, err
+	}
+	start, err := db.ReserveHDIndex(pathTemplate, uint32(keyNum))
+	if err != nil {
+		log.Error("reserve hd index fail", "err", err, "path", pathTemplate)
+		return nil, err
+	}
+	keyPairs, err := DeriveKeyPairs(hdSigner, seed, pathTemplate, start, uint32(keyNum))
+	if err != nil {
+		log.Error("derive key pairs fail", "err", err, "path", pathTemplate)
+		db.ReleaseHDIndex(pathTemplate, start, uint32(keyNum))
+		return nil, err
+	}
+	return keyPairs, nil
+}
+
+// ReleaseKeyPairs gives back the hd indexes of created key pairs that are not
+// stored, so the next request derives them again.
+func ReleaseKeyPairs(db *leveldb.Keys, keyPairs []*KeyPair) {
+	keyList := make([]leveldb.Key, 0, len(keyPairs))
+	for _, keyPair := range keyPairs {
+		keyList = append(keyList, keyPair.Key)
+	}
+	db.ReleaseHDIndexes(keyList)
+}
+
+// DeriveKeyPairs derives the keys at child indexes [start, start+num) of the
+// path template. Storing them advances the hd index of the template.
+func DeriveKeyPairs(signer ssm.HDSigner, seed []byte, pathTemplate string, start uint32, num uint32) ([]*KeyPair, error) {
+	keyList := make([]*KeyPair, 0, num)
+	for index := start; index < start+num; index++ {
+		path := fmt.Sprintf(pathTemplate, index)
+		priKeyStr, pubKeyStr, compressPubKeyStr, err := signer.DeriveKeyPair(seed, path)
+		if err != nil {
+			return nil, err
+		}
+		keyList = append(keyList, &KeyPair{
+			Key: leveldb.Key{
+				PrivateKey:   priKeyStr,
+				PubKey:       pubKeyStr,
+				Path:         path,
+				PathTemplate: pathTemplate,
+				Index:        index,
+			},
+			CompressPubKey: compressPubKeyStr,
+		})
+	}
+	return keyList, nil
+}
@@ -6,7 +6,9 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/DQYXACML/wallet-sign/chain"
+	"github.com/DQYXACML/wallet-sign/config"
 	"github.com/DQYXACML/wallet-sign/leveldb"
 	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
 	"github.com/DQYXACML/wallet-sign/ssm"
@@ -23,9 +25,15 @@ import (
 
 const ChainName = "Solana"
 
+// HDPathTemplate is the SLIP-10 derivation path of hd mode keys, the same
+// account path Phantom and solana-keygen use
+const HDPathTemplate = "m/44'/501'/%d'/0'"
+
 type ChainAdaptor struct {
-	db     *leveldb.Keys
-	signer ssm.Signer
+	db           *leveldb.Keys
+	signer       ssm.Signer
+	hdEnable     bool
+	batchWorkers int
 }
 
 func (c *ChainAdaptor) SignTransactionMessage(ctx context.Context, req *wallet.SignTransactionMessageRequest) (*wallet.SignTransactionMessageResponse, error) {
@@ -85,31 +93,26 @@ func (c *ChainAdaptor) CreateKeyPairsExportPublicKeyList(ctx context.Context, re
 	resp := &wallet.CreateKeyPairAndExportPublicKeyResponse{
 		Code: wallet.ReturnCode_ERROR,
 	}
-	if req.KeyNum > 10000 {
-		resp.Message = "Number must be less than 100000"
+	if req.KeyNum > chain.MaxKeyNum {
+		resp.Message = fmt.Sprintf("Number must be less than %d", chain.MaxKeyNum)
 		return resp, nil
 	}
 	var keyList []leveldb.Key
 	var retKeyList []*wallet.ExportPublicKey
 
-	for i := 0; i < int(req.KeyNum); i++ {
-		priKeyStr, pubKeyStr, compressPubKeyStr, err := c.signer.CreateKeyPair()
-		if err != nil {
-			if req.KeyNum > 10000 {
-				resp.Message = "create key pair fail"
-				return resp, nil
-			}
-		}
-		keyItem := leveldb.Key{
-			PrivateKey: priKeyStr,
-			PubKey:     pubKeyStr,
-		}
+	keyPairs, err := chain.CreateKeyPairs(c.db, c.signer, c.hdPathTemplate(), req.KeyNum)
+	if err != nil {
+		log.Error("create key pairs fail", "err", err)
+		resp.Message = "create key pair fail"
+		return resp, nil
+	}
+	for _, keyPair := range keyPairs {
 		pukItem := &wallet.ExportPublicKey{
-			PublicKey:         pubKeyStr,
-			CompressPublicKey: compressPubKeyStr,
+			PublicKey:         keyPair.PubKey,
+			CompressPublicKey: keyPair.CompressPubKey,
 		}
 		retKeyList = append(retKeyList, pukItem)
-		keyList = append(keyList, keyItem)
+		keyList = append(keyList, keyPair.Key)
 	}
 
 	isOk := c.db.StoreKeys(keyList)
@@ -128,36 +131,32 @@ func (c *ChainAdaptor) CreateKeyPairsWithAddresses(ctx context.Context, req *wal
 	resp := &wallet.CreateKeyPairsWithAddressesResponse{
 		Code: wallet.ReturnCode_ERROR,
 	}
-	if req.KeyNum > 10000 {
-		resp.Message = "Number must be less than 100000"
+	if req.KeyNum > chain.MaxKeyNum {
+		resp.Message = fmt.Sprintf("Number must be less than %d", chain.MaxKeyNum)
 		return resp, nil
 	}
 	var keyList []leveldb.Key
 	var retKeyList []*wallet.ExportPublicKeyWithAddress
-	for i := 0; i < int(req.KeyNum); i++ {
-		priKeyStr, pubKeyStr, compressPubKeyStr, err := c.signer.CreateKeyPair()
-		if err != nil {
-			if req.KeyNum > 10000 {
-				resp.Message = "create key pair fail"
-				return resp, nil
-			}
-		}
-		keyItem := leveldb.Key{
-			PrivateKey: priKeyStr,
-			PubKey:     pubKeyStr,
-		}
-		address, err := PubKeyHexToAddress(pubKeyStr)
+	keyPairs, err := chain.CreateKeyPairs(c.db, c.signer, c.hdPathTemplate(), req.KeyNum)
+	if err != nil {
+		log.Error("create key pairs fail", "err", err)
+		resp.Message = "create key pair fail"
+		return resp, nil
+	}
+	for _, keyPair := range keyPairs {
+		address, err := PubKeyHexToAddress(keyPair.PubKey)
 		if err != nil {
+			chain.ReleaseKeyPairs(c.db, keyPairs)
 			resp.Message = "public key to address fail"
 			return resp, nil
 		}
 		pukItem := &wallet.ExportPublicKeyWithAddress{
-			PublicKey:         pubKeyStr,
-			CompressPublicKey: compressPubKeyStr,
+			PublicKey:         keyPair.PubKey,
+			CompressPublicKey: keyPair.CompressPubKey,
 			Address:           address,
 		}
 		retKeyList = append(retKeyList, pukItem)
-		keyList = append(keyList, keyItem)
+		keyList = append(keyList, keyPair.Key)
 	}
 	isOk := c.db.StoreKeys(keyList)
 	if !isOk {
@@ -250,6 +249,11 @@ func (c *ChainAdaptor) BuildAndSignTransaction(ctx context.Context, req *wallet.
 			)
 		}
 	}
+	if err != nil || tx == nil {
+		log.Error("build transaction fail", "err", err)
+		resp.Message = "build transaction fail"
+		return resp, nil
+	}
 	log.Info("Transaction:", tx.String())
 	txm, _ := tx.Message.MarshalBinary()
 	signingMessageHex := hex.EncodeToString(txm)
@@ -264,16 +268,14 @@ func (c *ChainAdaptor) BuildAndSignTransaction(ctx context.Context, req *wallet.
 		resp.Message = "sign message hash fail"
 		return resp, nil
 	}
-	if len(txSignatures) == 0 {
-		tx.Signatures = make([]solana.Signature, 1)
-	}
-	if len(txSignatures) != 64 {
+	txSignatureBytes, err := hex.DecodeString(txSignatures)
+	if err != nil || len(txSignatureBytes) != solana.SignatureLength {
 		resp.Message = "Invalid signature length"
 		return resp, nil
 	}
 	var solanaSig solana.Signature
-	copy(solanaSig[:], txSignatures)
-	tx.Signatures[0] = solanaSig
+	copy(solanaSig[:], txSignatureBytes)
+	tx.Signatures = []solana.Signature{solanaSig}
 	spew.Dump(tx)
 
 	if err := tx.VerifySignatures(); err != nil {
@@ -294,11 +296,84 @@ func (c *ChainAdaptor) BuildAndSignTransaction(ctx context.Context, req *wallet.
 }
 
 func (c *ChainAdaptor) BuildAndSignBatchTransaction(ctx context.Context, req *wallet.BuildAndSignBatchTransactionRequest) (*wallet.BuildAndSignBatchTransactionResponse, error) {
-	resp := &wallet.BuildAndSignBatchTransactionResponse{
-		Code: wallet.ReturnCode_ERROR,
-	}
+	return chain.BuildAndSignBatch(ctx, req, c.batchWorkers, c.BuildAndSignTransaction), nil
+}
 
-	return resp, nil
+func (c *ChainAdaptor) SignPersonalMessage(ctx context.Context, req *wallet.SignPersonalMessageRequest) (*wallet.SignPersonalMessageResponse, error) {
+	return &wallet.SignPersonalMessageResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
+}
+
+func (c *ChainAdaptor) SignTypedData(ctx context.Context, req *wallet.SignTypedDataRequest) (*wallet.SignTypedDataResponse, error) {
+	return &wallet.SignTypedDataResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
+}
+
+func (c *ChainAdaptor) BuildAndSignPermit(ctx context.Context, req *wallet.BuildAndSignPermitRequest) (*wallet.BuildAndSignPermitResponse, error) {
+	return &wallet.BuildAndSignPermitResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
+}
+
+func (c *ChainAdaptor) BuildAndSignUserOperation(ctx context.Context, req *wallet.BuildAndSignUserOperationRequest) (*wallet.BuildAndSignUserOperationResponse, error) {
+	return &wallet.BuildAndSignUserOperationResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
+}
+
+func (c *ChainAdaptor) BuildAndSignSafeTransaction(ctx context.Context, req *wallet.BuildAndSignSafeTransactionRequest) (*wallet.BuildAndSignSafeTransactionResponse, error) {
+	return &wallet.BuildAndSignSafeTransactionResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
+}
+
+func (c *ChainAdaptor) SignPsbt(ctx context.Context, req *wallet.SignPsbtRequest) (*wallet.SignPsbtResponse, error) {
+	return &wallet.SignPsbtResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
+}
+
+func (c *ChainAdaptor) CreateMultisigAddress(ctx context.Context, req *wallet.CreateMultisigAddressRequest) (*wallet.CreateMultisigAddressResponse, error) {
+	return &wallet.CreateMultisigAddressResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
+}
+
+func (c *ChainAdaptor) CreateMusig2Address(ctx context.Context, req *wallet.CreateMusig2AddressRequest) (*wallet.CreateMusig2AddressResponse, error) {
+	return &wallet.CreateMusig2AddressResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
+}
+
+func (c *ChainAdaptor) Musig2Nonce(ctx context.Context, req *wallet.Musig2NonceRequest) (*wallet.Musig2NonceResponse, error) {
+	return &wallet.Musig2NonceResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
+}
+
+func (c *ChainAdaptor) Musig2PartialSign(ctx context.Context, req *wallet.Musig2PartialSignRequest) (*wallet.Musig2PartialSignResponse, error) {
+	return &wallet.Musig2PartialSignResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
+}
+
+func (c *ChainAdaptor) CreateTaprootAddress(ctx context.Context, req *wallet.CreateTaprootAddressRequest) (*wallet.CreateTaprootAddressResponse, error) {
+	return &wallet.CreateTaprootAddressResponse{
+		Code:    wallet.ReturnCode_ERROR,
+		Message: config.UnsupportedOperation,
+	}, nil
 }
 
 func isSOLTransfer(coinAddress string) bool {
@@ -306,9 +381,23 @@ func isSOLTransfer(coinAddress string) bool {
 		coinAddress == "So11111111111111111111111111111111111111112"
 }
 
-func NewChainAdaptor(db *leveldb.Keys) (chain.IChainAdaptor, error) {
+func (c *ChainAdaptor) hdPathTemplate() string {
+	if !c.hdEnable {
+		return ""
+	}
+	return HDPathTemplate
+}
+
+func NewChainAdaptor(conf *config.Config, db *leveldb.Keys) (chain.IChainAdaptor, error) {
+	signer, err := ssm.NewEdDSASigner(conf)
+	if err != nil {
+		log.Error("new signer fail", "err", err)
+		return nil, err
+	}
 	return &ChainAdaptor{
-		db:     db,
-		signer: &ssm.EdDSASigner{},
+		db:           db,
+		signer:       signer,
+		hdEnable:     conf.HdEnable,
+		batchWorkers: conf.BatchWorkers,
 	}, nil
 }
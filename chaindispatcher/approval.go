@@ -0,0 +1,105 @@
+package chaindispatcher
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/DQYXACML/wallet-sign/config"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	ErrUnknownKeyVersion = errors.New("unknown key version")
+	ErrKeyHashMismatch   = errors.New("key hash mismatch")
+)
+
+// hashKeys holds every active version of a co-approval secret, several
+// versions are accepted at once so secrets can be rotated without downtime.
+type hashKeys map[string][]byte
+
+func newHashKeys(name string, keyConfigs []config.HashKeyConfig) (hashKeys, error) {
+	if len(keyConfigs) == 0 {
+		return nil, fmt.Errorf("no %s configured", name)
+	}
+	keys := make(hashKeys, len(keyConfigs))
+	for _, kc := range keyConfigs {
+		if kc.Version == "" {
+			return nil, fmt.Errorf("%s: version is required", name)
+		}
+		if _, ok := keys[kc.Version]; ok {
+			return nil, fmt.Errorf("%s: duplicate version %s", name, kc.Version)
+		}
+		secret := kc.Secret
+		if kc.SecretFile != "" {
+			data, err := os.ReadFile(kc.SecretFile)
+			if err != nil {
+				return nil, fmt.Errorf("%s: read secret file of version %s fail: %w", name, kc.Version, err)
+			}
+			secret = strings.TrimSpace(string(data))
+		}
+		if secret == "" {
+			return nil, fmt.Errorf("%s: empty secret for version %s", name, kc.Version)
+		}
+		keys[kc.Version] = []byte(secret)
+	}
+	return keys, nil
+}
+
+// verify checks that keyHash is the hex HMAC-SHA256 of body under the secret
+// of the given version.
+func (k hashKeys) verify(version string, body []byte, keyHash string) error {
+	secret, ok := k[version]
+	if !ok {
+		return ErrUnknownKeyVersion
+	}
+	expected, err := hex.DecodeString(strings.TrimPrefix(keyHash, "0x"))
+	if err != nil {
+		return ErrKeyHashMismatch
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrKeyHashMismatch
+	}
+	return nil
+}
+
+// approvalBody is the canonical body the risk and wallet key hashes of a
+// request sign over: the compact JSON object of the named fields with the
+// keys sorted and no whitespace, the values exactly as sent in the request.
+// For signTransactionMessage it is
+//
+//	{"chain_name":"Ethereum","message_hash":"0x..","network":"mainnet","public_key":"04.."}
+func approvalBody(fields map[string]string) []byte {
+	body, _ := json.Marshal(fields)
+	return body
+}
+
+// ApprovalRequest carries the wallet and risk control co-approval of the
+// body it signs.
+type ApprovalRequest interface {
+	GetWalletKeyHash() string
+	GetWalletKeyVersion() string
+	GetRiskKeyHash() string
+	GetRiskKeyVersion() string
+}
+
+// checkApproval returns the reason the co-approval of body is rejected, or
+// an empty string when both the risk and the wallet key hash are valid.
+func (c *ChainDispatcher) checkApproval(req ApprovalRequest, body []byte) string {
+	if err := c.riskKeys.verify(req.GetRiskKeyVersion(), body, req.GetRiskKeyHash()); err != nil {
+		log.Warn("risk key hash check fail", "err", err, "version", req.GetRiskKeyVersion())
+		return "riskKey hash check Fail: " + err.Error()
+	}
+	if err := c.walletKeys.verify(req.GetWalletKeyVersion(), body, req.GetWalletKeyHash()); err != nil {
+		log.Warn("wallet key hash check fail", "err", err, "version", req.GetWalletKeyVersion())
+		return "wallet key hash Check Fail: " + err.Error()
+	}
+	return ""
+}
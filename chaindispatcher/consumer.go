@@ -0,0 +1,90 @@
+package chaindispatcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/DQYXACML/wallet-sign/config"
+	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
+)
+
+const allowAll = "*"
+
+// rawSignMethods sign caller supplied hashes the risk control can not inspect
+var rawSignMethods = map[string]bool{
+	methodName(wallet.WalletService_SignTransactionMessage_FullMethodName): true,
+	methodName(wallet.WalletService_Musig2Nonce_FullMethodName):            true,
+	methodName(wallet.WalletService_Musig2PartialSign_FullMethodName):      true,
+}
+
+// Consumer is an authenticated rpc caller and what it may do.
+type Consumer struct {
+	Name       string
+	chains     map[string]bool
+	methods    map[string]bool
+	rawSign    bool
+	anySighash bool
+}
+
+func (c *Consumer) AllowChain(chainName string) bool {
+	return c.chains[allowAll] || c.chains[chainName]
+}
+
+func (c *Consumer) AllowMethod(method string) bool {
+	if rawSignMethods[method] {
+		return c.rawSign
+	}
+	return c.methods[allowAll] || c.methods[method]
+}
+
+// AllowAnySighash reports whether the consumer may sign psbt inputs with a
+// sighash other than SIGHASH_ALL or SIGHASH_DEFAULT.
+func (c *Consumer) AllowAnySighash() bool {
+	return c.anySighash
+}
+
+// newConsumers indexes the configured consumers by their token hash.
+func newConsumers(consumerConfigs []config.ConsumerConfig) (map[string]*Consumer, error) {
+	if len(consumerConfigs) == 0 {
+		return nil, errors.New("no consumers configured")
+	}
+	consumers := make(map[string]*Consumer, len(consumerConfigs))
+	for _, cc := range consumerConfigs {
+		tokenHash := strings.ToLower(strings.TrimPrefix(cc.TokenHash, "0x"))
+		if b, err := hex.DecodeString(tokenHash); err != nil || len(b) != sha256.Size {
+			return nil, fmt.Errorf("consumer %s: token_hash must be a hex sha256", cc.Name)
+		}
+		if _, ok := consumers[tokenHash]; ok {
+			return nil, fmt.Errorf("consumer %s: duplicate token_hash", cc.Name)
+		}
+		consumer := &Consumer{
+			Name:       cc.Name,
+			chains:     make(map[string]bool),
+			methods:    make(map[string]bool),
+			rawSign:    cc.AllowRawSign,
+			anySighash: cc.AllowAnySighash,
+		}
+		for _, chainName := range cc.Chains {
+			consumer.chains[chainName] = true
+		}
+		for _, method := range cc.Methods {
+			consumer.methods[method] = true
+		}
+		consumers[tokenHash] = consumer
+	}
+	return consumers, nil
+}
+
+func hashToken(consumerToken string) string {
+	sum := sha256.Sum256([]byte(consumerToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// methodName strips the service from a full grpc method name,
+// /wallet.WalletService/signTransactionMessage becomes signTransactionMessage.
+func methodName(fullMethod string) string {
+	return fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+}
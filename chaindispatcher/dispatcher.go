@@ -3,6 +3,8 @@ package chaindispatcher
 import (
 	"context"
 	"encoding/base64"
+	"errors"
+	"fmt"
 	"github.com/DQYXACML/wallet-sign/chain"
 	"github.com/DQYXACML/wallet-sign/chain/bitcoin"
 	"github.com/DQYXACML/wallet-sign/chain/ethereum"
@@ -10,23 +12,14 @@ import (
 	"github.com/DQYXACML/wallet-sign/config"
 	"github.com/DQYXACML/wallet-sign/leveldb"
 	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
-	"github.com/status-im/keycard-go/hexutils"
 	"google.golang.org/grpc"
 	"runtime/debug"
-	"strings"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
-const (
-	AccessToken string = "DappLinkTheWeb3202402290001"
-	WalletKey   string = "DappLinkWalletServicesRiskKeyxxxxxxxKey"
-	RisKKey     string = "DappLinkWalletServicesRiskKeyxxxxxxxKey"
-)
-
 type CommonRequest interface {
 	GetConsumerToken() string
 	GetChainName() string
@@ -35,11 +28,15 @@ type CommonRequest interface {
 type CommonReply = wallet.GetChainSignMethodResponse
 type ChainType = string
 type ChainDispatcher struct {
-	registry map[string]chain.IChainAdaptor
+	registry     map[string]chain.IChainAdaptor
+	consumers    map[string]*Consumer
+	walletKeys   hashKeys
+	riskKeys     hashKeys
+	maxBatchSize int
 }
 
 func (c *ChainDispatcher) GetChainSignMethod(ctx context.Context, request *wallet.GetChainSignMethodRequest) (*wallet.GetChainSignMethodResponse, error) {
-	resp := c.preHandler(request)
+	resp := c.preHandler(request, wallet.WalletService_GetChainSignMethod_FullMethodName)
 	if resp != nil {
 		return &wallet.GetChainSignMethodResponse{
 			Code: resp.Code,
@@ -50,7 +47,7 @@ func (c *ChainDispatcher) GetChainSignMethod(ctx context.Context, request *walle
 }
 
 func (c *ChainDispatcher) GetChainSchema(ctx context.Context, request *wallet.GetChainSchemaRequest) (*wallet.GetChainSchemaResponse, error) {
-	resp := c.preHandler(request)
+	resp := c.preHandler(request, wallet.WalletService_GetChainSchema_FullMethodName)
 	if resp != nil {
 		return &wallet.GetChainSchemaResponse{
 			Code:    resp.Code,
@@ -61,7 +58,7 @@ func (c *ChainDispatcher) GetChainSchema(ctx context.Context, request *wallet.Ge
 }
 
 func (c *ChainDispatcher) CreateKeyPairsExportPublicKeyList(ctx context.Context, request *wallet.CreateKeyPairAndExportPublicKeyRequest) (*wallet.CreateKeyPairAndExportPublicKeyResponse, error) {
-	resp := c.preHandler(request)
+	resp := c.preHandler(request, wallet.WalletService_CreateKeyPairsExportPublicKeyList_FullMethodName)
 	if resp != nil {
 		return &wallet.CreateKeyPairAndExportPublicKeyResponse{
 			Code:    resp.Code,
@@ -72,7 +69,7 @@ func (c *ChainDispatcher) CreateKeyPairsExportPublicKeyList(ctx context.Context,
 }
 
 func (c *ChainDispatcher) CreateKeyPairsWithAddresses(ctx context.Context, request *wallet.CreateKeyPairsWithAddressesRequest) (*wallet.CreateKeyPairsWithAddressesResponse, error) {
-	resp := c.preHandler(request)
+	resp := c.preHandler(request, wallet.WalletService_CreateKeyPairsWithAddresses_FullMethodName)
 	if resp != nil {
 		return &wallet.CreateKeyPairsWithAddressesResponse{
 			Code:    resp.Code,
@@ -83,61 +80,333 @@ func (c *ChainDispatcher) CreateKeyPairsWithAddresses(ctx context.Context, reque
 }
 
 func (c *ChainDispatcher) BuildAndSignTransaction(ctx context.Context, request *wallet.BuildAndSignTransactionRequest) (*wallet.BuildAndSignTransactionResponse, error) {
-	resp := c.preHandler(request)
+	resp := c.preHandler(request, wallet.WalletService_BuildAndSignTransaction_FullMethodName)
 	if resp != nil {
 		return &wallet.BuildAndSignTransactionResponse{
 			Code:    resp.Code,
 			Message: resp.Msg,
 		}, nil
 	}
-	txReqJsonByte, err := base64.StdEncoding.DecodeString(request.TxBase64Body)
-	if err != nil {
-		return &wallet.BuildAndSignTransactionResponse{
-			Code:    wallet.ReturnCode_ERROR,
-			Message: "decode base64 string fail",
-		}, nil
-	}
-	RiskKeyHash := crypto.Keccak256(append(txReqJsonByte, []byte(RisKKey)...))
-	RiskKeyHashStr := hexutils.BytesToHex(RiskKeyHash)
-	if RiskKeyHashStr != request.RiskKeyHash {
+	body := approvalBody(map[string]string{
+		"chain_name":     request.ChainName,
+		"network":        request.Network,
+		"public_key":     request.PublicKey,
+		"tx_base64_body": request.TxBase64Body,
+	})
+	if msg := c.checkApproval(request, body); msg != "" {
 		return &wallet.BuildAndSignTransactionResponse{
 			Code:    wallet.ReturnCode_ERROR,
-			Message: "riskKey hash check Fail",
-		}, nil
-	}
-	WalletKeyHash := crypto.Keccak256(append(txReqJsonByte, []byte(WalletKey)...))
-	WalletKeyHashStr := hexutils.BytesToHex(WalletKeyHash)
-	if WalletKeyHashStr != request.WalletKeyHash {
-		return &wallet.BuildAndSignTransactionResponse{
-			Code:    wallet.ReturnCode_ERROR,
-			Message: "wallet key hash Check Fail",
+			Message: msg,
 		}, nil
 	}
 	return c.registry[request.ChainName].BuildAndSignTransaction(ctx, request)
 }
 
 func (c *ChainDispatcher) BuildAndSignBatchTransaction(ctx context.Context, request *wallet.BuildAndSignBatchTransactionRequest) (*wallet.BuildAndSignBatchTransactionResponse, error) {
-	resp := c.preHandler(request)
+	resp := c.preHandler(request, wallet.WalletService_BuildAndSignBatchTransaction_FullMethodName)
 	if resp != nil {
 		return &wallet.BuildAndSignBatchTransactionResponse{
 			Code:    resp.Code,
 			Message: resp.Msg,
 		}, nil
 	}
-	return c.registry[request.ChainName].BuildAndSignBatchTransaction(ctx, request)
+	if err := chain.CheckBatchSize(len(request.TxMsg), c.maxBatchSize); err != nil {
+		return &wallet.BuildAndSignBatchTransactionResponse{
+			Code:    wallet.ReturnCode_ERROR,
+			Message: err.Error(),
+		}, nil
+	}
+	// items failing the co-approval are rejected on their own, the approved
+	// ones are still signed
+	results := make([]*wallet.TransactionWithSign, len(request.TxMsg))
+	var approvedIndexes []int
+	approved := &wallet.BuildAndSignBatchTransactionRequest{
+		ConsumerToken: request.ConsumerToken,
+		ChainName:     request.ChainName,
+		Network:       request.Network,
+	}
+	for i, txMsg := range request.TxMsg {
+		body := approvalBody(map[string]string{
+			"chain_name":     request.ChainName,
+			"network":        request.Network,
+			"public_key":     txMsg.PublicKey,
+			"tx_base64_body": txMsg.TxBase64Body,
+		})
+		if msg := c.checkApproval(txMsg, body); msg != "" {
+			results[i] = &wallet.TransactionWithSign{
+				Code:      wallet.ReturnCode_ERROR,
+				Message:   msg,
+				PublicKey: txMsg.PublicKey,
+			}
+			continue
+		}
+		approvedIndexes = append(approvedIndexes, i)
+		approved.TxMsg = append(approved.TxMsg, txMsg)
+	}
+	if len(approved.TxMsg) > 0 {
+		batchResp, err := c.registry[request.ChainName].BuildAndSignBatchTransaction(ctx, approved)
+		// a failed adaptor call fails every approved item, the rejected ones
+		// keep their own reason
+		var msg string
+		switch {
+		case err != nil:
+			msg = err.Error()
+		case batchResp.Code != wallet.ReturnCode_SUCCESS:
+			msg = batchResp.Message
+		case len(batchResp.TxWithSign) != len(approved.TxMsg):
+			msg = fmt.Sprintf("batch returned %d results for %d transactions", len(batchResp.TxWithSign), len(approved.TxMsg))
+		}
+		for j, i := range approvedIndexes {
+			if msg != "" {
+				results[i] = &wallet.TransactionWithSign{
+					Code:      wallet.ReturnCode_ERROR,
+					Message:   msg,
+					PublicKey: request.TxMsg[i].PublicKey,
+				}
+				continue
+			}
+			results[i] = batchResp.TxWithSign[j]
+		}
+	}
+	return chain.BatchResponse(results), nil
 }
 
 func (c *ChainDispatcher) SignTransactionMessage(ctx context.Context, request *wallet.SignTransactionMessageRequest) (*wallet.SignTransactionMessageResponse, error) {
-	resp := c.preHandler(request)
+	resp := c.preHandler(request, wallet.WalletService_SignTransactionMessage_FullMethodName)
 	if resp != nil {
 		return &wallet.SignTransactionMessageResponse{
 			Code:    resp.Code,
 			Message: resp.Msg,
 		}, nil
 	}
+	body := approvalBody(map[string]string{
+		"chain_name":   request.ChainName,
+		"network":      request.Network,
+		"public_key":   request.PublicKey,
+		"message_hash": request.MessageHash,
+	})
+	if msg := c.checkApproval(request, body); msg != "" {
+		return &wallet.SignTransactionMessageResponse{
+			Code:    wallet.ReturnCode_ERROR,
+			Message: msg,
+		}, nil
+	}
 	return c.registry[request.ChainName].SignTransactionMessage(ctx, request)
 }
 
+func (c *ChainDispatcher) SignPersonalMessage(ctx context.Context, request *wallet.SignPersonalMessageRequest) (*wallet.SignPersonalMessageResponse, error) {
+	resp := c.preHandler(request, wallet.WalletService_SignPersonalMessage_FullMethodName)
+	if resp != nil {
+		return &wallet.SignPersonalMessageResponse{
+			Code:    resp.Code,
+			Message: resp.Msg,
+		}, nil
+	}
+	body := approvalBody(map[string]string{
+		"chain_name": request.ChainName,
+		"network":    request.Network,
+		"public_key": request.PublicKey,
+		"message":    request.Message,
+	})
+	if msg := c.checkApproval(request, body); msg != "" {
+		return &wallet.SignPersonalMessageResponse{
+			Code:    wallet.ReturnCode_ERROR,
+			Message: msg,
+		}, nil
+	}
+	return c.registry[request.ChainName].SignPersonalMessage(ctx, request)
+}
+
+func (c *ChainDispatcher) SignTypedData(ctx context.Context, request *wallet.SignTypedDataRequest) (*wallet.SignTypedDataResponse, error) {
+	resp := c.preHandler(request, wallet.WalletService_SignTypedData_FullMethodName)
+	if resp != nil {
+		return &wallet.SignTypedDataResponse{
+			Code:    resp.Code,
+			Message: resp.Msg,
+		}, nil
+	}
+	body := approvalBody(map[string]string{
+		"chain_name": request.ChainName,
+		"network":    request.Network,
+		"public_key": request.PublicKey,
+		"typed_data": request.TypedData,
+	})
+	if msg := c.checkApproval(request, body); msg != "" {
+		return &wallet.SignTypedDataResponse{
+			Code:    wallet.ReturnCode_ERROR,
+			Message: msg,
+		}, nil
+	}
+	return c.registry[request.ChainName].SignTypedData(ctx, request)
+}
+
+func (c *ChainDispatcher) BuildAndSignPermit(ctx context.Context, request *wallet.BuildAndSignPermitRequest) (*wallet.BuildAndSignPermitResponse, error) {
+	resp := c.preHandler(request, wallet.WalletService_BuildAndSignPermit_FullMethodName)
+	if resp != nil {
+		return &wallet.BuildAndSignPermitResponse{
+			Code:    resp.Code,
+			Message: resp.Msg,
+		}, nil
+	}
+	body := approvalBody(map[string]string{
+		"chain_name":         request.ChainName,
+		"network":            request.Network,
+		"public_key":         request.PublicKey,
+		"permit_base64_body": request.PermitBase64Body,
+	})
+	if msg := c.checkApproval(request, body); msg != "" {
+		return &wallet.BuildAndSignPermitResponse{
+			Code:    wallet.ReturnCode_ERROR,
+			Message: msg,
+		}, nil
+	}
+	return c.registry[request.ChainName].BuildAndSignPermit(ctx, request)
+}
+
+func (c *ChainDispatcher) BuildAndSignUserOperation(ctx context.Context, request *wallet.BuildAndSignUserOperationRequest) (*wallet.BuildAndSignUserOperationResponse, error) {
+	resp := c.preHandler(request, wallet.WalletService_BuildAndSignUserOperation_FullMethodName)
+	if resp != nil {
+		return &wallet.BuildAndSignUserOperationResponse{
+			Code:    resp.Code,
+			Message: resp.Msg,
+		}, nil
+	}
+	body := approvalBody(map[string]string{
+		"chain_name":          request.ChainName,
+		"network":             request.Network,
+		"public_key":          request.PublicKey,
+		"user_op_base64_body": request.UserOpBase64Body,
+	})
+	if msg := c.checkApproval(request, body); msg != "" {
+		return &wallet.BuildAndSignUserOperationResponse{
+			Code:    wallet.ReturnCode_ERROR,
+			Message: msg,
+		}, nil
+	}
+	return c.registry[request.ChainName].BuildAndSignUserOperation(ctx, request)
+}
+
+func (c *ChainDispatcher) BuildAndSignSafeTransaction(ctx context.Context, request *wallet.BuildAndSignSafeTransactionRequest) (*wallet.BuildAndSignSafeTransactionResponse, error) {
+	resp := c.preHandler(request, wallet.WalletService_BuildAndSignSafeTransaction_FullMethodName)
+	if resp != nil {
+		return &wallet.BuildAndSignSafeTransactionResponse{
+			Code:    resp.Code,
+			Message: resp.Msg,
+		}, nil
+	}
+	body := approvalBody(map[string]string{
+		"chain_name":          request.ChainName,
+		"network":             request.Network,
+		"public_key":          request.PublicKey,
+		"safe_tx_base64_body": request.SafeTxBase64Body,
+	})
+	if msg := c.checkApproval(request, body); msg != "" {
+		return &wallet.BuildAndSignSafeTransactionResponse{
+			Code:    wallet.ReturnCode_ERROR,
+			Message: msg,
+		}, nil
+	}
+	return c.registry[request.ChainName].BuildAndSignSafeTransaction(ctx, request)
+}
+
+func (c *ChainDispatcher) SignPsbt(ctx context.Context, request *wallet.SignPsbtRequest) (*wallet.SignPsbtResponse, error) {
+	resp := c.preHandler(request, wallet.WalletService_SignPsbt_FullMethodName)
+	if resp != nil {
+		return &wallet.SignPsbtResponse{
+			Code:    resp.Code,
+			Message: resp.Msg,
+		}, nil
+	}
+	if request.AllowAnySighash && !c.consumers[hashToken(request.ConsumerToken)].AllowAnySighash() {
+		return &wallet.SignPsbtResponse{
+			Code:    wallet.ReturnCode_ERROR,
+			Message: "permission denied: allow_any_sighash",
+		}, nil
+	}
+	psbtByte, err := base64.StdEncoding.DecodeString(request.PsbtBase64)
+	if err != nil {
+		return &wallet.SignPsbtResponse{
+			Code:    wallet.ReturnCode_ERROR,
+			Message: "decode base64 string fail",
+		}, nil
+	}
+	if msg := c.checkApproval(request, psbtByte); msg != "" {
+		return &wallet.SignPsbtResponse{
+			Code:    wallet.ReturnCode_ERROR,
+			Message: msg,
+		}, nil
+	}
+	return c.registry[request.ChainName].SignPsbt(ctx, request)
+}
+
+func (c *ChainDispatcher) CreateMultisigAddress(ctx context.Context, request *wallet.CreateMultisigAddressRequest) (*wallet.CreateMultisigAddressResponse, error) {
+	resp := c.preHandler(request, wallet.WalletService_CreateMultisigAddress_FullMethodName)
+	if resp != nil {
+		return &wallet.CreateMultisigAddressResponse{
+			Code:    resp.Code,
+			Message: resp.Msg,
+		}, nil
+	}
+	return c.registry[request.ChainName].CreateMultisigAddress(ctx, request)
+}
+
+func (c *ChainDispatcher) CreateMusig2Address(ctx context.Context, request *wallet.CreateMusig2AddressRequest) (*wallet.CreateMusig2AddressResponse, error) {
+	resp := c.preHandler(request, wallet.WalletService_CreateMusig2Address_FullMethodName)
+	if resp != nil {
+		return &wallet.CreateMusig2AddressResponse{
+			Code:    resp.Code,
+			Message: resp.Msg,
+		}, nil
+	}
+	return c.registry[request.ChainName].CreateMusig2Address(ctx, request)
+}
+
+func (c *ChainDispatcher) Musig2Nonce(ctx context.Context, request *wallet.Musig2NonceRequest) (*wallet.Musig2NonceResponse, error) {
+	resp := c.preHandler(request, wallet.WalletService_Musig2Nonce_FullMethodName)
+	if resp != nil {
+		return &wallet.Musig2NonceResponse{
+			Code:    resp.Code,
+			Message: resp.Msg,
+		}, nil
+	}
+	return c.registry[request.ChainName].Musig2Nonce(ctx, request)
+}
+
+func (c *ChainDispatcher) Musig2PartialSign(ctx context.Context, request *wallet.Musig2PartialSignRequest) (*wallet.Musig2PartialSignResponse, error) {
+	resp := c.preHandler(request, wallet.WalletService_Musig2PartialSign_FullMethodName)
+	if resp != nil {
+		return &wallet.Musig2PartialSignResponse{
+			Code:    resp.Code,
+			Message: resp.Msg,
+		}, nil
+	}
+	body := approvalBody(map[string]string{
+		"chain_name":   request.ChainName,
+		"network":      request.Network,
+		"session_id":   request.SessionId,
+		"public_key":   request.PublicKey,
+		"message_hash": request.MessageHash,
+	})
+	if msg := c.checkApproval(request, body); msg != "" {
+		return &wallet.Musig2PartialSignResponse{
+			Code:    wallet.ReturnCode_ERROR,
+			Message: msg,
+		}, nil
+	}
+	return c.registry[request.ChainName].Musig2PartialSign(ctx, request)
+}
+
+func (c *ChainDispatcher) CreateTaprootAddress(ctx context.Context, request *wallet.CreateTaprootAddressRequest) (*wallet.CreateTaprootAddressResponse, error) {
+	resp := c.preHandler(request, wallet.WalletService_CreateTaprootAddress_FullMethodName)
+	if resp != nil {
+		return &wallet.CreateTaprootAddressResponse{
+			Code:    resp.Code,
+			Message: resp.Msg,
+		}, nil
+	}
+	return c.registry[request.ChainName].CreateTaprootAddress(ctx, request)
+}
+
 func (c *ChainDispatcher) Interceptor(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
 	defer func() {
 		if e := recover(); e != nil {
@@ -147,40 +416,70 @@ func (c *ChainDispatcher) Interceptor(ctx context.Context, request interface{},
 		}
 	}()
 
-	pos := strings.LastIndex(info.FullMethod, "/")
-	consumerToken := request.(CommonRequest).GetConsumerToken()
-	method := info.FullMethod[pos+1:]
+	method := methodName(info.FullMethod)
 	chainName := request.(CommonRequest).GetChainName()
-	log.Info(method, "chain", chainName, "consumerToken", consumerToken, "req", request)
+	consumerName := "unknown"
+	if consumer, ok := c.consumers[hashToken(request.(CommonRequest).GetConsumerToken())]; ok {
+		consumerName = consumer.Name
+	}
+	log.Info(method, "chain", chainName, "consumer", consumerName)
 	resp, err = handler(ctx, request)
 	log.Debug("Finish handling", "resp", resp, "err", err)
 	return
 }
 
-func (c *ChainDispatcher) preHandler(req interface{}) (resp *CommonReply) {
+func (c *ChainDispatcher) preHandler(req interface{}, fullMethod string) (resp *CommonReply) {
 	consumerToken := req.(CommonRequest).GetConsumerToken()
-	if consumerToken != AccessToken {
+	consumer, ok := c.consumers[hashToken(consumerToken)]
+	if !ok {
 		return &CommonReply{
 			Code: wallet.ReturnCode_ERROR,
 			Msg:  "Invalid consumer token",
 		}
 	}
 	chainName := req.(CommonRequest).GetChainName()
-	log.Debug("chain name", "chain", chainName, "req", req)
+	method := methodName(fullMethod)
+	log.Debug("chain name", "chain", chainName, "consumer", consumer.Name, "method", method)
 	if _, ok := c.registry[chainName]; !ok {
 		return &CommonReply{
 			Code: wallet.ReturnCode_ERROR,
 			Msg:  "unsupported chain",
 		}
 	}
+	if !consumer.AllowChain(chainName) || !consumer.AllowMethod(method) {
+		log.Warn("consumer permission denied", "consumer", consumer.Name, "chain", chainName, "method", method)
+		return &CommonReply{
+			Code: wallet.ReturnCode_ERROR,
+			Msg:  "permission denied",
+		}
+	}
 	return nil
 }
 
 func NewChainDispatcher(conf *config.Config) (*ChainDispatcher, error) {
+	consumers, err := newConsumers(conf.Consumers)
+	if err != nil {
+		log.Error("load consumers fail", "err", err)
+		return nil, err
+	}
+	walletKeys, err := newHashKeys("wallet_keys", conf.WalletKeys)
+	if err != nil {
+		log.Error("load wallet keys fail", "err", err)
+		return nil, err
+	}
+	riskKeys, err := newHashKeys("risk_keys", conf.RiskKeys)
+	if err != nil {
+		log.Error("load risk keys fail", "err", err)
+		return nil, err
+	}
 	dispatcher := &ChainDispatcher{
-		registry: make(map[string]chain.IChainAdaptor),
+		registry:     make(map[string]chain.IChainAdaptor),
+		consumers:    consumers,
+		walletKeys:   walletKeys,
+		riskKeys:     riskKeys,
+		maxBatchSize: conf.MaxBatchSize,
 	}
-	chainAdaptorFactoryMap := map[ChainType]func(db *leveldb.Keys) (chain.IChainAdaptor, error){
+	chainAdaptorFactoryMap := map[ChainType]func(conf *config.Config, db *leveldb.Keys) (chain.IChainAdaptor, error){
 		bitcoin.ChainName:  bitcoin.NewChainAdaptor,
 		ethereum.ChainName: ethereum.NewChainAdaptor,
 		solana.ChainName:   solana.NewChainAdaptor,
@@ -191,7 +490,10 @@ func NewChainDispatcher(conf *config.Config) (*ChainDispatcher, error) {
 		solana.ChainName,
 	}
 
-	db, err := leveldb.NewKeyStore(conf.LevelDbPath)
+	if conf.HsmEnable && conf.HdEnable {
+		return nil, errors.New("hd_enable can not be used with hsm_enable, token keys are not derivable")
+	}
+	db, err := leveldb.NewKeyStore(conf.LevelDbPath, conf.KeyPath, conf.KeyPassphrase)
 	if err != nil {
 		log.Error("new key store level db", "err", err)
 		return nil, err
@@ -199,7 +501,7 @@ func NewChainDispatcher(conf *config.Config) (*ChainDispatcher, error) {
 
 	for _, c := range conf.Chains {
 		if factory, ok := chainAdaptorFactoryMap[c]; ok {
-			adaptor, err := factory(db)
+			adaptor, err := factory(conf, db)
 			if err != nil {
 				log.Crit("failed to setup chain", "chain", c, "error", err)
 			}
@@ -27,7 +27,7 @@ func runRpc(ctx *cli.Context, shutdown context.CancelCauseFunc) (cliapp.Lifecycl
 }
 
 func NewCli() *cli.App {
-	flags := flags.Flags
+	rpcFlags := flags.Flags
 	return &cli.App{
 		Version:              "v0.0.1-beta",
 		Description:          "wallet sign rpc service",
@@ -35,10 +35,22 @@ func NewCli() *cli.App {
 		Commands: []*cli.Command{
 			{
 				Name:        "rpc",
-				Flags:       flags,
+				Flags:       rpcFlags,
 				Description: "Run rpc services",
 				Action:      cliapp.LifecycleCmd(runRpc),
 			},
+			{
+				Name:        "init-seed",
+				Flags:       []cli.Flag{flags.ConfigFlag},
+				Description: "Generate the hd wallet seed and print its mnemonic once",
+				Action:      initSeed,
+			},
+			{
+				Name:        "restore-seed",
+				Flags:       []cli.Flag{flags.ConfigFlag, flags.DeriveFlag},
+				Description: "Restore the hd wallet seed from a mnemonic read on stdin",
+				Action:      restoreSeed,
+			},
 			{
 				Name:        "version",
 				Description: "Show project version",
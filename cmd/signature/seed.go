@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/DQYXACML/wallet-sign/chain"
+	"github.com/DQYXACML/wallet-sign/chain/bitcoin"
+	"github.com/DQYXACML/wallet-sign/config"
+	"github.com/DQYXACML/wallet-sign/flags"
+	"github.com/DQYXACML/wallet-sign/leveldb"
+	"github.com/DQYXACML/wallet-sign/ssm"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+)
+
+func openKeyStore(ctx *cli.Context) (*leveldb.Keys, error) {
+	cfg, err := config.NewConfig(ctx.String(flags.ConfigFlag.Name))
+	if err != nil {
+		log.Error("new config fail", "err", err)
+		return nil, err
+	}
+	return leveldb.NewKeyStore(cfg.LevelDbPath, cfg.KeyPath, cfg.KeyPassphrase)
+}
+
+func initSeed(ctx *cli.Context) error {
+	db, err := openKeyStore(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := db.GetSeed(); err == nil {
+		return leveldb.ErrSeedExists
+	}
+	mnemonic, err := ssm.NewMnemonic()
+	if err != nil {
+		return err
+	}
+	seed, err := ssm.MnemonicToSeed(mnemonic, "")
+	if err != nil {
+		return err
+	}
+	if err := db.StoreSeed(seed); err != nil {
+		return err
+	}
+	fmt.Println("Write down the mnemonic below, it is the only backup of every hd key and is not shown again:")
+	fmt.Println(mnemonic)
+	return nil
+}
+
+func restoreSeed(ctx *cli.Context) error {
+	db, err := openKeyStore(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Enter the mnemonic:")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return err
+	}
+	seed, err := ssm.MnemonicToSeed(strings.Join(strings.Fields(line), " "), "")
+	if err != nil {
+		return err
+	}
+	if err := db.StoreSeed(seed); err != nil && !errors.Is(err, leveldb.ErrSeedExists) {
+		return err
+	}
+	stored, err := db.GetSeed()
+	if err != nil {
+		return err
+	}
+	if string(stored) != string(seed) {
+		return errors.New("key store holds a different hd seed")
+	}
+	return restoreKeys(db, seed, ctx.StringSlice(flags.DeriveFlag.Name))
+}
+
+// restoreKeys re-derives and stores the keys of every derive value with the
+// metadata CreateKeyPairs stores with them.
+func restoreKeys(db *leveldb.Keys, seed []byte, derives []string) error {
+	for _, derive := range derives {
+		pathTemplate, count, network, err := parseDerive(derive)
+		if err != nil {
+			return err
+		}
+		keyNetwork, isBitcoin, err := bitcoin.HDNetwork(pathTemplate, network)
+		if err != nil {
+			return fmt.Errorf("invalid derive value %s: %w", derive, err)
+		}
+		if !isBitcoin && network != "" {
+			return fmt.Errorf("invalid derive value %s: network is only set for bitcoin keys", derive)
+		}
+		keyPairs, err := chain.DeriveKeyPairs(hdSignerForPath(pathTemplate), seed, pathTemplate, 0, count)
+		if err != nil {
+			return err
+		}
+		keyList := make([]leveldb.Key, 0, len(keyPairs))
+		for _, keyPair := range keyPairs {
+			keyPair.Network = keyNetwork
+			keyList = append(keyList, keyPair.Key)
+		}
+		if !db.StoreKeys(keyList) {
+			return errors.New("store keys fail")
+		}
+		log.Info("restore hd keys success", "path", pathTemplate, "count", count, "network", keyNetwork)
+	}
+	return nil
+}
+
+// parseDerive splits a path_template=count[@network] derive value.
+func parseDerive(derive string) (string, uint32, string, error) {
+	pos := strings.LastIndex(derive, "=")
+	if pos < 0 || !strings.Contains(derive[:pos], "%d") {
+		return "", 0, "", fmt.Errorf("invalid derive value: %s", derive)
+	}
+	countStr, network, _ := strings.Cut(derive[pos+1:], "@")
+	count, err := strconv.ParseUint(countStr, 10, 32)
+	if err != nil {
+		return "", 0, "", fmt.Errorf("invalid derive count: %s", derive)
+	}
+	return derive[:pos], uint32(count), network, nil
+}
+
+// hdSignerForPath picks the curve of the path, solana keys are SLIP-10 ed25519
+func hdSignerForPath(pathTemplate string) ssm.HDSigner {
+	if strings.HasPrefix(pathTemplate, "m/44'/501'/") {
+		return &ssm.EdDSASigner{}
+	}
+	return &ssm.ECDSASigner{}
+}
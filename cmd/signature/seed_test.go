@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/DQYXACML/wallet-sign/chain/bitcoin"
+	"github.com/DQYXACML/wallet-sign/config"
+	"github.com/DQYXACML/wallet-sign/leveldb"
+	"github.com/DQYXACML/wallet-sign/protobuf/wallet"
+	"github.com/DQYXACML/wallet-sign/ssm"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func newTestKeyStore(t *testing.T, seed []byte) *leveldb.Keys {
+	t.Helper()
+	db, err := leveldb.NewKeyStore(t.TempDir(), "", "test passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.StoreSeed(seed); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// TestRestoreBitcoinKeys checks that restored bitcoin keys are stored with the
+// same private key and metadata as the keys created by the adaptor.
+func TestRestoreBitcoinKeys(t *testing.T) {
+	seed, err := ssm.MnemonicToSeed(testMnemonic, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		addressFormat string
+		network       string
+		derive        string
+	}{
+		{addressFormat: "p2wpkh", network: "mainnet", derive: "m/84'/0'/0'/0/%d=3"},
+		{addressFormat: "p2tr", network: "testnet3", derive: "m/86'/1'/0'/0/%d=3@testnet3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addressFormat+" "+tt.network, func(t *testing.T) {
+			createdDb := newTestKeyStore(t, seed)
+			adaptor, err := bitcoin.NewChainAdaptor(&config.Config{HdEnable: true}, createdDb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := adaptor.CreateKeyPairsWithAddresses(context.Background(), &wallet.CreateKeyPairsWithAddressesRequest{
+				KeyNum:        3,
+				AddressFormat: tt.addressFormat,
+				Network:       tt.network,
+			})
+			if err != nil || resp.Code != wallet.ReturnCode_SUCCESS {
+				t.Fatalf("create keys fail: %v %s", err, resp.GetMessage())
+			}
+
+			restoredDb := newTestKeyStore(t, seed)
+			if err := restoreKeys(restoredDb, seed, []string{tt.derive}); err != nil {
+				t.Fatal(err)
+			}
+			for _, key := range resp.PublicKeyAddresses {
+				createdPrivKey, _ := createdDb.GetPrivKey(key.PublicKey)
+				restoredPrivKey, ok := restoredDb.GetPrivKey(key.PublicKey)
+				if !ok || restoredPrivKey != createdPrivKey {
+					t.Fatalf("key %s not restored", key.PublicKey)
+				}
+				createdMeta, _ := createdDb.GetKeyMeta(key.PublicKey)
+				restoredMeta, ok := restoredDb.GetKeyMeta(key.PublicKey)
+				if !ok || !reflect.DeepEqual(restoredMeta, createdMeta) {
+					t.Fatalf("key %s restored with meta %+v, want %+v", key.PublicKey, restoredMeta, createdMeta)
+				}
+			}
+		})
+	}
+}
+
+func TestRestoreKeysNetwork(t *testing.T) {
+	seed, err := ssm.MnemonicToSeed(testMnemonic, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := newTestKeyStore(t, seed)
+	for _, derive := range []string{
+		// coin type 1 is shared by the test networks
+		"m/84'/1'/0'/0/%d=1",
+		"m/84'/0'/0'/0/%d=1@testnet3",
+		"m/84'/1'/0'/0/%d=1@dogecoin",
+		"m/44'/60'/0'/0/%d=1@testnet3",
+	} {
+		if err := restoreKeys(db, seed, []string{derive}); err == nil {
+			t.Fatalf("derive %s restored", derive)
+		}
+	}
+}
@@ -11,14 +11,71 @@ type ServerConfig struct {
 	Port int    `yaml:"port"`
 }
 
+// ConsumerConfig is one rpc caller, TokenHash is the hex sha256 of its access
+// token and "*" in Chains or Methods allows everything. Signing raw hashes,
+// signTransactionMessage and the musig2 signing rounds, is never covered by
+// Methods and needs AllowRawSign, signing psbt inputs with a sighash other
+// than SIGHASH_ALL or SIGHASH_DEFAULT needs AllowAnySighash.
+type ConsumerConfig struct {
+	Name            string   `yaml:"name"`
+	TokenHash       string   `yaml:"token_hash"`
+	Chains          []string `yaml:"chains"`
+	Methods         []string `yaml:"methods"`
+	AllowRawSign    bool     `yaml:"allow_raw_sign"`
+	AllowAnySighash bool     `yaml:"allow_any_sighash"`
+}
+
+// HashKeyConfig is one version of a co-approval secret, the secret is given
+// inline or read from SecretFile
+type HashKeyConfig struct {
+	Version    string `yaml:"version"`
+	Secret     string `yaml:"secret"`
+	SecretFile string `yaml:"secret_file"`
+}
+
+// PermitPolicyConfig caps the permits signed for Token, "*" matches every
+// token. Empty Spenders allows any spender and an empty MaxAmount any amount.
+type PermitPolicyConfig struct {
+	Token     string   `yaml:"token"`
+	Spenders  []string `yaml:"spenders"`
+	MaxAmount string   `yaml:"max_amount"`
+}
+
+// BitcoinConfig bounds the bitcoin transactions, MaxFeeRate is in sat/vB and
+// 0 disables the cap. ChangeAddresses maps a network to the service owned
+// address that receives the change of a request asking for it. A musig2
+// session expires Musig2SessionTTL seconds after its nonce round and a public
+// key holds at most MaxMusig2Sessions open sessions.
+type BitcoinConfig struct {
+	MaxFeeRate        uint64            `yaml:"max_fee_rate"`
+	ChangeAddresses   map[string]string `yaml:"change_addresses"`
+	Musig2SessionTTL  uint64            `yaml:"musig2_session_ttl"`
+	MaxMusig2Sessions int               `yaml:"max_musig2_sessions"`
+}
+
 type Config struct {
-	LevelDbPath     string       `yaml:"level_db_path"`
-	RpcServer       ServerConfig `yaml:"rpc_server"`
-	CredentialsFile string       `yaml:"credentials_file"`
-	KeyName         string       `yaml:"key_name"`
-	KeyPath         string       `yaml:"key_path"`
-	HsmEnable       bool         `yaml:"hsm_enable"`
-	Chains          []string     `yaml:"chains"`
+	LevelDbPath     string           `yaml:"level_db_path"`
+	RpcServer       ServerConfig     `yaml:"rpc_server"`
+	CredentialsFile string           `yaml:"credentials_file"`
+	KeyName         string           `yaml:"key_name"`
+	KeyPath         string           `yaml:"key_path"`
+	KeyPassphrase   string           `yaml:"key_passphrase"`
+	HsmEnable       bool             `yaml:"hsm_enable"`
+	HsmModulePath   string           `yaml:"hsm_module_path"`
+	HdEnable        bool             `yaml:"hd_enable"`
+	Chains          []string         `yaml:"chains"`
+	Consumers       []ConsumerConfig `yaml:"consumers"`
+	WalletKeys      []HashKeyConfig  `yaml:"wallet_keys"`
+	RiskKeys        []HashKeyConfig  `yaml:"risk_keys"`
+	BatchWorkers    int              `yaml:"batch_workers"`
+	MaxBatchSize    int              `yaml:"max_batch_size"`
+	// PermitPolicies restricts permit signing when set, a permit must match
+	// the policy of its token
+	PermitPolicies []PermitPolicyConfig `yaml:"permit_policies"`
+	// AllowAnyChainAuthorization lets EIP-7702 authorizations carry chain_id
+	// 0, which is valid on every chain
+	AllowAnyChainAuthorization bool          `yaml:"allow_any_chain_authorization"`
+	Bitcoin                    BitcoinConfig `yaml:"bitcoin"`
 }
 
 func NewConfig(path string) (*Config, error) {
@@ -36,8 +93,14 @@ func NewConfig(path string) (*Config, error) {
 		log.Error("unmarshal config file error", "err", err)
 		return nil, err
 	}
+	if config.KeyPassphrase == "" {
+		config.KeyPassphrase = os.Getenv(KeyPassphraseEnv)
+	}
 	return config, nil
 }
 
+// KeyPassphraseEnv lets the key store passphrase stay out of the config file
+const KeyPassphraseEnv = "SIGNATURE_KEY_PASSPHRASE"
+
 const UnsupportedChain = "Unsupport chain"
 const UnsupportedOperation = UnsupportedChain
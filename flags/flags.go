@@ -15,6 +15,18 @@ var (
 		EnvVars: prefixEnvVars("LEVEL_DB_PATH"),
 		Value:   "./",
 	}
+	ConfigFlag = &cli.StringFlag{
+		Name:    "config",
+		Aliases: []string{"c"},
+		Usage:   "The path of the config file",
+		EnvVars: prefixEnvVars("CONFIG"),
+		Value:   "config.yml",
+	}
+	DeriveFlag = &cli.StringSliceFlag{
+		Name:    "derive",
+		Usage:   "Re-derive keys after restore, as path_template=count[@network], e.g. m/44'/60'/0'/0/%d=100 or m/84'/1'/0'/0/%d=100@testnet3",
+		EnvVars: prefixEnvVars("DERIVE"),
+	}
 )
 
 var requireFlags = []cli.Flag{
@@ -0,0 +1,78 @@
+package leveldb
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	masterKeyLen = 32
+	kdfSaltLen   = 32
+
+	// scrypt parameters, same cost as the go-ethereum standard keystore
+	scryptN = 1 << 18
+	scryptR = 8
+	scryptP = 1
+)
+
+var (
+	ErrNoMasterKey      = errors.New("no master key configured, set key_path or key_passphrase")
+	ErrInvalidMasterKey = errors.New("master key does not match the key store")
+)
+
+// loadMasterKey reads a 32 byte master key from a key file, the file can hold
+// the raw bytes or their hex encoding.
+func loadMasterKey(keyFile string) ([]byte, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read master key file fail: %w", err)
+	}
+	if len(data) == masterKeyLen {
+		return data, nil
+	}
+	key, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+	if err != nil || len(key) != masterKeyLen {
+		return nil, fmt.Errorf("master key file must hold %d bytes raw or hex encoded", masterKeyLen)
+	}
+	return key, nil
+}
+
+// deriveMasterKey stretches the passphrase into the master key with scrypt.
+func deriveMasterKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, masterKeyLen)
+}
+
+func newAEAD(masterKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the plaintext as nonce||ciphertext, the record key is bound
+// as additional data so sealed values can not be moved between records.
+func seal(aead cipher.AEAD, recordKey []byte, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, recordKey), nil
+}
+
+func open(aead cipher.AEAD, recordKey []byte, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return nil, errors.New("sealed value too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, recordKey)
+}
@@ -0,0 +1,154 @@
+package leveldb
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+const (
+	hdSeedKey        = metaPrefix + "hd_seed"
+	hdIndexKeyPrefix = metaPrefix + "hd_index:"
+	keyMetaKeyPrefix = metaPrefix + "key:"
+)
+
+var (
+	ErrSeedNotFound = errors.New("hd seed not initialized")
+	ErrSeedExists   = errors.New("hd seed already initialized")
+)
+
+// StoreSeed seals the BIP39 seed into the key store, an existing seed is
+// never overwritten.
+func (k *Keys) StoreSeed(seed []byte) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, err := k.db.Get([]byte(hdSeedKey)); err == nil {
+		return ErrSeedExists
+	} else if !errors.Is(err, leveldb.ErrNotFound) {
+		return err
+	}
+	sealed, err := seal(k.aead, []byte(hdSeedKey), seed)
+	if err != nil {
+		return err
+	}
+	return k.db.Put([]byte(hdSeedKey), sealed)
+}
+
+func (k *Keys) GetSeed() ([]byte, error) {
+	data, err := k.db.Get([]byte(hdSeedKey))
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, ErrSeedNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return open(k.aead, []byte(hdSeedKey), data)
+}
+
+// ReserveHDIndex hands out num consecutive child indexes for the derivation
+// path template and returns the first one. The reservation is only held in
+// memory, the next free index is persisted by StoreKeys in the same write as
+// the derived keys. Keys that are not stored give it back with
+// ReleaseHDIndexes, a failed StoreKeys does so itself.
+func (k *Keys) ReserveHDIndex(pathTemplate string, num uint32) (uint32, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	next, err := k.getHDIndex(pathTemplate)
+	if err != nil {
+		return 0, err
+	}
+	if reserved := k.hdNext[pathTemplate]; reserved > next {
+		next = reserved
+	}
+	if next+num < next {
+		return 0, errors.New("hd index exhausted")
+	}
+	k.hdNext[pathTemplate] = next + num
+	return next, nil
+}
+
+func (k *Keys) getHDIndex(pathTemplate string) (uint32, error) {
+	data, err := k.db.Get([]byte(hdIndexKeyPrefix + pathTemplate))
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(data), nil
+}
+
+// putHDIndexes raises the persisted next free index of every path template to
+// the one after the highest key index in the batch.
+func (k *Keys) putHDIndexes(batch *leveldb.Batch, nextIndexes map[string]uint32) error {
+	for pathTemplate, next := range nextIndexes {
+		current, err := k.getHDIndex(pathTemplate)
+		if err != nil {
+			return err
+		}
+		if current >= next {
+			continue
+		}
+		value := make([]byte, 4)
+		binary.BigEndian.PutUint32(value, next)
+		batch.Put([]byte(hdIndexKeyPrefix+pathTemplate), value)
+	}
+	return nil
+}
+
+// ReleaseHDIndex gives back the num indexes from start reserved for keys that
+// are not going to be stored. Only the latest reservation of the template can
+// be given back, an earlier one stays skipped until the next restart.
+func (k *Keys) ReleaseHDIndex(pathTemplate string, start uint32, num uint32) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.hdNext[pathTemplate] == start+num {
+		k.hdNext[pathTemplate] = start
+	}
+}
+
+// ReleaseHDIndexes gives back the reservation of derived keys that are not
+// going to be stored, see ReleaseHDIndex.
+func (k *Keys) ReleaseHDIndexes(keyList []Key) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.releaseHDIndexes(keyList)
+}
+
+// releaseHDIndexes gives back the reservation of keys that failed to store
+// when no later reservation was handed out.
+func (k *Keys) releaseHDIndexes(keyList []Key) {
+	for i := len(keyList) - 1; i >= 0; i-- {
+		item := keyList[i]
+		if item.PathTemplate != "" && k.hdNext[item.PathTemplate] == item.Index+1 {
+			k.hdNext[item.PathTemplate] = item.Index
+		}
+	}
+}
+
+func (k *Keys) GetKeyMeta(publicKey string) (*KeyMeta, bool) {
+	data, err := k.db.Get([]byte(keyMetaKeyPrefix + publicKey))
+	if err != nil {
+		return nil, false
+	}
+	var meta KeyMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		log.Error("parse key meta fail", "err", err, "key", publicKey)
+		return nil, false
+	}
+	return &meta, true
+}
+
+func (k *Keys) storeKeyMeta(batch *leveldb.Batch, item Key) error {
+	data, err := json.Marshal(KeyMeta{
+		Path:    item.Path,
+		Index:   item.Index,
+		Network: item.Network,
+	})
+	if err != nil {
+		return err
+	}
+	batch.Put([]byte(keyMetaKeyPrefix+item.PubKey), data)
+	return nil
+}
@@ -0,0 +1,50 @@
+package leveldb
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHDIndexReservation(t *testing.T) {
+	const pathTemplate = "m/44'/60'/0'/0/%d"
+	path := t.TempDir()
+	keys, err := NewKeyStore(path, "", "test passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reserve := func(num uint32, want uint32) {
+		t.Helper()
+		start, err := keys.ReserveHDIndex(pathTemplate, num)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if start != want {
+			t.Fatalf("reserved from %d, want %d", start, want)
+		}
+	}
+	hdKey := func(index uint32) Key {
+		return Key{PrivateKey: "11", PubKey: fmt.Sprintf("04%02x", index), PathTemplate: pathTemplate, Index: index}
+	}
+
+	reserve(2, 0)
+	keys.ReleaseHDIndex(pathTemplate, 0, 2)
+	// a released reservation is handed out again
+	reserve(2, 0)
+	reserve(3, 2)
+	// only the latest reservation can be given back
+	keys.ReleaseHDIndex(pathTemplate, 0, 2)
+	keys.ReleaseHDIndexes([]Key{hdKey(2), hdKey(3), hdKey(4)})
+	reserve(1, 2)
+	if !keys.StoreKeys([]Key{hdKey(0), hdKey(1), hdKey(2)}) {
+		t.Fatal("store keys fail")
+	}
+	keys.db.Close()
+
+	keys, err = NewKeyStore(path, "", "test passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer keys.db.Close()
+	// the next free index is persisted with the keys
+	reserve(1, 3)
+}
@@ -1,20 +1,151 @@
 package leveldb
 
-import "github.com/ethereum/go-ethereum/log"
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+const (
+	metaPrefix       = "_meta:"
+	encryptionMarker = metaPrefix + "encryption"
+	kdfSaltKey       = metaPrefix + "kdf_salt"
+	keyCheckKey      = metaPrefix + "key_check"
+
+	encryptionVersion = "aes-256-gcm:v1"
+	keyCheckValue     = "wallet-sign key check"
+)
 
 type Keys struct {
-	db *LevelStore
+	db   *LevelStore
+	aead cipher.AEAD
+	mu   sync.Mutex
+	// hdNext holds the hd indexes reserved but not stored yet
+	hdNext map[string]uint32
 }
 
-func NewKeyStore(path string) (*Keys, error) {
+// NewKeyStore opens the key store and unlocks it with the master key read from
+// keyFile, or derived from passphrase when no key file is given. Databases
+// written before encryption was introduced are migrated in place.
+func NewKeyStore(path string, keyFile string, passphrase string) (*Keys, error) {
 	db, err := NewLevelStore(path)
 	if err != nil {
 		log.Error("Could not create leveldb database.")
 		return nil, err
 	}
-	return &Keys{
-		db: db,
-	}, nil
+	keys, err := unlockKeyStore(db, keyFile, passphrase)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return keys, nil
+}
+
+func unlockKeyStore(db *LevelStore, keyFile string, passphrase string) (*Keys, error) {
+	batch := new(leveldb.Batch)
+	var (
+		masterKey []byte
+		err       error
+	)
+	switch {
+	case keyFile != "":
+		masterKey, err = loadMasterKey(keyFile)
+		if err != nil {
+			log.Error("load master key fail", "err", err)
+			return nil, err
+		}
+	case passphrase != "":
+		salt, err := db.Get([]byte(kdfSaltKey))
+		if errors.Is(err, leveldb.ErrNotFound) {
+			salt = make([]byte, kdfSaltLen)
+			if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+				return nil, err
+			}
+			batch.Put([]byte(kdfSaltKey), salt)
+		} else if err != nil {
+			log.Error("get kdf salt fail", "err", err)
+			return nil, err
+		}
+		masterKey, err = deriveMasterKey(passphrase, salt)
+		if err != nil {
+			log.Error("derive master key fail", "err", err)
+			return nil, err
+		}
+	default:
+		return nil, ErrNoMasterKey
+	}
+	aead, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	keys := &Keys{
+		db:     db,
+		aead:   aead,
+		hdNext: make(map[string]uint32),
+	}
+	if err := keys.unlock(batch); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// unlock checks the master key against the stored key check value and seals
+// any plaintext record left over from an unencrypted database.
+func (k *Keys) unlock(batch *leveldb.Batch) error {
+	check, err := k.db.Get([]byte(keyCheckKey))
+	switch {
+	case err == nil:
+		if _, err := open(k.aead, []byte(keyCheckKey), check); err != nil {
+			log.Error("open key check value fail", "err", err)
+			return ErrInvalidMasterKey
+		}
+	case errors.Is(err, leveldb.ErrNotFound):
+		sealed, err := seal(k.aead, []byte(keyCheckKey), []byte(keyCheckValue))
+		if err != nil {
+			return err
+		}
+		batch.Put([]byte(keyCheckKey), sealed)
+	default:
+		return err
+	}
+
+	if _, err := k.db.Get([]byte(encryptionMarker)); errors.Is(err, leveldb.ErrNotFound) {
+		migrated, err := k.migratePlaintext(batch)
+		if err != nil {
+			log.Error("migrate plaintext keys fail", "err", err)
+			return err
+		}
+		batch.Put([]byte(encryptionMarker), []byte(encryptionVersion))
+		log.Info("key store encryption enabled", "migratedKeys", migrated)
+	} else if err != nil {
+		return err
+	}
+	return k.db.Write(batch, nil)
+}
+
+func (k *Keys) migratePlaintext(batch *leveldb.Batch) (int, error) {
+	iter := k.db.NewIterator(nil, nil)
+	defer iter.Release()
+	migrated := 0
+	for iter.Next() {
+		if strings.HasPrefix(string(iter.Key()), metaPrefix) {
+			continue
+		}
+		key := append([]byte{}, iter.Key()...)
+		sealed, err := seal(k.aead, key, iter.Value())
+		if err != nil {
+			return 0, err
+		}
+		batch.Put(key, sealed)
+		migrated++
+	}
+	return migrated, iter.Error()
 }
 
 func (k *Keys) GetPrivKey(publicKey string) (string, bool) {
@@ -23,19 +154,55 @@ func (k *Keys) GetPrivKey(publicKey string) (string, bool) {
 	if err != nil {
 		return "0x00", false
 	}
-	bstr := toString(data)
+	privKey, err := open(k.aead, key, data)
+	if err != nil {
+		log.Error("open private key fail", "err", err, "key", publicKey)
+		return "0x00", false
+	}
+	bstr := toString(privKey)
 	return bstr, true
 }
 
+// StoreKeys seals and writes the keys with their meta in one batch, hd keys
+// also advance the next free index of their path template in that batch.
 func (k *Keys) StoreKeys(keyList []Key) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if !k.storeKeys(keyList) {
+		k.releaseHDIndexes(keyList)
+		return false
+	}
+	return true
+}
+
+func (k *Keys) storeKeys(keyList []Key) bool {
+	batch := new(leveldb.Batch)
+	nextIndexes := make(map[string]uint32)
 	for _, item := range keyList {
 		key := []byte(item.PubKey)
-		value := toBytes(item.PrivateKey)
-		err := k.db.Put(key, value)
+		value, err := seal(k.aead, key, toBytes(item.PrivateKey))
 		if err != nil {
-			log.Error("store key value fail", "err", err, "key", key, "value", value)
+			log.Error("seal private key fail", "err", err, "key", item.PubKey)
 			return false
 		}
+		batch.Put(key, value)
+		if item.Path != "" || item.Network != "" {
+			if err := k.storeKeyMeta(batch, item); err != nil {
+				log.Error("store key meta fail", "err", err, "key", item.PubKey)
+				return false
+			}
+		}
+		if item.PathTemplate != "" && item.Index+1 > nextIndexes[item.PathTemplate] {
+			nextIndexes[item.PathTemplate] = item.Index + 1
+		}
+	}
+	if err := k.putHDIndexes(batch, nextIndexes); err != nil {
+		log.Error("store hd index fail", "err", err)
+		return false
+	}
+	if err := k.db.Write(batch, nil); err != nil {
+		log.Error("store key value fail", "err", err)
+		return false
 	}
 	return true
 }
@@ -0,0 +1,196 @@
+package leveldb
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeMasterKey(t *testing.T, key string) string {
+	t.Helper()
+	keyFile := filepath.Join(t.TempDir(), "master.key")
+	if err := os.WriteFile(keyFile, []byte(key), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return keyFile
+}
+
+func TestSealOpen(t *testing.T) {
+	masterKey := bytes.Repeat([]byte{0x42}, masterKeyLen)
+	otherKey := bytes.Repeat([]byte{0x24}, masterKeyLen)
+	recordKey := []byte("04a1b2c3")
+	plaintext := []byte("private key bytes")
+	tests := []struct {
+		name    string
+		tamper  func(sealed []byte) []byte
+		key     []byte
+		record  []byte
+		wantErr bool
+	}{
+		{name: "round trip", key: masterKey, record: recordKey},
+		{name: "other master key", key: otherKey, record: recordKey, wantErr: true},
+		{name: "moved to another record", key: masterKey, record: []byte("04d4e5f6"), wantErr: true},
+		{
+			name:    "flipped ciphertext bit",
+			tamper:  func(sealed []byte) []byte { sealed[len(sealed)-1] ^= 1; return sealed },
+			key:     masterKey,
+			record:  recordKey,
+			wantErr: true,
+		},
+		{
+			name:    "truncated",
+			tamper:  func(sealed []byte) []byte { return sealed[:10] },
+			key:     masterKey,
+			record:  recordKey,
+			wantErr: true,
+		},
+	}
+	sealAEAD, err := newAEAD(masterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sealed, err := seal(sealAEAD, recordKey, plaintext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Contains(sealed, plaintext) {
+				t.Fatal("sealed value holds the plaintext")
+			}
+			if tt.tamper != nil {
+				sealed = tt.tamper(sealed)
+			}
+			openAEAD, err := newAEAD(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opened, err := open(openAEAD, tt.record, sealed)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("open succeeded")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(opened, plaintext) {
+				t.Fatalf("opened %x, want %x", opened, plaintext)
+			}
+		})
+	}
+}
+
+func TestSealUsesFreshNonces(t *testing.T) {
+	aead, err := newAEAD(bytes.Repeat([]byte{0x42}, masterKeyLen))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := seal(aead, []byte("key"), []byte("value"))
+	second, _ := seal(aead, []byte("key"), []byte("value"))
+	if bytes.Equal(first[:aead.NonceSize()], second[:aead.NonceSize()]) {
+		t.Fatal("nonce reused")
+	}
+}
+
+// TestUnlockKeyStoreMigratesPlaintext opens a key store written before
+// encryption, every private key must be sealed in place and stay readable.
+func TestUnlockKeyStoreMigratesPlaintext(t *testing.T) {
+	path := t.TempDir()
+	plainKeys := map[string]string{
+		"04aa": "1111111111111111111111111111111111111111111111111111111111111111",
+		"04bb": "2222222222222222222222222222222222222222222222222222222222222222",
+	}
+	db, err := NewLevelStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pubKey, privKey := range plainKeys {
+		if err := db.Put([]byte(pubKey), toBytes(privKey)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	keyFile := writeMasterKey(t, hex.EncodeToString(bytes.Repeat([]byte{0x42}, masterKeyLen)))
+	db, err = NewLevelStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := unlockKeyStore(db, keyFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pubKey, privKey := range plainKeys {
+		raw, err := db.Get([]byte(pubKey))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Equal(raw, toBytes(privKey)) {
+			t.Fatalf("key %s left in plaintext", pubKey)
+		}
+		got, ok := keys.GetPrivKey(pubKey)
+		if !ok || got != privKey {
+			t.Fatalf("key %s: got %s, want %s", pubKey, got, privKey)
+		}
+	}
+	marker, err := db.Get([]byte(encryptionMarker))
+	if err != nil || string(marker) != encryptionVersion {
+		t.Fatalf("encryption marker %q: %v", marker, err)
+	}
+	db.Close()
+
+	// a second unlock must not seal the sealed values again
+	db, err = NewLevelStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err = unlockKeyStore(db, keyFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pubKey, privKey := range plainKeys {
+		if got, ok := keys.GetPrivKey(pubKey); !ok || got != privKey {
+			t.Fatalf("key %s after reopen: got %s, want %s", pubKey, got, privKey)
+		}
+	}
+	db.Close()
+
+	wrongKeyFile := writeMasterKey(t, hex.EncodeToString(bytes.Repeat([]byte{0x24}, masterKeyLen)))
+	db, err = NewLevelStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := unlockKeyStore(db, wrongKeyFile, ""); !errors.Is(err, ErrInvalidMasterKey) {
+		t.Fatalf("unlock with the wrong master key: %v", err)
+	}
+}
+
+func TestUnlockKeyStorePassphrase(t *testing.T) {
+	path := t.TempDir()
+	keys, err := NewKeyStore(path, "", "correct passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !keys.StoreKeys([]Key{{PrivateKey: "33", PubKey: "04cc"}}) {
+		t.Fatal("store keys fail")
+	}
+	keys.db.Close()
+
+	if _, err := NewKeyStore(path, "", "wrong passphrase"); !errors.Is(err, ErrInvalidMasterKey) {
+		t.Fatalf("unlock with the wrong passphrase: %v", err)
+	}
+	keys, err = NewKeyStore(path, "", "correct passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer keys.db.Close()
+	if got, ok := keys.GetPrivKey("04cc"); !ok || got != "33" {
+		t.Fatalf("got %s, want 33", got)
+	}
+}
@@ -0,0 +1,149 @@
+package leveldb
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const musig2SessionKeyPrefix = metaPrefix + "musig2:"
+
+var (
+	ErrSessionNotFound = errors.New("musig2 session not found or already used")
+	ErrSessionExists   = errors.New("musig2 session already exists")
+	ErrSessionExpired  = errors.New("musig2 session expired")
+	ErrTooManySessions = errors.New("too many open musig2 sessions for the public key")
+)
+
+// syncWrite makes a session write durable before its nonce leaves the
+// process, a crash can then never hand out a nonce twice.
+var syncWrite = &opt.WriteOptions{Sync: true}
+
+// StoreMusig2Session seals the session with its secret nonce under id. The
+// expired sessions are deleted first, a public key holds at most maxOpen open
+// sessions.
+func (k *Keys) StoreMusig2Session(id string, session *Musig2Session, maxOpen int) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	key := []byte(musig2SessionKeyPrefix + id)
+	if _, err := k.db.Get(key); err == nil {
+		return ErrSessionExists
+	} else if !errors.Is(err, leveldb.ErrNotFound) {
+		return err
+	}
+	batch := new(leveldb.Batch)
+	openSessions, err := k.pruneMusig2Sessions(batch, session.PublicKey)
+	if err != nil {
+		return err
+	}
+	if openSessions >= maxOpen {
+		if batch.Len() > 0 {
+			if err := k.db.Write(batch, nil); err != nil {
+				return err
+			}
+		}
+		return ErrTooManySessions
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	sealed, err := seal(k.aead, key, data)
+	if err != nil {
+		return err
+	}
+	batch.Put(key, sealed)
+	return k.db.Write(batch, syncWrite)
+}
+
+// GetMusig2Session reads the session without consuming it.
+func (k *Keys) GetMusig2Session(id string) (*Musig2Session, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.liveMusig2Session([]byte(musig2SessionKeyPrefix + id))
+}
+
+// TakeMusig2Session deletes the session and returns it, the secret nonce is
+// gone from the store before the caller signs with it.
+func (k *Keys) TakeMusig2Session(id string) (*Musig2Session, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	key := []byte(musig2SessionKeyPrefix + id)
+	session, err := k.liveMusig2Session(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.db.DB.Delete(key, syncWrite); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// liveMusig2Session reads the session and deletes it when it has expired.
+func (k *Keys) liveMusig2Session(key []byte) (*Musig2Session, error) {
+	session, err := k.getMusig2Session(key)
+	if err != nil {
+		return nil, err
+	}
+	if session.expired(time.Now()) {
+		if err := k.db.DB.Delete(key, nil); err != nil {
+			return nil, err
+		}
+		return nil, ErrSessionExpired
+	}
+	return session, nil
+}
+
+// pruneMusig2Sessions adds the deletion of every expired session to the batch
+// and counts the open sessions of the public key.
+func (k *Keys) pruneMusig2Sessions(batch *leveldb.Batch, publicKey string) (int, error) {
+	iter := k.db.NewIterator(util.BytesPrefix([]byte(musig2SessionKeyPrefix)), nil)
+	defer iter.Release()
+	now := time.Now()
+	openSessions := 0
+	for iter.Next() {
+		key := append([]byte{}, iter.Key()...)
+		session, err := k.openMusig2Session(key, iter.Value())
+		if err != nil {
+			return 0, err
+		}
+		if session.expired(now) {
+			batch.Delete(key)
+		} else if session.PublicKey == publicKey {
+			openSessions++
+		}
+	}
+	return openSessions, iter.Error()
+}
+
+func (k *Keys) getMusig2Session(key []byte) (*Musig2Session, error) {
+	data, err := k.db.Get(key)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, ErrSessionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return k.openMusig2Session(key, data)
+}
+
+func (k *Keys) openMusig2Session(key []byte, sealed []byte) (*Musig2Session, error) {
+	data, err := open(k.aead, key, sealed)
+	if err != nil {
+		return nil, err
+	}
+	var session Musig2Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// expired reports whether the session is past its expiry, a session stored
+// without one is expired.
+func (s *Musig2Session) expired(now time.Time) bool {
+	return now.Unix() >= s.ExpiresAt
+}
@@ -0,0 +1,62 @@
+package leveldb
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMusig2SessionLifetime(t *testing.T) {
+	keys, err := NewKeyStore(t.TempDir(), "", "test passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer keys.db.Close()
+	live := time.Now().Add(time.Hour).Unix()
+	past := time.Now().Add(-time.Second).Unix()
+
+	if err := keys.StoreMusig2Session("expired", &Musig2Session{PublicKey: "02aa", ExpiresAt: past}, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.GetMusig2Session("expired"); !errors.Is(err, ErrSessionExpired) {
+		t.Fatalf("get expired session: %v", err)
+	}
+	if _, err := keys.GetMusig2Session("expired"); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expired session not deleted: %v", err)
+	}
+
+	tests := []struct {
+		id        string
+		publicKey string
+		expiresAt int64
+		wantErr   error
+	}{
+		{id: "a1", publicKey: "02aa", expiresAt: live},
+		{id: "a2", publicKey: "02aa", expiresAt: past},
+		// a2 is pruned, so a3 is the second open session of 02aa
+		{id: "a3", publicKey: "02aa", expiresAt: live},
+		{id: "a4", publicKey: "02aa", expiresAt: live, wantErr: ErrTooManySessions},
+		{id: "b1", publicKey: "02bb", expiresAt: live},
+		{id: "a1", publicKey: "02bb", expiresAt: live, wantErr: ErrSessionExists},
+	}
+	for _, tt := range tests {
+		err := keys.StoreMusig2Session(tt.id, &Musig2Session{PublicKey: tt.publicKey, ExpiresAt: tt.expiresAt}, 2)
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("store session %s: got %v, want %v", tt.id, err, tt.wantErr)
+		}
+	}
+	if _, err := keys.GetMusig2Session("a2"); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expired session a2 not pruned: %v", err)
+	}
+
+	if _, err := keys.TakeMusig2Session("a1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.TakeMusig2Session("a1"); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("take session twice: %v", err)
+	}
+	// taking a1 frees a slot of 02aa
+	if err := keys.StoreMusig2Session("a4", &Musig2Session{PublicKey: "02aa", ExpiresAt: live}, 2); err != nil {
+		t.Fatal(err)
+	}
+}
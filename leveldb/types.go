@@ -3,4 +3,30 @@ package leveldb
 type Key struct {
 	PrivateKey string
 	PubKey     string
+	// Path, PathTemplate and Index are set for keys derived from the hd seed
+	Path         string
+	PathTemplate string
+	Index        uint32
+	// Network is set for keys created for one network of the chain
+	Network string
+}
+
+type KeyMeta struct {
+	Path    string `json:"path"`
+	Index   uint32 `json:"index"`
+	Network string `json:"network,omitempty"`
+}
+
+// Musig2Session is the round one state of a MuSig2 signing session, SecNonce
+// is the secret nonce of the local key and must be used at most once.
+type Musig2Session struct {
+	Network    string   `json:"network"`
+	PublicKey  string   `json:"public_key"`
+	PublicKeys []string `json:"public_keys"`
+	MsgHash    string   `json:"msg_hash"`
+	MerkleRoot string   `json:"merkle_root,omitempty"`
+	PubNonce   string   `json:"pub_nonce"`
+	SecNonce   string   `json:"sec_nonce"`
+	// ExpiresAt is the unix time the session stops accepting round two
+	ExpiresAt int64 `json:"expires_at"`
 }
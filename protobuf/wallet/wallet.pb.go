@@ -69,7 +69,7 @@ func (ReturnCode) EnumDescriptor() ([]byte, []int) {
 
 type GetChainSignMethodRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Consumer      string                 `protobuf:"bytes,1,opt,name=consumer,proto3" json:"consumer,omitempty"`
+	ConsumerToken string                 `protobuf:"bytes,1,opt,name=consumer_token,json=consumerToken,proto3" json:"consumer_token,omitempty"`
 	ChainName     string                 `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
 	Network       string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
 	AssetType     string                 `protobuf:"bytes,4,opt,name=asset_type,json=assetType,proto3" json:"asset_type,omitempty"`
@@ -107,9 +107,9 @@ func (*GetChainSignMethodRequest) Descriptor() ([]byte, []int) {
 	return file_protobuf_wallet_proto_rawDescGZIP(), []int{0}
 }
 
-func (x *GetChainSignMethodRequest) GetConsumer() string {
+func (x *GetChainSignMethodRequest) GetConsumerToken() string {
 	if x != nil {
-		return x.Consumer
+		return x.ConsumerToken
 	}
 	return ""
 }
@@ -197,7 +197,7 @@ func (x *GetChainSignMethodResponse) GetSignMethod() string {
 
 type GetChainSchemaRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Consumer      string                 `protobuf:"bytes,1,opt,name=consumer,proto3" json:"consumer,omitempty"`
+	ConsumerToken string                 `protobuf:"bytes,1,opt,name=consumer_token,json=consumerToken,proto3" json:"consumer_token,omitempty"`
 	ChainName     string                 `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
 	Network       string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
 	AssetType     string                 `protobuf:"bytes,4,opt,name=asset_type,json=assetType,proto3" json:"asset_type,omitempty"`
@@ -235,9 +235,9 @@ func (*GetChainSchemaRequest) Descriptor() ([]byte, []int) {
 	return file_protobuf_wallet_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *GetChainSchemaRequest) GetConsumer() string {
+func (x *GetChainSchemaRequest) GetConsumerToken() string {
 	if x != nil {
-		return x.Consumer
+		return x.ConsumerToken
 	}
 	return ""
 }
@@ -699,17 +699,21 @@ func (x *CreateKeyPairsWithAddressesResponse) GetPublicKeyAddresses() []*ExportP
 	return nil
 }
 
+// wallet_key_hash 和 risk_key_hash 是对 chain_name, network, public_key, tx_base64_body
+// 按 key 排序的紧凑 JSON 对象计算的 HMAC-SHA256
 type BuildAndSignTransactionRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ConsumerToken string                 `protobuf:"bytes,1,opt,name=consumer_token,json=consumerToken,proto3" json:"consumer_token,omitempty"`
-	ChainName     string                 `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
-	Network       string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
-	PublicKey     string                 `protobuf:"bytes,4,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
-	WalletKeyHash string                 `protobuf:"bytes,5,opt,name=wallet_key_hash,json=walletKeyHash,proto3" json:"wallet_key_hash,omitempty"`
-	RiskKeyHash   string                 `protobuf:"bytes,6,opt,name=risk_key_hash,json=riskKeyHash,proto3" json:"risk_key_hash,omitempty"`
-	TxBase64Body  string                 `protobuf:"bytes,7,opt,name=tx_base64_body,json=txBase64Body,proto3" json:"tx_base64_body,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	ConsumerToken    string                 `protobuf:"bytes,1,opt,name=consumer_token,json=consumerToken,proto3" json:"consumer_token,omitempty"`
+	ChainName        string                 `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	Network          string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	PublicKey        string                 `protobuf:"bytes,4,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	WalletKeyHash    string                 `protobuf:"bytes,5,opt,name=wallet_key_hash,json=walletKeyHash,proto3" json:"wallet_key_hash,omitempty"`
+	RiskKeyHash      string                 `protobuf:"bytes,6,opt,name=risk_key_hash,json=riskKeyHash,proto3" json:"risk_key_hash,omitempty"`
+	TxBase64Body     string                 `protobuf:"bytes,7,opt,name=tx_base64_body,json=txBase64Body,proto3" json:"tx_base64_body,omitempty"`
+	WalletKeyVersion string                 `protobuf:"bytes,8,opt,name=wallet_key_version,json=walletKeyVersion,proto3" json:"wallet_key_version,omitempty"`
+	RiskKeyVersion   string                 `protobuf:"bytes,9,opt,name=risk_key_version,json=riskKeyVersion,proto3" json:"risk_key_version,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *BuildAndSignTransactionRequest) Reset() {
@@ -791,15 +795,31 @@ func (x *BuildAndSignTransactionRequest) GetTxBase64Body() string {
 	return ""
 }
 
+func (x *BuildAndSignTransactionRequest) GetWalletKeyVersion() string {
+	if x != nil {
+		return x.WalletKeyVersion
+	}
+	return ""
+}
+
+func (x *BuildAndSignTransactionRequest) GetRiskKeyVersion() string {
+	if x != nil {
+		return x.RiskKeyVersion
+	}
+	return ""
+}
+
 type BuildAndSignTransactionResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Code          ReturnCode             `protobuf:"varint,1,opt,name=code,proto3,enum=wallet.ReturnCode" json:"code,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	TxMessageHash string                 `protobuf:"bytes,3,opt,name=tx_message_hash,json=txMessageHash,proto3" json:"tx_message_hash,omitempty"`
-	TxHash        string                 `protobuf:"bytes,4,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
-	SignedTx      string                 `protobuf:"bytes,5,opt,name=signed_tx,json=signedTx,proto3" json:"signed_tx,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Code              ReturnCode             `protobuf:"varint,1,opt,name=code,proto3,enum=wallet.ReturnCode" json:"code,omitempty"`
+	Message           string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	TxMessageHash     string                 `protobuf:"bytes,3,opt,name=tx_message_hash,json=txMessageHash,proto3" json:"tx_message_hash,omitempty"`
+	TxHash            string                 `protobuf:"bytes,4,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	SignedTx          string                 `protobuf:"bytes,5,opt,name=signed_tx,json=signedTx,proto3" json:"signed_tx,omitempty"`
+	DecodedCall       string                 `protobuf:"bytes,6,opt,name=decoded_call,json=decodedCall,proto3" json:"decoded_call,omitempty"`
+	PartialSignatures string                 `protobuf:"bytes,7,opt,name=partial_signatures,json=partialSignatures,proto3" json:"partial_signatures,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *BuildAndSignTransactionResponse) Reset() {
@@ -867,14 +887,32 @@ func (x *BuildAndSignTransactionResponse) GetSignedTx() string {
 	return ""
 }
 
+func (x *BuildAndSignTransactionResponse) GetDecodedCall() string {
+	if x != nil {
+		return x.DecodedCall
+	}
+	return ""
+}
+
+func (x *BuildAndSignTransactionResponse) GetPartialSignatures() string {
+	if x != nil {
+		return x.PartialSignatures
+	}
+	return ""
+}
+
+// 批量签名的每一项单独审批, chain_name 和 network 取自批量请求,
+// 计算方式同 BuildAndSignTransactionRequest
 type TransactionMessage struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	PublicKey     string                 `protobuf:"bytes,1,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
-	WalletKeyHash string                 `protobuf:"bytes,2,opt,name=wallet_key_hash,json=walletKeyHash,proto3" json:"wallet_key_hash,omitempty"`
-	RiskKeyHash   string                 `protobuf:"bytes,3,opt,name=risk_key_hash,json=riskKeyHash,proto3" json:"risk_key_hash,omitempty"`
-	TxBase64Body  string                 `protobuf:"bytes,4,opt,name=tx_base64_body,json=txBase64Body,proto3" json:"tx_base64_body,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	PublicKey        string                 `protobuf:"bytes,1,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	WalletKeyHash    string                 `protobuf:"bytes,2,opt,name=wallet_key_hash,json=walletKeyHash,proto3" json:"wallet_key_hash,omitempty"`
+	RiskKeyHash      string                 `protobuf:"bytes,3,opt,name=risk_key_hash,json=riskKeyHash,proto3" json:"risk_key_hash,omitempty"`
+	TxBase64Body     string                 `protobuf:"bytes,4,opt,name=tx_base64_body,json=txBase64Body,proto3" json:"tx_base64_body,omitempty"`
+	WalletKeyVersion string                 `protobuf:"bytes,5,opt,name=wallet_key_version,json=walletKeyVersion,proto3" json:"wallet_key_version,omitempty"`
+	RiskKeyVersion   string                 `protobuf:"bytes,6,opt,name=risk_key_version,json=riskKeyVersion,proto3" json:"risk_key_version,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *TransactionMessage) Reset() {
@@ -935,13 +973,32 @@ func (x *TransactionMessage) GetTxBase64Body() string {
 	return ""
 }
 
+func (x *TransactionMessage) GetWalletKeyVersion() string {
+	if x != nil {
+		return x.WalletKeyVersion
+	}
+	return ""
+}
+
+func (x *TransactionMessage) GetRiskKeyVersion() string {
+	if x != nil {
+		return x.RiskKeyVersion
+	}
+	return ""
+}
+
 type TransactionWithSign struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	TxMessageHash string                 `protobuf:"bytes,1,opt,name=tx_message_hash,json=txMessageHash,proto3" json:"tx_message_hash,omitempty"`
-	TxHash        string                 `protobuf:"bytes,2,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
-	SignedTx      string                 `protobuf:"bytes,3,opt,name=signed_tx,json=signedTx,proto3" json:"signed_tx,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	TxMessageHash     string                 `protobuf:"bytes,1,opt,name=tx_message_hash,json=txMessageHash,proto3" json:"tx_message_hash,omitempty"`
+	TxHash            string                 `protobuf:"bytes,2,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	SignedTx          string                 `protobuf:"bytes,3,opt,name=signed_tx,json=signedTx,proto3" json:"signed_tx,omitempty"`
+	Code              ReturnCode             `protobuf:"varint,4,opt,name=code,proto3,enum=wallet.ReturnCode" json:"code,omitempty"`
+	Message           string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	PublicKey         string                 `protobuf:"bytes,6,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	DecodedCall       string                 `protobuf:"bytes,7,opt,name=decoded_call,json=decodedCall,proto3" json:"decoded_call,omitempty"`
+	PartialSignatures string                 `protobuf:"bytes,8,opt,name=partial_signatures,json=partialSignatures,proto3" json:"partial_signatures,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *TransactionWithSign) Reset() {
@@ -995,6 +1052,41 @@ func (x *TransactionWithSign) GetSignedTx() string {
 	return ""
 }
 
+func (x *TransactionWithSign) GetCode() ReturnCode {
+	if x != nil {
+		return x.Code
+	}
+	return ReturnCode_ERROR
+}
+
+func (x *TransactionWithSign) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *TransactionWithSign) GetPublicKey() string {
+	if x != nil {
+		return x.PublicKey
+	}
+	return ""
+}
+
+func (x *TransactionWithSign) GetDecodedCall() string {
+	if x != nil {
+		return x.DecodedCall
+	}
+	return ""
+}
+
+func (x *TransactionWithSign) GetPartialSignatures() string {
+	if x != nil {
+		return x.PartialSignatures
+	}
+	return ""
+}
+
 type BuildAndSignBatchTransactionRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ConsumerToken string                 `protobuf:"bytes,1,opt,name=consumer_token,json=consumerToken,proto3" json:"consumer_token,omitempty"`
@@ -1123,15 +1215,21 @@ func (x *BuildAndSignBatchTransactionResponse) GetTxWithSign() []*TransactionWit
 	return nil
 }
 
+// wallet_key_hash 和 risk_key_hash 是对 chain_name, network, public_key, message_hash
+// 按 key 排序的紧凑 JSON 对象计算的 HMAC-SHA256
 type SignTransactionMessageRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ConsumerToken string                 `protobuf:"bytes,1,opt,name=consumer_token,json=consumerToken,proto3" json:"consumer_token,omitempty"`
-	ChainName     string                 `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
-	Network       string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
-	PublicKey     string                 `protobuf:"bytes,4,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
-	MessageHash   string                 `protobuf:"bytes,5,opt,name=message_hash,json=messageHash,proto3" json:"message_hash,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	ConsumerToken    string                 `protobuf:"bytes,1,opt,name=consumer_token,json=consumerToken,proto3" json:"consumer_token,omitempty"`
+	ChainName        string                 `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	Network          string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	PublicKey        string                 `protobuf:"bytes,4,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	MessageHash      string                 `protobuf:"bytes,5,opt,name=message_hash,json=messageHash,proto3" json:"message_hash,omitempty"`
+	WalletKeyHash    string                 `protobuf:"bytes,6,opt,name=wallet_key_hash,json=walletKeyHash,proto3" json:"wallet_key_hash,omitempty"`
+	RiskKeyHash      string                 `protobuf:"bytes,7,opt,name=risk_key_hash,json=riskKeyHash,proto3" json:"risk_key_hash,omitempty"`
+	WalletKeyVersion string                 `protobuf:"bytes,8,opt,name=wallet_key_version,json=walletKeyVersion,proto3" json:"wallet_key_version,omitempty"`
+	RiskKeyVersion   string                 `protobuf:"bytes,9,opt,name=risk_key_version,json=riskKeyVersion,proto3" json:"risk_key_version,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *SignTransactionMessageRequest) Reset() {
@@ -1199,6 +1297,34 @@ func (x *SignTransactionMessageRequest) GetMessageHash() string {
 	return ""
 }
 
+func (x *SignTransactionMessageRequest) GetWalletKeyHash() string {
+	if x != nil {
+		return x.WalletKeyHash
+	}
+	return ""
+}
+
+func (x *SignTransactionMessageRequest) GetRiskKeyHash() string {
+	if x != nil {
+		return x.RiskKeyHash
+	}
+	return ""
+}
+
+func (x *SignTransactionMessageRequest) GetWalletKeyVersion() string {
+	if x != nil {
+		return x.WalletKeyVersion
+	}
+	return ""
+}
+
+func (x *SignTransactionMessageRequest) GetRiskKeyVersion() string {
+	if x != nil {
+		return x.RiskKeyVersion
+	}
+	return ""
+}
+
 type SignTransactionMessageResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Code          ReturnCode             `protobuf:"varint,1,opt,name=Code,proto3,enum=wallet.ReturnCode" json:"Code,omitempty"`
@@ -1259,117 +1385,2540 @@ func (x *SignTransactionMessageResponse) GetSignature() string {
 	return ""
 }
 
-var File_protobuf_wallet_proto protoreflect.FileDescriptor
+// wallet_key_hash 和 risk_key_hash 是对 chain_name, network, public_key, message
+// 按 key 排序的紧凑 JSON 对象计算的 HMAC-SHA256
+type SignPersonalMessageRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	ConsumerToken    string                 `protobuf:"bytes,1,opt,name=consumer_token,json=consumerToken,proto3" json:"consumer_token,omitempty"`
+	ChainName        string                 `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	Network          string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	PublicKey        string                 `protobuf:"bytes,4,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	Message          string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	WalletKeyHash    string                 `protobuf:"bytes,6,opt,name=wallet_key_hash,json=walletKeyHash,proto3" json:"wallet_key_hash,omitempty"`
+	RiskKeyHash      string                 `protobuf:"bytes,7,opt,name=risk_key_hash,json=riskKeyHash,proto3" json:"risk_key_hash,omitempty"`
+	WalletKeyVersion string                 `protobuf:"bytes,8,opt,name=wallet_key_version,json=walletKeyVersion,proto3" json:"wallet_key_version,omitempty"`
+	RiskKeyVersion   string                 `protobuf:"bytes,9,opt,name=risk_key_version,json=riskKeyVersion,proto3" json:"risk_key_version,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *SignPersonalMessageRequest) Reset() {
+	*x = SignPersonalMessageRequest{}
+	mi := &file_protobuf_wallet_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
 
-const file_protobuf_wallet_proto_rawDesc = "" +
-	"\n" +
-	"\x15protobuf/wallet.proto\x12\x06wallet\"\x8f\x01\n" +
-	"\x19GetChainSignMethodRequest\x12\x1a\n" +
-	"\bconsumer\x18\x01 \x01(\tR\bconsumer\x12\x1d\n" +
-	"\n" +
-	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
-	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x1d\n" +
-	"\n" +
-	"asset_type\x18\x04 \x01(\tR\tassetType\"w\n" +
-	"\x1aGetChainSignMethodResponse\x12&\n" +
-	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x10\n" +
-	"\x03msg\x18\x02 \x01(\tR\x03msg\x12\x1f\n" +
-	"\vsign_method\x18\x03 \x01(\tR\n" +
-	"signMethod\"\x8b\x01\n" +
-	"\x15getChainSchemaRequest\x12\x1a\n" +
-	"\bconsumer\x18\x01 \x01(\tR\bconsumer\x12\x1d\n" +
-	"\n" +
-	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
-	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x1d\n" +
-	"\n" +
-	"asset_type\x18\x04 \x01(\tR\tassetType\"r\n" +
-	"\x16getChainSchemaResponse\x12&\n" +
-	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\x12\x16\n" +
-	"\x06schema\x18\x03 \x01(\tR\x06schema\"`\n" +
-	"\x0fExportPublicKey\x12\x1d\n" +
-	"\n" +
-	"public_key\x18\x01 \x01(\tR\tpublicKey\x12.\n" +
-	"\x13compress_public_key\x18\x02 \x01(\tR\x11compressPublicKey\"\xa1\x01\n" +
-	"&CreateKeyPairAndExportPublicKeyRequest\x12%\n" +
-	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
-	"\n" +
-	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
-	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x17\n" +
-	"\akey_num\x18\x04 \x01(\x04R\x06keyNum\"\xac\x01\n" +
-	"'CreateKeyPairAndExportPublicKeyResponse\x12&\n" +
-	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\x12?\n" +
-	"\x0fpublic_key_list\x18\x03 \x03(\v2\x17.wallet.ExportPublicKeyR\rpublicKeyList\"\x85\x01\n" +
-	"\x1aExportPublicKeyWithAddress\x12\x1d\n" +
-	"\n" +
-	"public_key\x18\x01 \x01(\tR\tpublicKey\x12.\n" +
-	"\x13compress_public_key\x18\x02 \x01(\tR\x11compressPublicKey\x12\x18\n" +
-	"\aaddress\x18\x03 \x01(\tR\aaddress\"\xc4\x01\n" +
-	"\"CreateKeyPairsWithAddressesRequest\x12%\n" +
-	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
-	"\n" +
-	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
-	"\anetwork\x18\x03 \x01(\tR\anetwork\x12%\n" +
-	"\x0eaddress_format\x18\x04 \x01(\tR\raddressFormat\x12\x17\n" +
-	"\akey_num\x18\x05 \x01(\x04R\x06keyNum\"\xbd\x01\n" +
-	"#CreateKeyPairsWithAddressesResponse\x12&\n" +
-	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\x12T\n" +
-	"\x14public_key_addresses\x18\x03 \x03(\v2\".wallet.ExportPublicKeyWithAddressR\x12publicKeyAddresses\"\x91\x02\n" +
-	"\x1eBuildAndSignTransactionRequest\x12%\n" +
-	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
-	"\n" +
-	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
-	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x1d\n" +
-	"\n" +
-	"public_key\x18\x04 \x01(\tR\tpublicKey\x12&\n" +
-	"\x0fwallet_key_hash\x18\x05 \x01(\tR\rwalletKeyHash\x12\"\n" +
-	"\rrisk_key_hash\x18\x06 \x01(\tR\vriskKeyHash\x12$\n" +
-	"\x0etx_base64_body\x18\a \x01(\tR\ftxBase64Body\"\xc1\x01\n" +
-	"\x1fBuildAndSignTransactionResponse\x12&\n" +
-	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\x12&\n" +
-	"\x0ftx_message_hash\x18\x03 \x01(\tR\rtxMessageHash\x12\x17\n" +
-	"\atx_hash\x18\x04 \x01(\tR\x06txHash\x12\x1b\n" +
-	"\tsigned_tx\x18\x05 \x01(\tR\bsignedTx\"\xa5\x01\n" +
-	"\x12TransactionMessage\x12\x1d\n" +
-	"\n" +
-	"public_key\x18\x01 \x01(\tR\tpublicKey\x12&\n" +
-	"\x0fwallet_key_hash\x18\x02 \x01(\tR\rwalletKeyHash\x12\"\n" +
-	"\rrisk_key_hash\x18\x03 \x01(\tR\vriskKeyHash\x12$\n" +
-	"\x0etx_base64_body\x18\x04 \x01(\tR\ftxBase64Body\"s\n" +
-	"\x13TransactionWithSign\x12&\n" +
-	"\x0ftx_message_hash\x18\x01 \x01(\tR\rtxMessageHash\x12\x17\n" +
-	"\atx_hash\x18\x02 \x01(\tR\x06txHash\x12\x1b\n" +
-	"\tsigned_tx\x18\x03 \x01(\tR\bsignedTx\"\xb8\x01\n" +
-	"#BuildAndSignBatchTransactionRequest\x12%\n" +
-	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
-	"\n" +
-	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
-	"\anetwork\x18\x03 \x01(\tR\anetwork\x121\n" +
-	"\x06tx_msg\x18\x04 \x03(\v2\x1a.wallet.TransactionMessageR\x05txMsg\"\xa7\x01\n" +
-	"$BuildAndSignBatchTransactionResponse\x12&\n" +
-	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\x12=\n" +
-	"\ftx_with_sign\x18\x03 \x03(\v2\x1b.wallet.TransactionWithSignR\n" +
-	"txWithSign\"\xc1\x01\n" +
-	"\x1dSignTransactionMessageRequest\x12%\n" +
-	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
-	"\n" +
-	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
-	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x1d\n" +
-	"\n" +
-	"public_key\x18\x04 \x01(\tR\tpublicKey\x12!\n" +
-	"\fmessage_hash\x18\x05 \x01(\tR\vmessageHash\"\x80\x01\n" +
-	"\x1eSignTransactionMessageResponse\x12&\n" +
-	"\x04Code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04Code\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1c\n" +
-	"\tsignature\x18\x03 \x01(\tR\tsignature*$\n" +
+func (x *SignPersonalMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignPersonalMessageRequest) ProtoMessage() {}
+
+func (x *SignPersonalMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignPersonalMessageRequest.ProtoReflect.Descriptor instead.
+func (*SignPersonalMessageRequest) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SignPersonalMessageRequest) GetConsumerToken() string {
+	if x != nil {
+		return x.ConsumerToken
+	}
+	return ""
+}
+
+func (x *SignPersonalMessageRequest) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *SignPersonalMessageRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *SignPersonalMessageRequest) GetPublicKey() string {
+	if x != nil {
+		return x.PublicKey
+	}
+	return ""
+}
+
+func (x *SignPersonalMessageRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SignPersonalMessageRequest) GetWalletKeyHash() string {
+	if x != nil {
+		return x.WalletKeyHash
+	}
+	return ""
+}
+
+func (x *SignPersonalMessageRequest) GetRiskKeyHash() string {
+	if x != nil {
+		return x.RiskKeyHash
+	}
+	return ""
+}
+
+func (x *SignPersonalMessageRequest) GetWalletKeyVersion() string {
+	if x != nil {
+		return x.WalletKeyVersion
+	}
+	return ""
+}
+
+func (x *SignPersonalMessageRequest) GetRiskKeyVersion() string {
+	if x != nil {
+		return x.RiskKeyVersion
+	}
+	return ""
+}
+
+type SignPersonalMessageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          ReturnCode             `protobuf:"varint,1,opt,name=code,proto3,enum=wallet.ReturnCode" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Signature     string                 `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+	MessageHash   string                 `protobuf:"bytes,4,opt,name=message_hash,json=messageHash,proto3" json:"message_hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignPersonalMessageResponse) Reset() {
+	*x = SignPersonalMessageResponse{}
+	mi := &file_protobuf_wallet_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignPersonalMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignPersonalMessageResponse) ProtoMessage() {}
+
+func (x *SignPersonalMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignPersonalMessageResponse.ProtoReflect.Descriptor instead.
+func (*SignPersonalMessageResponse) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SignPersonalMessageResponse) GetCode() ReturnCode {
+	if x != nil {
+		return x.Code
+	}
+	return ReturnCode_ERROR
+}
+
+func (x *SignPersonalMessageResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SignPersonalMessageResponse) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *SignPersonalMessageResponse) GetMessageHash() string {
+	if x != nil {
+		return x.MessageHash
+	}
+	return ""
+}
+
+// wallet_key_hash 和 risk_key_hash 是对 chain_name, network, public_key, typed_data
+// 按 key 排序的紧凑 JSON 对象计算的 HMAC-SHA256
+type SignTypedDataRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	ConsumerToken    string                 `protobuf:"bytes,1,opt,name=consumer_token,json=consumerToken,proto3" json:"consumer_token,omitempty"`
+	ChainName        string                 `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	Network          string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	PublicKey        string                 `protobuf:"bytes,4,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	TypedData        string                 `protobuf:"bytes,5,opt,name=typed_data,json=typedData,proto3" json:"typed_data,omitempty"`
+	WalletKeyHash    string                 `protobuf:"bytes,6,opt,name=wallet_key_hash,json=walletKeyHash,proto3" json:"wallet_key_hash,omitempty"`
+	RiskKeyHash      string                 `protobuf:"bytes,7,opt,name=risk_key_hash,json=riskKeyHash,proto3" json:"risk_key_hash,omitempty"`
+	WalletKeyVersion string                 `protobuf:"bytes,8,opt,name=wallet_key_version,json=walletKeyVersion,proto3" json:"wallet_key_version,omitempty"`
+	RiskKeyVersion   string                 `protobuf:"bytes,9,opt,name=risk_key_version,json=riskKeyVersion,proto3" json:"risk_key_version,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *SignTypedDataRequest) Reset() {
+	*x = SignTypedDataRequest{}
+	mi := &file_protobuf_wallet_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignTypedDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignTypedDataRequest) ProtoMessage() {}
+
+func (x *SignTypedDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignTypedDataRequest.ProtoReflect.Descriptor instead.
+func (*SignTypedDataRequest) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SignTypedDataRequest) GetConsumerToken() string {
+	if x != nil {
+		return x.ConsumerToken
+	}
+	return ""
+}
+
+func (x *SignTypedDataRequest) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *SignTypedDataRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *SignTypedDataRequest) GetPublicKey() string {
+	if x != nil {
+		return x.PublicKey
+	}
+	return ""
+}
+
+func (x *SignTypedDataRequest) GetTypedData() string {
+	if x != nil {
+		return x.TypedData
+	}
+	return ""
+}
+
+func (x *SignTypedDataRequest) GetWalletKeyHash() string {
+	if x != nil {
+		return x.WalletKeyHash
+	}
+	return ""
+}
+
+func (x *SignTypedDataRequest) GetRiskKeyHash() string {
+	if x != nil {
+		return x.RiskKeyHash
+	}
+	return ""
+}
+
+func (x *SignTypedDataRequest) GetWalletKeyVersion() string {
+	if x != nil {
+		return x.WalletKeyVersion
+	}
+	return ""
+}
+
+func (x *SignTypedDataRequest) GetRiskKeyVersion() string {
+	if x != nil {
+		return x.RiskKeyVersion
+	}
+	return ""
+}
+
+type SignTypedDataResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          ReturnCode             `protobuf:"varint,1,opt,name=code,proto3,enum=wallet.ReturnCode" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Signature     string                 `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+	MessageHash   string                 `protobuf:"bytes,4,opt,name=message_hash,json=messageHash,proto3" json:"message_hash,omitempty"`
+	Domain        string                 `protobuf:"bytes,5,opt,name=domain,proto3" json:"domain,omitempty"`
+	PrimaryType   string                 `protobuf:"bytes,6,opt,name=primary_type,json=primaryType,proto3" json:"primary_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignTypedDataResponse) Reset() {
+	*x = SignTypedDataResponse{}
+	mi := &file_protobuf_wallet_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignTypedDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignTypedDataResponse) ProtoMessage() {}
+
+func (x *SignTypedDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignTypedDataResponse.ProtoReflect.Descriptor instead.
+func (*SignTypedDataResponse) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SignTypedDataResponse) GetCode() ReturnCode {
+	if x != nil {
+		return x.Code
+	}
+	return ReturnCode_ERROR
+}
+
+func (x *SignTypedDataResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SignTypedDataResponse) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *SignTypedDataResponse) GetMessageHash() string {
+	if x != nil {
+		return x.MessageHash
+	}
+	return ""
+}
+
+func (x *SignTypedDataResponse) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *SignTypedDataResponse) GetPrimaryType() string {
+	if x != nil {
+		return x.PrimaryType
+	}
+	return ""
+}
+
+// wallet_key_hash 和 risk_key_hash 是对 chain_name, network, public_key, permit_base64_body
+// 按 key 排序的紧凑 JSON 对象计算的 HMAC-SHA256
+type BuildAndSignPermitRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	ConsumerToken    string                 `protobuf:"bytes,1,opt,name=consumer_token,json=consumerToken,proto3" json:"consumer_token,omitempty"`
+	ChainName        string                 `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	Network          string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	PublicKey        string                 `protobuf:"bytes,4,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	PermitBase64Body string                 `protobuf:"bytes,5,opt,name=permit_base64_body,json=permitBase64Body,proto3" json:"permit_base64_body,omitempty"`
+	WalletKeyHash    string                 `protobuf:"bytes,6,opt,name=wallet_key_hash,json=walletKeyHash,proto3" json:"wallet_key_hash,omitempty"`
+	RiskKeyHash      string                 `protobuf:"bytes,7,opt,name=risk_key_hash,json=riskKeyHash,proto3" json:"risk_key_hash,omitempty"`
+	WalletKeyVersion string                 `protobuf:"bytes,8,opt,name=wallet_key_version,json=walletKeyVersion,proto3" json:"wallet_key_version,omitempty"`
+	RiskKeyVersion   string                 `protobuf:"bytes,9,opt,name=risk_key_version,json=riskKeyVersion,proto3" json:"risk_key_version,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *BuildAndSignPermitRequest) Reset() {
+	*x = BuildAndSignPermitRequest{}
+	mi := &file_protobuf_wallet_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BuildAndSignPermitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuildAndSignPermitRequest) ProtoMessage() {}
+
+func (x *BuildAndSignPermitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuildAndSignPermitRequest.ProtoReflect.Descriptor instead.
+func (*BuildAndSignPermitRequest) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *BuildAndSignPermitRequest) GetConsumerToken() string {
+	if x != nil {
+		return x.ConsumerToken
+	}
+	return ""
+}
+
+func (x *BuildAndSignPermitRequest) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *BuildAndSignPermitRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *BuildAndSignPermitRequest) GetPublicKey() string {
+	if x != nil {
+		return x.PublicKey
+	}
+	return ""
+}
+
+func (x *BuildAndSignPermitRequest) GetPermitBase64Body() string {
+	if x != nil {
+		return x.PermitBase64Body
+	}
+	return ""
+}
+
+func (x *BuildAndSignPermitRequest) GetWalletKeyHash() string {
+	if x != nil {
+		return x.WalletKeyHash
+	}
+	return ""
+}
+
+func (x *BuildAndSignPermitRequest) GetRiskKeyHash() string {
+	if x != nil {
+		return x.RiskKeyHash
+	}
+	return ""
+}
+
+func (x *BuildAndSignPermitRequest) GetWalletKeyVersion() string {
+	if x != nil {
+		return x.WalletKeyVersion
+	}
+	return ""
+}
+
+func (x *BuildAndSignPermitRequest) GetRiskKeyVersion() string {
+	if x != nil {
+		return x.RiskKeyVersion
+	}
+	return ""
+}
+
+type BuildAndSignPermitResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          ReturnCode             `protobuf:"varint,1,opt,name=code,proto3,enum=wallet.ReturnCode" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Signature     string                 `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+	V             uint32                 `protobuf:"varint,4,opt,name=v,proto3" json:"v,omitempty"`
+	R             string                 `protobuf:"bytes,5,opt,name=r,proto3" json:"r,omitempty"`
+	S             string                 `protobuf:"bytes,6,opt,name=s,proto3" json:"s,omitempty"`
+	MessageHash   string                 `protobuf:"bytes,7,opt,name=message_hash,json=messageHash,proto3" json:"message_hash,omitempty"`
+	TypedData     string                 `protobuf:"bytes,8,opt,name=typed_data,json=typedData,proto3" json:"typed_data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BuildAndSignPermitResponse) Reset() {
+	*x = BuildAndSignPermitResponse{}
+	mi := &file_protobuf_wallet_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BuildAndSignPermitResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuildAndSignPermitResponse) ProtoMessage() {}
+
+func (x *BuildAndSignPermitResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuildAndSignPermitResponse.ProtoReflect.Descriptor instead.
+func (*BuildAndSignPermitResponse) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *BuildAndSignPermitResponse) GetCode() ReturnCode {
+	if x != nil {
+		return x.Code
+	}
+	return ReturnCode_ERROR
+}
+
+func (x *BuildAndSignPermitResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *BuildAndSignPermitResponse) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *BuildAndSignPermitResponse) GetV() uint32 {
+	if x != nil {
+		return x.V
+	}
+	return 0
+}
+
+func (x *BuildAndSignPermitResponse) GetR() string {
+	if x != nil {
+		return x.R
+	}
+	return ""
+}
+
+func (x *BuildAndSignPermitResponse) GetS() string {
+	if x != nil {
+		return x.S
+	}
+	return ""
+}
+
+func (x *BuildAndSignPermitResponse) GetMessageHash() string {
+	if x != nil {
+		return x.MessageHash
+	}
+	return ""
+}
+
+func (x *BuildAndSignPermitResponse) GetTypedData() string {
+	if x != nil {
+		return x.TypedData
+	}
+	return ""
+}
+
+// wallet_key_hash 和 risk_key_hash 是对 chain_name, network, public_key, user_op_base64_body
+// 按 key 排序的紧凑 JSON 对象计算的 HMAC-SHA256
+type BuildAndSignUserOperationRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	ConsumerToken    string                 `protobuf:"bytes,1,opt,name=consumer_token,json=consumerToken,proto3" json:"consumer_token,omitempty"`
+	ChainName        string                 `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	Network          string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	PublicKey        string                 `protobuf:"bytes,4,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	UserOpBase64Body string                 `protobuf:"bytes,5,opt,name=user_op_base64_body,json=userOpBase64Body,proto3" json:"user_op_base64_body,omitempty"`
+	WalletKeyHash    string                 `protobuf:"bytes,6,opt,name=wallet_key_hash,json=walletKeyHash,proto3" json:"wallet_key_hash,omitempty"`
+	RiskKeyHash      string                 `protobuf:"bytes,7,opt,name=risk_key_hash,json=riskKeyHash,proto3" json:"risk_key_hash,omitempty"`
+	WalletKeyVersion string                 `protobuf:"bytes,8,opt,name=wallet_key_version,json=walletKeyVersion,proto3" json:"wallet_key_version,omitempty"`
+	RiskKeyVersion   string                 `protobuf:"bytes,9,opt,name=risk_key_version,json=riskKeyVersion,proto3" json:"risk_key_version,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *BuildAndSignUserOperationRequest) Reset() {
+	*x = BuildAndSignUserOperationRequest{}
+	mi := &file_protobuf_wallet_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BuildAndSignUserOperationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuildAndSignUserOperationRequest) ProtoMessage() {}
+
+func (x *BuildAndSignUserOperationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuildAndSignUserOperationRequest.ProtoReflect.Descriptor instead.
+func (*BuildAndSignUserOperationRequest) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *BuildAndSignUserOperationRequest) GetConsumerToken() string {
+	if x != nil {
+		return x.ConsumerToken
+	}
+	return ""
+}
+
+func (x *BuildAndSignUserOperationRequest) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *BuildAndSignUserOperationRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *BuildAndSignUserOperationRequest) GetPublicKey() string {
+	if x != nil {
+		return x.PublicKey
+	}
+	return ""
+}
+
+func (x *BuildAndSignUserOperationRequest) GetUserOpBase64Body() string {
+	if x != nil {
+		return x.UserOpBase64Body
+	}
+	return ""
+}
+
+func (x *BuildAndSignUserOperationRequest) GetWalletKeyHash() string {
+	if x != nil {
+		return x.WalletKeyHash
+	}
+	return ""
+}
+
+func (x *BuildAndSignUserOperationRequest) GetRiskKeyHash() string {
+	if x != nil {
+		return x.RiskKeyHash
+	}
+	return ""
+}
+
+func (x *BuildAndSignUserOperationRequest) GetWalletKeyVersion() string {
+	if x != nil {
+		return x.WalletKeyVersion
+	}
+	return ""
+}
+
+func (x *BuildAndSignUserOperationRequest) GetRiskKeyVersion() string {
+	if x != nil {
+		return x.RiskKeyVersion
+	}
+	return ""
+}
+
+type BuildAndSignUserOperationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          ReturnCode             `protobuf:"varint,1,opt,name=code,proto3,enum=wallet.ReturnCode" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	UserOpHash    string                 `protobuf:"bytes,3,opt,name=user_op_hash,json=userOpHash,proto3" json:"user_op_hash,omitempty"`
+	Signature     string                 `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+	UserOperation string                 `protobuf:"bytes,5,opt,name=user_operation,json=userOperation,proto3" json:"user_operation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BuildAndSignUserOperationResponse) Reset() {
+	*x = BuildAndSignUserOperationResponse{}
+	mi := &file_protobuf_wallet_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BuildAndSignUserOperationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuildAndSignUserOperationResponse) ProtoMessage() {}
+
+func (x *BuildAndSignUserOperationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuildAndSignUserOperationResponse.ProtoReflect.Descriptor instead.
+func (*BuildAndSignUserOperationResponse) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *BuildAndSignUserOperationResponse) GetCode() ReturnCode {
+	if x != nil {
+		return x.Code
+	}
+	return ReturnCode_ERROR
+}
+
+func (x *BuildAndSignUserOperationResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *BuildAndSignUserOperationResponse) GetUserOpHash() string {
+	if x != nil {
+		return x.UserOpHash
+	}
+	return ""
+}
+
+func (x *BuildAndSignUserOperationResponse) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *BuildAndSignUserOperationResponse) GetUserOperation() string {
+	if x != nil {
+		return x.UserOperation
+	}
+	return ""
+}
+
+// wallet_key_hash 和 risk_key_hash 是对 chain_name, network, public_key, safe_tx_base64_body
+// 按 key 排序的紧凑 JSON 对象计算的 HMAC-SHA256
+type BuildAndSignSafeTransactionRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	ConsumerToken    string                 `protobuf:"bytes,1,opt,name=consumer_token,json=consumerToken,proto3" json:"consumer_token,omitempty"`
+	ChainName        string                 `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	Network          string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	PublicKey        string                 `protobuf:"bytes,4,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	SafeTxBase64Body string                 `protobuf:"bytes,5,opt,name=safe_tx_base64_body,json=safeTxBase64Body,proto3" json:"safe_tx_base64_body,omitempty"`
+	WalletKeyHash    string                 `protobuf:"bytes,6,opt,name=wallet_key_hash,json=walletKeyHash,proto3" json:"wallet_key_hash,omitempty"`
+	RiskKeyHash      string                 `protobuf:"bytes,7,opt,name=risk_key_hash,json=riskKeyHash,proto3" json:"risk_key_hash,omitempty"`
+	WalletKeyVersion string                 `protobuf:"bytes,8,opt,name=wallet_key_version,json=walletKeyVersion,proto3" json:"wallet_key_version,omitempty"`
+	RiskKeyVersion   string                 `protobuf:"bytes,9,opt,name=risk_key_version,json=riskKeyVersion,proto3" json:"risk_key_version,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *BuildAndSignSafeTransactionRequest) Reset() {
+	*x = BuildAndSignSafeTransactionRequest{}
+	mi := &file_protobuf_wallet_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BuildAndSignSafeTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuildAndSignSafeTransactionRequest) ProtoMessage() {}
+
+func (x *BuildAndSignSafeTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuildAndSignSafeTransactionRequest.ProtoReflect.Descriptor instead.
+func (*BuildAndSignSafeTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *BuildAndSignSafeTransactionRequest) GetConsumerToken() string {
+	if x != nil {
+		return x.ConsumerToken
+	}
+	return ""
+}
+
+func (x *BuildAndSignSafeTransactionRequest) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *BuildAndSignSafeTransactionRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *BuildAndSignSafeTransactionRequest) GetPublicKey() string {
+	if x != nil {
+		return x.PublicKey
+	}
+	return ""
+}
+
+func (x *BuildAndSignSafeTransactionRequest) GetSafeTxBase64Body() string {
+	if x != nil {
+		return x.SafeTxBase64Body
+	}
+	return ""
+}
+
+func (x *BuildAndSignSafeTransactionRequest) GetWalletKeyHash() string {
+	if x != nil {
+		return x.WalletKeyHash
+	}
+	return ""
+}
+
+func (x *BuildAndSignSafeTransactionRequest) GetRiskKeyHash() string {
+	if x != nil {
+		return x.RiskKeyHash
+	}
+	return ""
+}
+
+func (x *BuildAndSignSafeTransactionRequest) GetWalletKeyVersion() string {
+	if x != nil {
+		return x.WalletKeyVersion
+	}
+	return ""
+}
+
+func (x *BuildAndSignSafeTransactionRequest) GetRiskKeyVersion() string {
+	if x != nil {
+		return x.RiskKeyVersion
+	}
+	return ""
+}
+
+type BuildAndSignSafeTransactionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          ReturnCode             `protobuf:"varint,1,opt,name=code,proto3,enum=wallet.ReturnCode" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	SafeTxHash    string                 `protobuf:"bytes,3,opt,name=safe_tx_hash,json=safeTxHash,proto3" json:"safe_tx_hash,omitempty"`
+	Signature     string                 `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+	Owner         string                 `protobuf:"bytes,5,opt,name=owner,proto3" json:"owner,omitempty"`
+	DecodedCall   string                 `protobuf:"bytes,6,opt,name=decoded_call,json=decodedCall,proto3" json:"decoded_call,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BuildAndSignSafeTransactionResponse) Reset() {
+	*x = BuildAndSignSafeTransactionResponse{}
+	mi := &file_protobuf_wallet_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BuildAndSignSafeTransactionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuildAndSignSafeTransactionResponse) ProtoMessage() {}
+
+func (x *BuildAndSignSafeTransactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuildAndSignSafeTransactionResponse.ProtoReflect.Descriptor instead.
+func (*BuildAndSignSafeTransactionResponse) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *BuildAndSignSafeTransactionResponse) GetCode() ReturnCode {
+	if x != nil {
+		return x.Code
+	}
+	return ReturnCode_ERROR
+}
+
+func (x *BuildAndSignSafeTransactionResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *BuildAndSignSafeTransactionResponse) GetSafeTxHash() string {
+	if x != nil {
+		return x.SafeTxHash
+	}
+	return ""
+}
+
+func (x *BuildAndSignSafeTransactionResponse) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *BuildAndSignSafeTransactionResponse) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *BuildAndSignSafeTransactionResponse) GetDecodedCall() string {
+	if x != nil {
+		return x.DecodedCall
+	}
+	return ""
+}
+
+type SignPsbtRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	ConsumerToken    string                 `protobuf:"bytes,1,opt,name=consumer_token,json=consumerToken,proto3" json:"consumer_token,omitempty"`
+	ChainName        string                 `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	Network          string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	PublicKey        string                 `protobuf:"bytes,4,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	PsbtBase64       string                 `protobuf:"bytes,5,opt,name=psbt_base64,json=psbtBase64,proto3" json:"psbt_base64,omitempty"`
+	WalletKeyHash    string                 `protobuf:"bytes,6,opt,name=wallet_key_hash,json=walletKeyHash,proto3" json:"wallet_key_hash,omitempty"`
+	RiskKeyHash      string                 `protobuf:"bytes,7,opt,name=risk_key_hash,json=riskKeyHash,proto3" json:"risk_key_hash,omitempty"`
+	WalletKeyVersion string                 `protobuf:"bytes,8,opt,name=wallet_key_version,json=walletKeyVersion,proto3" json:"wallet_key_version,omitempty"`
+	RiskKeyVersion   string                 `protobuf:"bytes,9,opt,name=risk_key_version,json=riskKeyVersion,proto3" json:"risk_key_version,omitempty"`
+	Finalize         bool                   `protobuf:"varint,10,opt,name=finalize,proto3" json:"finalize,omitempty"`
+	// 允许 SIGHASH_ALL 和 SIGHASH_DEFAULT 以外的 sighash, consumer 需要配置 allow_any_sighash
+	AllowAnySighash bool `protobuf:"varint,11,opt,name=allow_any_sighash,json=allowAnySighash,proto3" json:"allow_any_sighash,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SignPsbtRequest) Reset() {
+	*x = SignPsbtRequest{}
+	mi := &file_protobuf_wallet_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignPsbtRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignPsbtRequest) ProtoMessage() {}
+
+func (x *SignPsbtRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignPsbtRequest.ProtoReflect.Descriptor instead.
+func (*SignPsbtRequest) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *SignPsbtRequest) GetConsumerToken() string {
+	if x != nil {
+		return x.ConsumerToken
+	}
+	return ""
+}
+
+func (x *SignPsbtRequest) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *SignPsbtRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *SignPsbtRequest) GetPublicKey() string {
+	if x != nil {
+		return x.PublicKey
+	}
+	return ""
+}
+
+func (x *SignPsbtRequest) GetPsbtBase64() string {
+	if x != nil {
+		return x.PsbtBase64
+	}
+	return ""
+}
+
+func (x *SignPsbtRequest) GetWalletKeyHash() string {
+	if x != nil {
+		return x.WalletKeyHash
+	}
+	return ""
+}
+
+func (x *SignPsbtRequest) GetRiskKeyHash() string {
+	if x != nil {
+		return x.RiskKeyHash
+	}
+	return ""
+}
+
+func (x *SignPsbtRequest) GetWalletKeyVersion() string {
+	if x != nil {
+		return x.WalletKeyVersion
+	}
+	return ""
+}
+
+func (x *SignPsbtRequest) GetRiskKeyVersion() string {
+	if x != nil {
+		return x.RiskKeyVersion
+	}
+	return ""
+}
+
+func (x *SignPsbtRequest) GetFinalize() bool {
+	if x != nil {
+		return x.Finalize
+	}
+	return false
+}
+
+func (x *SignPsbtRequest) GetAllowAnySighash() bool {
+	if x != nil {
+		return x.AllowAnySighash
+	}
+	return false
+}
+
+type SignPsbtResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          ReturnCode             `protobuf:"varint,1,opt,name=code,proto3,enum=wallet.ReturnCode" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	PsbtBase64    string                 `protobuf:"bytes,3,opt,name=psbt_base64,json=psbtBase64,proto3" json:"psbt_base64,omitempty"`
+	SignedInputs  []uint32               `protobuf:"varint,4,rep,packed,name=signed_inputs,json=signedInputs,proto3" json:"signed_inputs,omitempty"`
+	Complete      bool                   `protobuf:"varint,5,opt,name=complete,proto3" json:"complete,omitempty"`
+	SignedTx      string                 `protobuf:"bytes,6,opt,name=signed_tx,json=signedTx,proto3" json:"signed_tx,omitempty"`
+	TxHash        string                 `protobuf:"bytes,7,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignPsbtResponse) Reset() {
+	*x = SignPsbtResponse{}
+	mi := &file_protobuf_wallet_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignPsbtResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignPsbtResponse) ProtoMessage() {}
+
+func (x *SignPsbtResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignPsbtResponse.ProtoReflect.Descriptor instead.
+func (*SignPsbtResponse) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *SignPsbtResponse) GetCode() ReturnCode {
+	if x != nil {
+		return x.Code
+	}
+	return ReturnCode_ERROR
+}
+
+func (x *SignPsbtResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SignPsbtResponse) GetPsbtBase64() string {
+	if x != nil {
+		return x.PsbtBase64
+	}
+	return ""
+}
+
+func (x *SignPsbtResponse) GetSignedInputs() []uint32 {
+	if x != nil {
+		return x.SignedInputs
+	}
+	return nil
+}
+
+func (x *SignPsbtResponse) GetComplete() bool {
+	if x != nil {
+		return x.Complete
+	}
+	return false
+}
+
+func (x *SignPsbtResponse) GetSignedTx() string {
+	if x != nil {
+		return x.SignedTx
+	}
+	return ""
+}
+
+func (x *SignPsbtResponse) GetTxHash() string {
+	if x != nil {
+		return x.TxHash
+	}
+	return ""
+}
+
+type CreateMultisigAddressRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConsumerToken string                 `protobuf:"bytes,1,opt,name=consumer_token,json=consumerToken,proto3" json:"consumer_token,omitempty"`
+	ChainName     string                 `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	Network       string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	AddressFormat string                 `protobuf:"bytes,4,opt,name=address_format,json=addressFormat,proto3" json:"address_format,omitempty"`
+	Threshold     uint32                 `protobuf:"varint,5,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	PublicKeys    []string               `protobuf:"bytes,6,rep,name=public_keys,json=publicKeys,proto3" json:"public_keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateMultisigAddressRequest) Reset() {
+	*x = CreateMultisigAddressRequest{}
+	mi := &file_protobuf_wallet_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateMultisigAddressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateMultisigAddressRequest) ProtoMessage() {}
+
+func (x *CreateMultisigAddressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateMultisigAddressRequest.ProtoReflect.Descriptor instead.
+func (*CreateMultisigAddressRequest) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *CreateMultisigAddressRequest) GetConsumerToken() string {
+	if x != nil {
+		return x.ConsumerToken
+	}
+	return ""
+}
+
+func (x *CreateMultisigAddressRequest) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *CreateMultisigAddressRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *CreateMultisigAddressRequest) GetAddressFormat() string {
+	if x != nil {
+		return x.AddressFormat
+	}
+	return ""
+}
+
+func (x *CreateMultisigAddressRequest) GetThreshold() uint32 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *CreateMultisigAddressRequest) GetPublicKeys() []string {
+	if x != nil {
+		return x.PublicKeys
+	}
+	return nil
+}
+
+type CreateMultisigAddressResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Code            ReturnCode             `protobuf:"varint,1,opt,name=code,proto3,enum=wallet.ReturnCode" json:"code,omitempty"`
+	Message         string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Address         string                 `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	WitnessScript   string                 `protobuf:"bytes,4,opt,name=witness_script,json=witnessScript,proto3" json:"witness_script,omitempty"`
+	RedeemScript    string                 `protobuf:"bytes,5,opt,name=redeem_script,json=redeemScript,proto3" json:"redeem_script,omitempty"`
+	PublicKeys      []string               `protobuf:"bytes,6,rep,name=public_keys,json=publicKeys,proto3" json:"public_keys,omitempty"`
+	LocalPublicKeys []string               `protobuf:"bytes,7,rep,name=local_public_keys,json=localPublicKeys,proto3" json:"local_public_keys,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CreateMultisigAddressResponse) Reset() {
+	*x = CreateMultisigAddressResponse{}
+	mi := &file_protobuf_wallet_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateMultisigAddressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateMultisigAddressResponse) ProtoMessage() {}
+
+func (x *CreateMultisigAddressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateMultisigAddressResponse.ProtoReflect.Descriptor instead.
+func (*CreateMultisigAddressResponse) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *CreateMultisigAddressResponse) GetCode() ReturnCode {
+	if x != nil {
+		return x.Code
+	}
+	return ReturnCode_ERROR
+}
+
+func (x *CreateMultisigAddressResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CreateMultisigAddressResponse) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *CreateMultisigAddressResponse) GetWitnessScript() string {
+	if x != nil {
+		return x.WitnessScript
+	}
+	return ""
+}
+
+func (x *CreateMultisigAddressResponse) GetRedeemScript() string {
+	if x != nil {
+		return x.RedeemScript
+	}
+	return ""
+}
+
+func (x *CreateMultisigAddressResponse) GetPublicKeys() []string {
+	if x != nil {
+		return x.PublicKeys
+	}
+	return nil
+}
+
+func (x *CreateMultisigAddressResponse) GetLocalPublicKeys() []string {
+	if x != nil {
+		return x.LocalPublicKeys
+	}
+	return nil
+}
+
+type CreateMusig2AddressRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConsumerToken string                 `protobuf:"bytes,1,opt,name=consumer_token,json=consumerToken,proto3" json:"consumer_token,omitempty"`
+	ChainName     string                 `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	Network       string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	PublicKeys    []string               `protobuf:"bytes,4,rep,name=public_keys,json=publicKeys,proto3" json:"public_keys,omitempty"`
+	MerkleRoot    string                 `protobuf:"bytes,5,opt,name=merkle_root,json=merkleRoot,proto3" json:"merkle_root,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateMusig2AddressRequest) Reset() {
+	*x = CreateMusig2AddressRequest{}
+	mi := &file_protobuf_wallet_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateMusig2AddressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateMusig2AddressRequest) ProtoMessage() {}
+
+func (x *CreateMusig2AddressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateMusig2AddressRequest.ProtoReflect.Descriptor instead.
+func (*CreateMusig2AddressRequest) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *CreateMusig2AddressRequest) GetConsumerToken() string {
+	if x != nil {
+		return x.ConsumerToken
+	}
+	return ""
+}
+
+func (x *CreateMusig2AddressRequest) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *CreateMusig2AddressRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *CreateMusig2AddressRequest) GetPublicKeys() []string {
+	if x != nil {
+		return x.PublicKeys
+	}
+	return nil
+}
+
+func (x *CreateMusig2AddressRequest) GetMerkleRoot() string {
+	if x != nil {
+		return x.MerkleRoot
+	}
+	return ""
+}
+
+type CreateMusig2AddressResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Code            ReturnCode             `protobuf:"varint,1,opt,name=code,proto3,enum=wallet.ReturnCode" json:"code,omitempty"`
+	Message         string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Address         string                 `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	InternalKey     string                 `protobuf:"bytes,4,opt,name=internal_key,json=internalKey,proto3" json:"internal_key,omitempty"`
+	OutputKey       string                 `protobuf:"bytes,5,opt,name=output_key,json=outputKey,proto3" json:"output_key,omitempty"`
+	PublicKeys      []string               `protobuf:"bytes,6,rep,name=public_keys,json=publicKeys,proto3" json:"public_keys,omitempty"`
+	LocalPublicKeys []string               `protobuf:"bytes,7,rep,name=local_public_keys,json=localPublicKeys,proto3" json:"local_public_keys,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CreateMusig2AddressResponse) Reset() {
+	*x = CreateMusig2AddressResponse{}
+	mi := &file_protobuf_wallet_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateMusig2AddressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateMusig2AddressResponse) ProtoMessage() {}
+
+func (x *CreateMusig2AddressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateMusig2AddressResponse.ProtoReflect.Descriptor instead.
+func (*CreateMusig2AddressResponse) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *CreateMusig2AddressResponse) GetCode() ReturnCode {
+	if x != nil {
+		return x.Code
+	}
+	return ReturnCode_ERROR
+}
+
+func (x *CreateMusig2AddressResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CreateMusig2AddressResponse) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *CreateMusig2AddressResponse) GetInternalKey() string {
+	if x != nil {
+		return x.InternalKey
+	}
+	return ""
+}
+
+func (x *CreateMusig2AddressResponse) GetOutputKey() string {
+	if x != nil {
+		return x.OutputKey
+	}
+	return ""
+}
+
+func (x *CreateMusig2AddressResponse) GetPublicKeys() []string {
+	if x != nil {
+		return x.PublicKeys
+	}
+	return nil
+}
+
+func (x *CreateMusig2AddressResponse) GetLocalPublicKeys() []string {
+	if x != nil {
+		return x.LocalPublicKeys
+	}
+	return nil
+}
+
+type Musig2NonceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConsumerToken string                 `protobuf:"bytes,1,opt,name=consumer_token,json=consumerToken,proto3" json:"consumer_token,omitempty"`
+	ChainName     string                 `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	Network       string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	PublicKey     string                 `protobuf:"bytes,4,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	MessageHash   string                 `protobuf:"bytes,5,opt,name=message_hash,json=messageHash,proto3" json:"message_hash,omitempty"`
+	PublicKeys    []string               `protobuf:"bytes,6,rep,name=public_keys,json=publicKeys,proto3" json:"public_keys,omitempty"`
+	MerkleRoot    string                 `protobuf:"bytes,7,opt,name=merkle_root,json=merkleRoot,proto3" json:"merkle_root,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Musig2NonceRequest) Reset() {
+	*x = Musig2NonceRequest{}
+	mi := &file_protobuf_wallet_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Musig2NonceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Musig2NonceRequest) ProtoMessage() {}
+
+func (x *Musig2NonceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Musig2NonceRequest.ProtoReflect.Descriptor instead.
+func (*Musig2NonceRequest) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *Musig2NonceRequest) GetConsumerToken() string {
+	if x != nil {
+		return x.ConsumerToken
+	}
+	return ""
+}
+
+func (x *Musig2NonceRequest) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *Musig2NonceRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *Musig2NonceRequest) GetPublicKey() string {
+	if x != nil {
+		return x.PublicKey
+	}
+	return ""
+}
+
+func (x *Musig2NonceRequest) GetMessageHash() string {
+	if x != nil {
+		return x.MessageHash
+	}
+	return ""
+}
+
+func (x *Musig2NonceRequest) GetPublicKeys() []string {
+	if x != nil {
+		return x.PublicKeys
+	}
+	return nil
+}
+
+func (x *Musig2NonceRequest) GetMerkleRoot() string {
+	if x != nil {
+		return x.MerkleRoot
+	}
+	return ""
+}
+
+type Musig2NonceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          ReturnCode             `protobuf:"varint,1,opt,name=code,proto3,enum=wallet.ReturnCode" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	SessionId     string                 `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	PubNonce      string                 `protobuf:"bytes,4,opt,name=pub_nonce,json=pubNonce,proto3" json:"pub_nonce,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Musig2NonceResponse) Reset() {
+	*x = Musig2NonceResponse{}
+	mi := &file_protobuf_wallet_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Musig2NonceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Musig2NonceResponse) ProtoMessage() {}
+
+func (x *Musig2NonceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Musig2NonceResponse.ProtoReflect.Descriptor instead.
+func (*Musig2NonceResponse) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *Musig2NonceResponse) GetCode() ReturnCode {
+	if x != nil {
+		return x.Code
+	}
+	return ReturnCode_ERROR
+}
+
+func (x *Musig2NonceResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Musig2NonceResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *Musig2NonceResponse) GetPubNonce() string {
+	if x != nil {
+		return x.PubNonce
+	}
+	return ""
+}
+
+// wallet_key_hash 和 risk_key_hash 是对 chain_name, network, session_id, public_key, message_hash
+// 按 key 排序的紧凑 JSON 对象计算的 HMAC-SHA256
+type Musig2PartialSignRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	ConsumerToken     string                 `protobuf:"bytes,1,opt,name=consumer_token,json=consumerToken,proto3" json:"consumer_token,omitempty"`
+	ChainName         string                 `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	Network           string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	SessionId         string                 `protobuf:"bytes,4,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	MessageHash       string                 `protobuf:"bytes,5,opt,name=message_hash,json=messageHash,proto3" json:"message_hash,omitempty"`
+	WalletKeyHash     string                 `protobuf:"bytes,6,opt,name=wallet_key_hash,json=walletKeyHash,proto3" json:"wallet_key_hash,omitempty"`
+	RiskKeyHash       string                 `protobuf:"bytes,7,opt,name=risk_key_hash,json=riskKeyHash,proto3" json:"risk_key_hash,omitempty"`
+	WalletKeyVersion  string                 `protobuf:"bytes,8,opt,name=wallet_key_version,json=walletKeyVersion,proto3" json:"wallet_key_version,omitempty"`
+	RiskKeyVersion    string                 `protobuf:"bytes,9,opt,name=risk_key_version,json=riskKeyVersion,proto3" json:"risk_key_version,omitempty"`
+	PubNonces         map[string]string      `protobuf:"bytes,10,rep,name=pub_nonces,json=pubNonces,proto3" json:"pub_nonces,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	PartialSignatures map[string]string      `protobuf:"bytes,11,rep,name=partial_signatures,json=partialSignatures,proto3" json:"partial_signatures,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// 本地签名公钥, 必须与 session 的公钥一致
+	PublicKey     string `protobuf:"bytes,12,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Musig2PartialSignRequest) Reset() {
+	*x = Musig2PartialSignRequest{}
+	mi := &file_protobuf_wallet_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Musig2PartialSignRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Musig2PartialSignRequest) ProtoMessage() {}
+
+func (x *Musig2PartialSignRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Musig2PartialSignRequest.ProtoReflect.Descriptor instead.
+func (*Musig2PartialSignRequest) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *Musig2PartialSignRequest) GetConsumerToken() string {
+	if x != nil {
+		return x.ConsumerToken
+	}
+	return ""
+}
+
+func (x *Musig2PartialSignRequest) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *Musig2PartialSignRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *Musig2PartialSignRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *Musig2PartialSignRequest) GetMessageHash() string {
+	if x != nil {
+		return x.MessageHash
+	}
+	return ""
+}
+
+func (x *Musig2PartialSignRequest) GetWalletKeyHash() string {
+	if x != nil {
+		return x.WalletKeyHash
+	}
+	return ""
+}
+
+func (x *Musig2PartialSignRequest) GetRiskKeyHash() string {
+	if x != nil {
+		return x.RiskKeyHash
+	}
+	return ""
+}
+
+func (x *Musig2PartialSignRequest) GetWalletKeyVersion() string {
+	if x != nil {
+		return x.WalletKeyVersion
+	}
+	return ""
+}
+
+func (x *Musig2PartialSignRequest) GetRiskKeyVersion() string {
+	if x != nil {
+		return x.RiskKeyVersion
+	}
+	return ""
+}
+
+func (x *Musig2PartialSignRequest) GetPubNonces() map[string]string {
+	if x != nil {
+		return x.PubNonces
+	}
+	return nil
+}
+
+func (x *Musig2PartialSignRequest) GetPartialSignatures() map[string]string {
+	if x != nil {
+		return x.PartialSignatures
+	}
+	return nil
+}
+
+func (x *Musig2PartialSignRequest) GetPublicKey() string {
+	if x != nil {
+		return x.PublicKey
+	}
+	return ""
+}
+
+type Musig2PartialSignResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Code             ReturnCode             `protobuf:"varint,1,opt,name=code,proto3,enum=wallet.ReturnCode" json:"code,omitempty"`
+	Message          string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	PartialSignature string                 `protobuf:"bytes,3,opt,name=partial_signature,json=partialSignature,proto3" json:"partial_signature,omitempty"`
+	FinalNonce       string                 `protobuf:"bytes,4,opt,name=final_nonce,json=finalNonce,proto3" json:"final_nonce,omitempty"`
+	Signature        string                 `protobuf:"bytes,5,opt,name=signature,proto3" json:"signature,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Musig2PartialSignResponse) Reset() {
+	*x = Musig2PartialSignResponse{}
+	mi := &file_protobuf_wallet_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Musig2PartialSignResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Musig2PartialSignResponse) ProtoMessage() {}
+
+func (x *Musig2PartialSignResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Musig2PartialSignResponse.ProtoReflect.Descriptor instead.
+func (*Musig2PartialSignResponse) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *Musig2PartialSignResponse) GetCode() ReturnCode {
+	if x != nil {
+		return x.Code
+	}
+	return ReturnCode_ERROR
+}
+
+func (x *Musig2PartialSignResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Musig2PartialSignResponse) GetPartialSignature() string {
+	if x != nil {
+		return x.PartialSignature
+	}
+	return ""
+}
+
+func (x *Musig2PartialSignResponse) GetFinalNonce() string {
+	if x != nil {
+		return x.FinalNonce
+	}
+	return ""
+}
+
+func (x *Musig2PartialSignResponse) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+type TapLeaf struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Script        string                 `protobuf:"bytes,1,opt,name=script,proto3" json:"script,omitempty"`
+	LeafVersion   uint32                 `protobuf:"varint,2,opt,name=leaf_version,json=leafVersion,proto3" json:"leaf_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TapLeaf) Reset() {
+	*x = TapLeaf{}
+	mi := &file_protobuf_wallet_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TapLeaf) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TapLeaf) ProtoMessage() {}
+
+func (x *TapLeaf) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TapLeaf.ProtoReflect.Descriptor instead.
+func (*TapLeaf) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *TapLeaf) GetScript() string {
+	if x != nil {
+		return x.Script
+	}
+	return ""
+}
+
+func (x *TapLeaf) GetLeafVersion() uint32 {
+	if x != nil {
+		return x.LeafVersion
+	}
+	return 0
+}
+
+type TapLeafProof struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Script        string                 `protobuf:"bytes,1,opt,name=script,proto3" json:"script,omitempty"`
+	LeafVersion   uint32                 `protobuf:"varint,2,opt,name=leaf_version,json=leafVersion,proto3" json:"leaf_version,omitempty"`
+	LeafHash      string                 `protobuf:"bytes,3,opt,name=leaf_hash,json=leafHash,proto3" json:"leaf_hash,omitempty"`
+	ControlBlock  string                 `protobuf:"bytes,4,opt,name=control_block,json=controlBlock,proto3" json:"control_block,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TapLeafProof) Reset() {
+	*x = TapLeafProof{}
+	mi := &file_protobuf_wallet_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TapLeafProof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TapLeafProof) ProtoMessage() {}
+
+func (x *TapLeafProof) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TapLeafProof.ProtoReflect.Descriptor instead.
+func (*TapLeafProof) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *TapLeafProof) GetScript() string {
+	if x != nil {
+		return x.Script
+	}
+	return ""
+}
+
+func (x *TapLeafProof) GetLeafVersion() uint32 {
+	if x != nil {
+		return x.LeafVersion
+	}
+	return 0
+}
+
+func (x *TapLeafProof) GetLeafHash() string {
+	if x != nil {
+		return x.LeafHash
+	}
+	return ""
+}
+
+func (x *TapLeafProof) GetControlBlock() string {
+	if x != nil {
+		return x.ControlBlock
+	}
+	return ""
+}
+
+type CreateTaprootAddressRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConsumerToken string                 `protobuf:"bytes,1,opt,name=consumer_token,json=consumerToken,proto3" json:"consumer_token,omitempty"`
+	ChainName     string                 `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	Network       string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	InternalKey   string                 `protobuf:"bytes,4,opt,name=internal_key,json=internalKey,proto3" json:"internal_key,omitempty"`
+	Leaves        []*TapLeaf             `protobuf:"bytes,5,rep,name=leaves,proto3" json:"leaves,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTaprootAddressRequest) Reset() {
+	*x = CreateTaprootAddressRequest{}
+	mi := &file_protobuf_wallet_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTaprootAddressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTaprootAddressRequest) ProtoMessage() {}
+
+func (x *CreateTaprootAddressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTaprootAddressRequest.ProtoReflect.Descriptor instead.
+func (*CreateTaprootAddressRequest) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *CreateTaprootAddressRequest) GetConsumerToken() string {
+	if x != nil {
+		return x.ConsumerToken
+	}
+	return ""
+}
+
+func (x *CreateTaprootAddressRequest) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *CreateTaprootAddressRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *CreateTaprootAddressRequest) GetInternalKey() string {
+	if x != nil {
+		return x.InternalKey
+	}
+	return ""
+}
+
+func (x *CreateTaprootAddressRequest) GetLeaves() []*TapLeaf {
+	if x != nil {
+		return x.Leaves
+	}
+	return nil
+}
+
+type CreateTaprootAddressResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          ReturnCode             `protobuf:"varint,1,opt,name=code,proto3,enum=wallet.ReturnCode" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Address       string                 `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	OutputKey     string                 `protobuf:"bytes,4,opt,name=output_key,json=outputKey,proto3" json:"output_key,omitempty"`
+	MerkleRoot    string                 `protobuf:"bytes,5,opt,name=merkle_root,json=merkleRoot,proto3" json:"merkle_root,omitempty"`
+	Leaves        []*TapLeafProof        `protobuf:"bytes,6,rep,name=leaves,proto3" json:"leaves,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTaprootAddressResponse) Reset() {
+	*x = CreateTaprootAddressResponse{}
+	mi := &file_protobuf_wallet_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTaprootAddressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTaprootAddressResponse) ProtoMessage() {}
+
+func (x *CreateTaprootAddressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_wallet_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTaprootAddressResponse.ProtoReflect.Descriptor instead.
+func (*CreateTaprootAddressResponse) Descriptor() ([]byte, []int) {
+	return file_protobuf_wallet_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *CreateTaprootAddressResponse) GetCode() ReturnCode {
+	if x != nil {
+		return x.Code
+	}
+	return ReturnCode_ERROR
+}
+
+func (x *CreateTaprootAddressResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CreateTaprootAddressResponse) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *CreateTaprootAddressResponse) GetOutputKey() string {
+	if x != nil {
+		return x.OutputKey
+	}
+	return ""
+}
+
+func (x *CreateTaprootAddressResponse) GetMerkleRoot() string {
+	if x != nil {
+		return x.MerkleRoot
+	}
+	return ""
+}
+
+func (x *CreateTaprootAddressResponse) GetLeaves() []*TapLeafProof {
+	if x != nil {
+		return x.Leaves
+	}
+	return nil
+}
+
+var File_protobuf_wallet_proto protoreflect.FileDescriptor
+
+const file_protobuf_wallet_proto_rawDesc = "" +
+	"\n" +
+	"\x15protobuf/wallet.proto\x12\x06wallet\"\x9a\x01\n" +
+	"\x19GetChainSignMethodRequest\x12%\n" +
+	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x1d\n" +
+	"\n" +
+	"asset_type\x18\x04 \x01(\tR\tassetType\"w\n" +
+	"\x1aGetChainSignMethodResponse\x12&\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x10\n" +
+	"\x03msg\x18\x02 \x01(\tR\x03msg\x12\x1f\n" +
+	"\vsign_method\x18\x03 \x01(\tR\n" +
+	"signMethod\"\x96\x01\n" +
+	"\x15getChainSchemaRequest\x12%\n" +
+	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x1d\n" +
+	"\n" +
+	"asset_type\x18\x04 \x01(\tR\tassetType\"r\n" +
+	"\x16getChainSchemaResponse\x12&\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x16\n" +
+	"\x06schema\x18\x03 \x01(\tR\x06schema\"`\n" +
+	"\x0fExportPublicKey\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x01 \x01(\tR\tpublicKey\x12.\n" +
+	"\x13compress_public_key\x18\x02 \x01(\tR\x11compressPublicKey\"\xa1\x01\n" +
+	"&CreateKeyPairAndExportPublicKeyRequest\x12%\n" +
+	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x17\n" +
+	"\akey_num\x18\x04 \x01(\x04R\x06keyNum\"\xac\x01\n" +
+	"'CreateKeyPairAndExportPublicKeyResponse\x12&\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12?\n" +
+	"\x0fpublic_key_list\x18\x03 \x03(\v2\x17.wallet.ExportPublicKeyR\rpublicKeyList\"\x85\x01\n" +
+	"\x1aExportPublicKeyWithAddress\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x01 \x01(\tR\tpublicKey\x12.\n" +
+	"\x13compress_public_key\x18\x02 \x01(\tR\x11compressPublicKey\x12\x18\n" +
+	"\aaddress\x18\x03 \x01(\tR\aaddress\"\xc4\x01\n" +
+	"\"CreateKeyPairsWithAddressesRequest\x12%\n" +
+	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\x12%\n" +
+	"\x0eaddress_format\x18\x04 \x01(\tR\raddressFormat\x12\x17\n" +
+	"\akey_num\x18\x05 \x01(\x04R\x06keyNum\"\xbd\x01\n" +
+	"#CreateKeyPairsWithAddressesResponse\x12&\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12T\n" +
+	"\x14public_key_addresses\x18\x03 \x03(\v2\".wallet.ExportPublicKeyWithAddressR\x12publicKeyAddresses\"\xe9\x02\n" +
+	"\x1eBuildAndSignTransactionRequest\x12%\n" +
+	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x04 \x01(\tR\tpublicKey\x12&\n" +
+	"\x0fwallet_key_hash\x18\x05 \x01(\tR\rwalletKeyHash\x12\"\n" +
+	"\rrisk_key_hash\x18\x06 \x01(\tR\vriskKeyHash\x12$\n" +
+	"\x0etx_base64_body\x18\a \x01(\tR\ftxBase64Body\x12,\n" +
+	"\x12wallet_key_version\x18\b \x01(\tR\x10walletKeyVersion\x12(\n" +
+	"\x10risk_key_version\x18\t \x01(\tR\x0eriskKeyVersion\"\x93\x02\n" +
+	"\x1fBuildAndSignTransactionResponse\x12&\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12&\n" +
+	"\x0ftx_message_hash\x18\x03 \x01(\tR\rtxMessageHash\x12\x17\n" +
+	"\atx_hash\x18\x04 \x01(\tR\x06txHash\x12\x1b\n" +
+	"\tsigned_tx\x18\x05 \x01(\tR\bsignedTx\x12!\n" +
+	"\fdecoded_call\x18\x06 \x01(\tR\vdecodedCall\x12-\n" +
+	"\x12partial_signatures\x18\a \x01(\tR\x11partialSignatures\"\xfd\x01\n" +
+	"\x12TransactionMessage\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x01 \x01(\tR\tpublicKey\x12&\n" +
+	"\x0fwallet_key_hash\x18\x02 \x01(\tR\rwalletKeyHash\x12\"\n" +
+	"\rrisk_key_hash\x18\x03 \x01(\tR\vriskKeyHash\x12$\n" +
+	"\x0etx_base64_body\x18\x04 \x01(\tR\ftxBase64Body\x12,\n" +
+	"\x12wallet_key_version\x18\x05 \x01(\tR\x10walletKeyVersion\x12(\n" +
+	"\x10risk_key_version\x18\x06 \x01(\tR\x0eriskKeyVersion\"\xa6\x02\n" +
+	"\x13TransactionWithSign\x12&\n" +
+	"\x0ftx_message_hash\x18\x01 \x01(\tR\rtxMessageHash\x12\x17\n" +
+	"\atx_hash\x18\x02 \x01(\tR\x06txHash\x12\x1b\n" +
+	"\tsigned_tx\x18\x03 \x01(\tR\bsignedTx\x12&\n" +
+	"\x04code\x18\x04 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x06 \x01(\tR\tpublicKey\x12!\n" +
+	"\fdecoded_call\x18\a \x01(\tR\vdecodedCall\x12-\n" +
+	"\x12partial_signatures\x18\b \x01(\tR\x11partialSignatures\"\xb8\x01\n" +
+	"#BuildAndSignBatchTransactionRequest\x12%\n" +
+	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\x121\n" +
+	"\x06tx_msg\x18\x04 \x03(\v2\x1a.wallet.TransactionMessageR\x05txMsg\"\xa7\x01\n" +
+	"$BuildAndSignBatchTransactionResponse\x12&\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12=\n" +
+	"\ftx_with_sign\x18\x03 \x03(\v2\x1b.wallet.TransactionWithSignR\n" +
+	"txWithSign\"\xe5\x02\n" +
+	"\x1dSignTransactionMessageRequest\x12%\n" +
+	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x04 \x01(\tR\tpublicKey\x12!\n" +
+	"\fmessage_hash\x18\x05 \x01(\tR\vmessageHash\x12&\n" +
+	"\x0fwallet_key_hash\x18\x06 \x01(\tR\rwalletKeyHash\x12\"\n" +
+	"\rrisk_key_hash\x18\a \x01(\tR\vriskKeyHash\x12,\n" +
+	"\x12wallet_key_version\x18\b \x01(\tR\x10walletKeyVersion\x12(\n" +
+	"\x10risk_key_version\x18\t \x01(\tR\x0eriskKeyVersion\"\x80\x01\n" +
+	"\x1eSignTransactionMessageResponse\x12&\n" +
+	"\x04Code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04Code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1c\n" +
+	"\tsignature\x18\x03 \x01(\tR\tsignature\"\xd9\x02\n" +
+	"\x1aSignPersonalMessageRequest\x12%\n" +
+	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x04 \x01(\tR\tpublicKey\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\x12&\n" +
+	"\x0fwallet_key_hash\x18\x06 \x01(\tR\rwalletKeyHash\x12\"\n" +
+	"\rrisk_key_hash\x18\a \x01(\tR\vriskKeyHash\x12,\n" +
+	"\x12wallet_key_version\x18\b \x01(\tR\x10walletKeyVersion\x12(\n" +
+	"\x10risk_key_version\x18\t \x01(\tR\x0eriskKeyVersion\"\xa0\x01\n" +
+	"\x1bSignPersonalMessageResponse\x12&\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1c\n" +
+	"\tsignature\x18\x03 \x01(\tR\tsignature\x12!\n" +
+	"\fmessage_hash\x18\x04 \x01(\tR\vmessageHash\"\xd8\x02\n" +
+	"\x14SignTypedDataRequest\x12%\n" +
+	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x04 \x01(\tR\tpublicKey\x12\x1d\n" +
+	"\n" +
+	"typed_data\x18\x05 \x01(\tR\ttypedData\x12&\n" +
+	"\x0fwallet_key_hash\x18\x06 \x01(\tR\rwalletKeyHash\x12\"\n" +
+	"\rrisk_key_hash\x18\a \x01(\tR\vriskKeyHash\x12,\n" +
+	"\x12wallet_key_version\x18\b \x01(\tR\x10walletKeyVersion\x12(\n" +
+	"\x10risk_key_version\x18\t \x01(\tR\x0eriskKeyVersion\"\xd5\x01\n" +
+	"\x15SignTypedDataResponse\x12&\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1c\n" +
+	"\tsignature\x18\x03 \x01(\tR\tsignature\x12!\n" +
+	"\fmessage_hash\x18\x04 \x01(\tR\vmessageHash\x12\x16\n" +
+	"\x06domain\x18\x05 \x01(\tR\x06domain\x12!\n" +
+	"\fprimary_type\x18\x06 \x01(\tR\vprimaryType\"\xec\x02\n" +
+	"\x19BuildAndSignPermitRequest\x12%\n" +
+	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x04 \x01(\tR\tpublicKey\x12,\n" +
+	"\x12permit_base64_body\x18\x05 \x01(\tR\x10permitBase64Body\x12&\n" +
+	"\x0fwallet_key_hash\x18\x06 \x01(\tR\rwalletKeyHash\x12\"\n" +
+	"\rrisk_key_hash\x18\a \x01(\tR\vriskKeyHash\x12,\n" +
+	"\x12wallet_key_version\x18\b \x01(\tR\x10walletKeyVersion\x12(\n" +
+	"\x10risk_key_version\x18\t \x01(\tR\x0eriskKeyVersion\"\xe8\x01\n" +
+	"\x1aBuildAndSignPermitResponse\x12&\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1c\n" +
+	"\tsignature\x18\x03 \x01(\tR\tsignature\x12\f\n" +
+	"\x01v\x18\x04 \x01(\rR\x01v\x12\f\n" +
+	"\x01r\x18\x05 \x01(\tR\x01r\x12\f\n" +
+	"\x01s\x18\x06 \x01(\tR\x01s\x12!\n" +
+	"\fmessage_hash\x18\a \x01(\tR\vmessageHash\x12\x1d\n" +
+	"\n" +
+	"typed_data\x18\b \x01(\tR\ttypedData\"\xf4\x02\n" +
+	" BuildAndSignUserOperationRequest\x12%\n" +
+	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x04 \x01(\tR\tpublicKey\x12-\n" +
+	"\x13user_op_base64_body\x18\x05 \x01(\tR\x10userOpBase64Body\x12&\n" +
+	"\x0fwallet_key_hash\x18\x06 \x01(\tR\rwalletKeyHash\x12\"\n" +
+	"\rrisk_key_hash\x18\a \x01(\tR\vriskKeyHash\x12,\n" +
+	"\x12wallet_key_version\x18\b \x01(\tR\x10walletKeyVersion\x12(\n" +
+	"\x10risk_key_version\x18\t \x01(\tR\x0eriskKeyVersion\"\xcc\x01\n" +
+	"!BuildAndSignUserOperationResponse\x12&\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12 \n" +
+	"\fuser_op_hash\x18\x03 \x01(\tR\n" +
+	"userOpHash\x12\x1c\n" +
+	"\tsignature\x18\x04 \x01(\tR\tsignature\x12%\n" +
+	"\x0euser_operation\x18\x05 \x01(\tR\ruserOperation\"\xf6\x02\n" +
+	"\"BuildAndSignSafeTransactionRequest\x12%\n" +
+	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x04 \x01(\tR\tpublicKey\x12-\n" +
+	"\x13safe_tx_base64_body\x18\x05 \x01(\tR\x10safeTxBase64Body\x12&\n" +
+	"\x0fwallet_key_hash\x18\x06 \x01(\tR\rwalletKeyHash\x12\"\n" +
+	"\rrisk_key_hash\x18\a \x01(\tR\vriskKeyHash\x12,\n" +
+	"\x12wallet_key_version\x18\b \x01(\tR\x10walletKeyVersion\x12(\n" +
+	"\x10risk_key_version\x18\t \x01(\tR\x0eriskKeyVersion\"\xe0\x01\n" +
+	"#BuildAndSignSafeTransactionResponse\x12&\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12 \n" +
+	"\fsafe_tx_hash\x18\x03 \x01(\tR\n" +
+	"safeTxHash\x12\x1c\n" +
+	"\tsignature\x18\x04 \x01(\tR\tsignature\x12\x14\n" +
+	"\x05owner\x18\x05 \x01(\tR\x05owner\x12!\n" +
+	"\fdecoded_call\x18\x06 \x01(\tR\vdecodedCall\"\x9d\x03\n" +
+	"\x0fSignPsbtRequest\x12%\n" +
+	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x04 \x01(\tR\tpublicKey\x12\x1f\n" +
+	"\vpsbt_base64\x18\x05 \x01(\tR\n" +
+	"psbtBase64\x12&\n" +
+	"\x0fwallet_key_hash\x18\x06 \x01(\tR\rwalletKeyHash\x12\"\n" +
+	"\rrisk_key_hash\x18\a \x01(\tR\vriskKeyHash\x12,\n" +
+	"\x12wallet_key_version\x18\b \x01(\tR\x10walletKeyVersion\x12(\n" +
+	"\x10risk_key_version\x18\t \x01(\tR\x0eriskKeyVersion\x12\x1a\n" +
+	"\bfinalize\x18\n" +
+	" \x01(\bR\bfinalize\x12*\n" +
+	"\x11allow_any_sighash\x18\v \x01(\bR\x0fallowAnySighash\"\xec\x01\n" +
+	"\x10SignPsbtResponse\x12&\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1f\n" +
+	"\vpsbt_base64\x18\x03 \x01(\tR\n" +
+	"psbtBase64\x12#\n" +
+	"\rsigned_inputs\x18\x04 \x03(\rR\fsignedInputs\x12\x1a\n" +
+	"\bcomplete\x18\x05 \x01(\bR\bcomplete\x12\x1b\n" +
+	"\tsigned_tx\x18\x06 \x01(\tR\bsignedTx\x12\x17\n" +
+	"\atx_hash\x18\a \x01(\tR\x06txHash\"\xe4\x01\n" +
+	"\x1cCreateMultisigAddressRequest\x12%\n" +
+	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\x12%\n" +
+	"\x0eaddress_format\x18\x04 \x01(\tR\raddressFormat\x12\x1c\n" +
+	"\tthreshold\x18\x05 \x01(\rR\tthreshold\x12\x1f\n" +
+	"\vpublic_keys\x18\x06 \x03(\tR\n" +
+	"publicKeys\"\x94\x02\n" +
+	"\x1dCreateMultisigAddressResponse\x12&\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x18\n" +
+	"\aaddress\x18\x03 \x01(\tR\aaddress\x12%\n" +
+	"\x0ewitness_script\x18\x04 \x01(\tR\rwitnessScript\x12#\n" +
+	"\rredeem_script\x18\x05 \x01(\tR\fredeemScript\x12\x1f\n" +
+	"\vpublic_keys\x18\x06 \x03(\tR\n" +
+	"publicKeys\x12*\n" +
+	"\x11local_public_keys\x18\a \x03(\tR\x0flocalPublicKeys\"\xbe\x01\n" +
+	"\x1aCreateMusig2AddressRequest\x12%\n" +
+	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x1f\n" +
+	"\vpublic_keys\x18\x04 \x03(\tR\n" +
+	"publicKeys\x12\x1f\n" +
+	"\vmerkle_root\x18\x05 \x01(\tR\n" +
+	"merkleRoot\"\x88\x02\n" +
+	"\x1bCreateMusig2AddressResponse\x12&\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x18\n" +
+	"\aaddress\x18\x03 \x01(\tR\aaddress\x12!\n" +
+	"\finternal_key\x18\x04 \x01(\tR\vinternalKey\x12\x1d\n" +
+	"\n" +
+	"output_key\x18\x05 \x01(\tR\toutputKey\x12\x1f\n" +
+	"\vpublic_keys\x18\x06 \x03(\tR\n" +
+	"publicKeys\x12*\n" +
+	"\x11local_public_keys\x18\a \x03(\tR\x0flocalPublicKeys\"\xf8\x01\n" +
+	"\x12Musig2NonceRequest\x12%\n" +
+	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x04 \x01(\tR\tpublicKey\x12!\n" +
+	"\fmessage_hash\x18\x05 \x01(\tR\vmessageHash\x12\x1f\n" +
+	"\vpublic_keys\x18\x06 \x03(\tR\n" +
+	"publicKeys\x12\x1f\n" +
+	"\vmerkle_root\x18\a \x01(\tR\n" +
+	"merkleRoot\"\x93\x01\n" +
+	"\x13Musig2NonceResponse\x12&\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x03 \x01(\tR\tsessionId\x12\x1b\n" +
+	"\tpub_nonce\x18\x04 \x01(\tR\bpubNonce\"\xbb\x05\n" +
+	"\x18Musig2PartialSignRequest\x12%\n" +
+	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x04 \x01(\tR\tsessionId\x12!\n" +
+	"\fmessage_hash\x18\x05 \x01(\tR\vmessageHash\x12&\n" +
+	"\x0fwallet_key_hash\x18\x06 \x01(\tR\rwalletKeyHash\x12\"\n" +
+	"\rrisk_key_hash\x18\a \x01(\tR\vriskKeyHash\x12,\n" +
+	"\x12wallet_key_version\x18\b \x01(\tR\x10walletKeyVersion\x12(\n" +
+	"\x10risk_key_version\x18\t \x01(\tR\x0eriskKeyVersion\x12N\n" +
+	"\n" +
+	"pub_nonces\x18\n" +
+	" \x03(\v2/.wallet.Musig2PartialSignRequest.PubNoncesEntryR\tpubNonces\x12f\n" +
+	"\x12partial_signatures\x18\v \x03(\v27.wallet.Musig2PartialSignRequest.PartialSignaturesEntryR\x11partialSignatures\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\f \x01(\tR\tpublicKey\x1a<\n" +
+	"\x0ePubNoncesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aD\n" +
+	"\x16PartialSignaturesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc9\x01\n" +
+	"\x19Musig2PartialSignResponse\x12&\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12+\n" +
+	"\x11partial_signature\x18\x03 \x01(\tR\x10partialSignature\x12\x1f\n" +
+	"\vfinal_nonce\x18\x04 \x01(\tR\n" +
+	"finalNonce\x12\x1c\n" +
+	"\tsignature\x18\x05 \x01(\tR\tsignature\"D\n" +
+	"\aTapLeaf\x12\x16\n" +
+	"\x06script\x18\x01 \x01(\tR\x06script\x12!\n" +
+	"\fleaf_version\x18\x02 \x01(\rR\vleafVersion\"\x8b\x01\n" +
+	"\fTapLeafProof\x12\x16\n" +
+	"\x06script\x18\x01 \x01(\tR\x06script\x12!\n" +
+	"\fleaf_version\x18\x02 \x01(\rR\vleafVersion\x12\x1b\n" +
+	"\tleaf_hash\x18\x03 \x01(\tR\bleafHash\x12#\n" +
+	"\rcontrol_block\x18\x04 \x01(\tR\fcontrolBlock\"\xc9\x01\n" +
+	"\x1bCreateTaprootAddressRequest\x12%\n" +
+	"\x0econsumer_token\x18\x01 \x01(\tR\rconsumerToken\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\x12!\n" +
+	"\finternal_key\x18\x04 \x01(\tR\vinternalKey\x12'\n" +
+	"\x06leaves\x18\x05 \x03(\v2\x0f.wallet.TapLeafR\x06leaves\"\xe8\x01\n" +
+	"\x1cCreateTaprootAddressResponse\x12&\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x12.wallet.ReturnCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x18\n" +
+	"\aaddress\x18\x03 \x01(\tR\aaddress\x12\x1d\n" +
+	"\n" +
+	"output_key\x18\x04 \x01(\tR\toutputKey\x12\x1f\n" +
+	"\vmerkle_root\x18\x05 \x01(\tR\n" +
+	"merkleRoot\x12,\n" +
+	"\x06leaves\x18\x06 \x03(\v2\x14.wallet.TapLeafProofR\x06leaves*$\n" +
 	"\n" +
 	"ReturnCode\x12\t\n" +
 	"\x05ERROR\x10\x00\x12\v\n" +
-	"\aSUCCESS\x10\x012\x90\x06\n" +
+	"\aSUCCESS\x10\x012\x8f\x0e\n" +
 	"\rWalletService\x12[\n" +
 	"\x12getChainSignMethod\x12!.wallet.GetChainSignMethodRequest\x1a\".wallet.GetChainSignMethodResponse\x12O\n" +
 	"\x0egetChainSchema\x12\x1d.wallet.getChainSchemaRequest\x1a\x1e.wallet.getChainSchemaResponse\x12\x84\x01\n" +
@@ -1377,7 +3926,18 @@ const file_protobuf_wallet_proto_rawDesc = "" +
 	"\x1bcreateKeyPairsWithAddresses\x12*.wallet.CreateKeyPairsWithAddressesRequest\x1a+.wallet.CreateKeyPairsWithAddressesResponse\"\x00\x12i\n" +
 	"\x16signTransactionMessage\x12%.wallet.SignTransactionMessageRequest\x1a&.wallet.SignTransactionMessageResponse\"\x00\x12j\n" +
 	"\x17buildAndSignTransaction\x12&.wallet.BuildAndSignTransactionRequest\x1a'.wallet.BuildAndSignTransactionResponse\x12y\n" +
-	"\x1cbuildAndSignBatchTransaction\x12+.wallet.BuildAndSignBatchTransactionRequest\x1a,.wallet.BuildAndSignBatchTransactionResponseB\x13Z\x11./protobuf/walletb\x06proto3"
+	"\x1cbuildAndSignBatchTransaction\x12+.wallet.BuildAndSignBatchTransactionRequest\x1a,.wallet.BuildAndSignBatchTransactionResponse\x12^\n" +
+	"\x13signPersonalMessage\x12\".wallet.SignPersonalMessageRequest\x1a#.wallet.SignPersonalMessageResponse\x12L\n" +
+	"\rsignTypedData\x12\x1c.wallet.SignTypedDataRequest\x1a\x1d.wallet.SignTypedDataResponse\x12[\n" +
+	"\x12buildAndSignPermit\x12!.wallet.BuildAndSignPermitRequest\x1a\".wallet.BuildAndSignPermitResponse\x12p\n" +
+	"\x19buildAndSignUserOperation\x12(.wallet.BuildAndSignUserOperationRequest\x1a).wallet.BuildAndSignUserOperationResponse\x12v\n" +
+	"\x1bbuildAndSignSafeTransaction\x12*.wallet.BuildAndSignSafeTransactionRequest\x1a+.wallet.BuildAndSignSafeTransactionResponse\x12=\n" +
+	"\bsignPsbt\x12\x17.wallet.SignPsbtRequest\x1a\x18.wallet.SignPsbtResponse\x12d\n" +
+	"\x15createMultisigAddress\x12$.wallet.CreateMultisigAddressRequest\x1a%.wallet.CreateMultisigAddressResponse\x12^\n" +
+	"\x13createMusig2Address\x12\".wallet.CreateMusig2AddressRequest\x1a#.wallet.CreateMusig2AddressResponse\x12F\n" +
+	"\vmusig2Nonce\x12\x1a.wallet.Musig2NonceRequest\x1a\x1b.wallet.Musig2NonceResponse\x12X\n" +
+	"\x11musig2PartialSign\x12 .wallet.Musig2PartialSignRequest\x1a!.wallet.Musig2PartialSignResponse\x12a\n" +
+	"\x14createTaprootAddress\x12#.wallet.CreateTaprootAddressRequest\x1a$.wallet.CreateTaprootAddressResponseB\x13Z\x11./protobuf/walletb\x06proto3"
 
 var (
 	file_protobuf_wallet_proto_rawDescOnce sync.Once
@@ -1392,7 +3952,7 @@ func file_protobuf_wallet_proto_rawDescGZIP() []byte {
 }
 
 var file_protobuf_wallet_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_protobuf_wallet_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_protobuf_wallet_proto_msgTypes = make([]protoimpl.MessageInfo, 44)
 var file_protobuf_wallet_proto_goTypes = []any{
 	(ReturnCode)(0),                                 // 0: wallet.ReturnCode
 	(*GetChainSignMethodRequest)(nil),               // 1: wallet.GetChainSignMethodRequest
@@ -1413,6 +3973,32 @@ var file_protobuf_wallet_proto_goTypes = []any{
 	(*BuildAndSignBatchTransactionResponse)(nil),    // 16: wallet.BuildAndSignBatchTransactionResponse
 	(*SignTransactionMessageRequest)(nil),           // 17: wallet.SignTransactionMessageRequest
 	(*SignTransactionMessageResponse)(nil),          // 18: wallet.SignTransactionMessageResponse
+	(*SignPersonalMessageRequest)(nil),              // 19: wallet.SignPersonalMessageRequest
+	(*SignPersonalMessageResponse)(nil),             // 20: wallet.SignPersonalMessageResponse
+	(*SignTypedDataRequest)(nil),                    // 21: wallet.SignTypedDataRequest
+	(*SignTypedDataResponse)(nil),                   // 22: wallet.SignTypedDataResponse
+	(*BuildAndSignPermitRequest)(nil),               // 23: wallet.BuildAndSignPermitRequest
+	(*BuildAndSignPermitResponse)(nil),              // 24: wallet.BuildAndSignPermitResponse
+	(*BuildAndSignUserOperationRequest)(nil),        // 25: wallet.BuildAndSignUserOperationRequest
+	(*BuildAndSignUserOperationResponse)(nil),       // 26: wallet.BuildAndSignUserOperationResponse
+	(*BuildAndSignSafeTransactionRequest)(nil),      // 27: wallet.BuildAndSignSafeTransactionRequest
+	(*BuildAndSignSafeTransactionResponse)(nil),     // 28: wallet.BuildAndSignSafeTransactionResponse
+	(*SignPsbtRequest)(nil),                         // 29: wallet.SignPsbtRequest
+	(*SignPsbtResponse)(nil),                        // 30: wallet.SignPsbtResponse
+	(*CreateMultisigAddressRequest)(nil),            // 31: wallet.CreateMultisigAddressRequest
+	(*CreateMultisigAddressResponse)(nil),           // 32: wallet.CreateMultisigAddressResponse
+	(*CreateMusig2AddressRequest)(nil),              // 33: wallet.CreateMusig2AddressRequest
+	(*CreateMusig2AddressResponse)(nil),             // 34: wallet.CreateMusig2AddressResponse
+	(*Musig2NonceRequest)(nil),                      // 35: wallet.Musig2NonceRequest
+	(*Musig2NonceResponse)(nil),                     // 36: wallet.Musig2NonceResponse
+	(*Musig2PartialSignRequest)(nil),                // 37: wallet.Musig2PartialSignRequest
+	(*Musig2PartialSignResponse)(nil),               // 38: wallet.Musig2PartialSignResponse
+	(*TapLeaf)(nil),                                 // 39: wallet.TapLeaf
+	(*TapLeafProof)(nil),                            // 40: wallet.TapLeafProof
+	(*CreateTaprootAddressRequest)(nil),             // 41: wallet.CreateTaprootAddressRequest
+	(*CreateTaprootAddressResponse)(nil),            // 42: wallet.CreateTaprootAddressResponse
+	nil,                                             // 43: wallet.Musig2PartialSignRequest.PubNoncesEntry
+	nil,                                             // 44: wallet.Musig2PartialSignRequest.PartialSignaturesEntry
 }
 var file_protobuf_wallet_proto_depIdxs = []int32{
 	0,  // 0: wallet.GetChainSignMethodResponse.code:type_name -> wallet.ReturnCode
@@ -1422,29 +4008,67 @@ var file_protobuf_wallet_proto_depIdxs = []int32{
 	0,  // 4: wallet.CreateKeyPairsWithAddressesResponse.code:type_name -> wallet.ReturnCode
 	8,  // 5: wallet.CreateKeyPairsWithAddressesResponse.public_key_addresses:type_name -> wallet.ExportPublicKeyWithAddress
 	0,  // 6: wallet.BuildAndSignTransactionResponse.code:type_name -> wallet.ReturnCode
-	13, // 7: wallet.BuildAndSignBatchTransactionRequest.tx_msg:type_name -> wallet.TransactionMessage
-	0,  // 8: wallet.BuildAndSignBatchTransactionResponse.code:type_name -> wallet.ReturnCode
-	14, // 9: wallet.BuildAndSignBatchTransactionResponse.tx_with_sign:type_name -> wallet.TransactionWithSign
-	0,  // 10: wallet.SignTransactionMessageResponse.Code:type_name -> wallet.ReturnCode
-	1,  // 11: wallet.WalletService.getChainSignMethod:input_type -> wallet.GetChainSignMethodRequest
-	3,  // 12: wallet.WalletService.getChainSchema:input_type -> wallet.getChainSchemaRequest
-	6,  // 13: wallet.WalletService.createKeyPairsExportPublicKeyList:input_type -> wallet.CreateKeyPairAndExportPublicKeyRequest
-	9,  // 14: wallet.WalletService.createKeyPairsWithAddresses:input_type -> wallet.CreateKeyPairsWithAddressesRequest
-	17, // 15: wallet.WalletService.signTransactionMessage:input_type -> wallet.SignTransactionMessageRequest
-	11, // 16: wallet.WalletService.buildAndSignTransaction:input_type -> wallet.BuildAndSignTransactionRequest
-	15, // 17: wallet.WalletService.buildAndSignBatchTransaction:input_type -> wallet.BuildAndSignBatchTransactionRequest
-	2,  // 18: wallet.WalletService.getChainSignMethod:output_type -> wallet.GetChainSignMethodResponse
-	4,  // 19: wallet.WalletService.getChainSchema:output_type -> wallet.getChainSchemaResponse
-	7,  // 20: wallet.WalletService.createKeyPairsExportPublicKeyList:output_type -> wallet.CreateKeyPairAndExportPublicKeyResponse
-	10, // 21: wallet.WalletService.createKeyPairsWithAddresses:output_type -> wallet.CreateKeyPairsWithAddressesResponse
-	18, // 22: wallet.WalletService.signTransactionMessage:output_type -> wallet.SignTransactionMessageResponse
-	12, // 23: wallet.WalletService.buildAndSignTransaction:output_type -> wallet.BuildAndSignTransactionResponse
-	16, // 24: wallet.WalletService.buildAndSignBatchTransaction:output_type -> wallet.BuildAndSignBatchTransactionResponse
-	18, // [18:25] is the sub-list for method output_type
-	11, // [11:18] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+	0,  // 7: wallet.TransactionWithSign.code:type_name -> wallet.ReturnCode
+	13, // 8: wallet.BuildAndSignBatchTransactionRequest.tx_msg:type_name -> wallet.TransactionMessage
+	0,  // 9: wallet.BuildAndSignBatchTransactionResponse.code:type_name -> wallet.ReturnCode
+	14, // 10: wallet.BuildAndSignBatchTransactionResponse.tx_with_sign:type_name -> wallet.TransactionWithSign
+	0,  // 11: wallet.SignTransactionMessageResponse.Code:type_name -> wallet.ReturnCode
+	0,  // 12: wallet.SignPersonalMessageResponse.code:type_name -> wallet.ReturnCode
+	0,  // 13: wallet.SignTypedDataResponse.code:type_name -> wallet.ReturnCode
+	0,  // 14: wallet.BuildAndSignPermitResponse.code:type_name -> wallet.ReturnCode
+	0,  // 15: wallet.BuildAndSignUserOperationResponse.code:type_name -> wallet.ReturnCode
+	0,  // 16: wallet.BuildAndSignSafeTransactionResponse.code:type_name -> wallet.ReturnCode
+	0,  // 17: wallet.SignPsbtResponse.code:type_name -> wallet.ReturnCode
+	0,  // 18: wallet.CreateMultisigAddressResponse.code:type_name -> wallet.ReturnCode
+	0,  // 19: wallet.CreateMusig2AddressResponse.code:type_name -> wallet.ReturnCode
+	0,  // 20: wallet.Musig2NonceResponse.code:type_name -> wallet.ReturnCode
+	43, // 21: wallet.Musig2PartialSignRequest.pub_nonces:type_name -> wallet.Musig2PartialSignRequest.PubNoncesEntry
+	44, // 22: wallet.Musig2PartialSignRequest.partial_signatures:type_name -> wallet.Musig2PartialSignRequest.PartialSignaturesEntry
+	0,  // 23: wallet.Musig2PartialSignResponse.code:type_name -> wallet.ReturnCode
+	39, // 24: wallet.CreateTaprootAddressRequest.leaves:type_name -> wallet.TapLeaf
+	0,  // 25: wallet.CreateTaprootAddressResponse.code:type_name -> wallet.ReturnCode
+	40, // 26: wallet.CreateTaprootAddressResponse.leaves:type_name -> wallet.TapLeafProof
+	1,  // 27: wallet.WalletService.getChainSignMethod:input_type -> wallet.GetChainSignMethodRequest
+	3,  // 28: wallet.WalletService.getChainSchema:input_type -> wallet.getChainSchemaRequest
+	6,  // 29: wallet.WalletService.createKeyPairsExportPublicKeyList:input_type -> wallet.CreateKeyPairAndExportPublicKeyRequest
+	9,  // 30: wallet.WalletService.createKeyPairsWithAddresses:input_type -> wallet.CreateKeyPairsWithAddressesRequest
+	17, // 31: wallet.WalletService.signTransactionMessage:input_type -> wallet.SignTransactionMessageRequest
+	11, // 32: wallet.WalletService.buildAndSignTransaction:input_type -> wallet.BuildAndSignTransactionRequest
+	15, // 33: wallet.WalletService.buildAndSignBatchTransaction:input_type -> wallet.BuildAndSignBatchTransactionRequest
+	19, // 34: wallet.WalletService.signPersonalMessage:input_type -> wallet.SignPersonalMessageRequest
+	21, // 35: wallet.WalletService.signTypedData:input_type -> wallet.SignTypedDataRequest
+	23, // 36: wallet.WalletService.buildAndSignPermit:input_type -> wallet.BuildAndSignPermitRequest
+	25, // 37: wallet.WalletService.buildAndSignUserOperation:input_type -> wallet.BuildAndSignUserOperationRequest
+	27, // 38: wallet.WalletService.buildAndSignSafeTransaction:input_type -> wallet.BuildAndSignSafeTransactionRequest
+	29, // 39: wallet.WalletService.signPsbt:input_type -> wallet.SignPsbtRequest
+	31, // 40: wallet.WalletService.createMultisigAddress:input_type -> wallet.CreateMultisigAddressRequest
+	33, // 41: wallet.WalletService.createMusig2Address:input_type -> wallet.CreateMusig2AddressRequest
+	35, // 42: wallet.WalletService.musig2Nonce:input_type -> wallet.Musig2NonceRequest
+	37, // 43: wallet.WalletService.musig2PartialSign:input_type -> wallet.Musig2PartialSignRequest
+	41, // 44: wallet.WalletService.createTaprootAddress:input_type -> wallet.CreateTaprootAddressRequest
+	2,  // 45: wallet.WalletService.getChainSignMethod:output_type -> wallet.GetChainSignMethodResponse
+	4,  // 46: wallet.WalletService.getChainSchema:output_type -> wallet.getChainSchemaResponse
+	7,  // 47: wallet.WalletService.createKeyPairsExportPublicKeyList:output_type -> wallet.CreateKeyPairAndExportPublicKeyResponse
+	10, // 48: wallet.WalletService.createKeyPairsWithAddresses:output_type -> wallet.CreateKeyPairsWithAddressesResponse
+	18, // 49: wallet.WalletService.signTransactionMessage:output_type -> wallet.SignTransactionMessageResponse
+	12, // 50: wallet.WalletService.buildAndSignTransaction:output_type -> wallet.BuildAndSignTransactionResponse
+	16, // 51: wallet.WalletService.buildAndSignBatchTransaction:output_type -> wallet.BuildAndSignBatchTransactionResponse
+	20, // 52: wallet.WalletService.signPersonalMessage:output_type -> wallet.SignPersonalMessageResponse
+	22, // 53: wallet.WalletService.signTypedData:output_type -> wallet.SignTypedDataResponse
+	24, // 54: wallet.WalletService.buildAndSignPermit:output_type -> wallet.BuildAndSignPermitResponse
+	26, // 55: wallet.WalletService.buildAndSignUserOperation:output_type -> wallet.BuildAndSignUserOperationResponse
+	28, // 56: wallet.WalletService.buildAndSignSafeTransaction:output_type -> wallet.BuildAndSignSafeTransactionResponse
+	30, // 57: wallet.WalletService.signPsbt:output_type -> wallet.SignPsbtResponse
+	32, // 58: wallet.WalletService.createMultisigAddress:output_type -> wallet.CreateMultisigAddressResponse
+	34, // 59: wallet.WalletService.createMusig2Address:output_type -> wallet.CreateMusig2AddressResponse
+	36, // 60: wallet.WalletService.musig2Nonce:output_type -> wallet.Musig2NonceResponse
+	38, // 61: wallet.WalletService.musig2PartialSign:output_type -> wallet.Musig2PartialSignResponse
+	42, // 62: wallet.WalletService.createTaprootAddress:output_type -> wallet.CreateTaprootAddressResponse
+	45, // [45:63] is the sub-list for method output_type
+	27, // [27:45] is the sub-list for method input_type
+	27, // [27:27] is the sub-list for extension type_name
+	27, // [27:27] is the sub-list for extension extendee
+	0,  // [0:27] is the sub-list for field type_name
 }
 
 func init() { file_protobuf_wallet_proto_init() }
@@ -1458,7 +4082,7 @@ func file_protobuf_wallet_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_protobuf_wallet_proto_rawDesc), len(file_protobuf_wallet_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   18,
+			NumMessages:   44,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
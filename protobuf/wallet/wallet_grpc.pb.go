@@ -26,6 +26,17 @@ const (
 	WalletService_SignTransactionMessage_FullMethodName            = "/wallet.WalletService/signTransactionMessage"
 	WalletService_BuildAndSignTransaction_FullMethodName           = "/wallet.WalletService/buildAndSignTransaction"
 	WalletService_BuildAndSignBatchTransaction_FullMethodName      = "/wallet.WalletService/buildAndSignBatchTransaction"
+	WalletService_SignPersonalMessage_FullMethodName               = "/wallet.WalletService/signPersonalMessage"
+	WalletService_SignTypedData_FullMethodName                     = "/wallet.WalletService/signTypedData"
+	WalletService_BuildAndSignPermit_FullMethodName                = "/wallet.WalletService/buildAndSignPermit"
+	WalletService_BuildAndSignUserOperation_FullMethodName         = "/wallet.WalletService/buildAndSignUserOperation"
+	WalletService_BuildAndSignSafeTransaction_FullMethodName       = "/wallet.WalletService/buildAndSignSafeTransaction"
+	WalletService_SignPsbt_FullMethodName                          = "/wallet.WalletService/signPsbt"
+	WalletService_CreateMultisigAddress_FullMethodName             = "/wallet.WalletService/createMultisigAddress"
+	WalletService_CreateMusig2Address_FullMethodName               = "/wallet.WalletService/createMusig2Address"
+	WalletService_Musig2Nonce_FullMethodName                       = "/wallet.WalletService/musig2Nonce"
+	WalletService_Musig2PartialSign_FullMethodName                 = "/wallet.WalletService/musig2PartialSign"
+	WalletService_CreateTaprootAddress_FullMethodName              = "/wallet.WalletService/createTaprootAddress"
 )
 
 // WalletServiceClient is the client API for WalletService service.
@@ -41,6 +52,21 @@ type WalletServiceClient interface {
 	// 完整的签名流程
 	BuildAndSignTransaction(ctx context.Context, in *BuildAndSignTransactionRequest, opts ...grpc.CallOption) (*BuildAndSignTransactionResponse, error)
 	BuildAndSignBatchTransaction(ctx context.Context, in *BuildAndSignBatchTransactionRequest, opts ...grpc.CallOption) (*BuildAndSignBatchTransactionResponse, error)
+	// EIP-191 personal_sign 和 EIP-712 eth_signTypedData_v4, 由服务端计算摘要
+	SignPersonalMessage(ctx context.Context, in *SignPersonalMessageRequest, opts ...grpc.CallOption) (*SignPersonalMessageResponse, error)
+	SignTypedData(ctx context.Context, in *SignTypedDataRequest, opts ...grpc.CallOption) (*SignTypedDataResponse, error)
+	// EIP-2612 permit 和 Uniswap Permit2 授权签名
+	BuildAndSignPermit(ctx context.Context, in *BuildAndSignPermitRequest, opts ...grpc.CallOption) (*BuildAndSignPermitResponse, error)
+	// ERC-4337 UserOperation 签名
+	BuildAndSignUserOperation(ctx context.Context, in *BuildAndSignUserOperationRequest, opts ...grpc.CallOption) (*BuildAndSignUserOperationResponse, error)
+	// Safe 多签交易 owner 签名
+	BuildAndSignSafeTransaction(ctx context.Context, in *BuildAndSignSafeTransactionRequest, opts ...grpc.CallOption) (*BuildAndSignSafeTransactionResponse, error)
+	SignPsbt(ctx context.Context, in *SignPsbtRequest, opts ...grpc.CallOption) (*SignPsbtResponse, error)
+	CreateMultisigAddress(ctx context.Context, in *CreateMultisigAddressRequest, opts ...grpc.CallOption) (*CreateMultisigAddressResponse, error)
+	CreateMusig2Address(ctx context.Context, in *CreateMusig2AddressRequest, opts ...grpc.CallOption) (*CreateMusig2AddressResponse, error)
+	Musig2Nonce(ctx context.Context, in *Musig2NonceRequest, opts ...grpc.CallOption) (*Musig2NonceResponse, error)
+	Musig2PartialSign(ctx context.Context, in *Musig2PartialSignRequest, opts ...grpc.CallOption) (*Musig2PartialSignResponse, error)
+	CreateTaprootAddress(ctx context.Context, in *CreateTaprootAddressRequest, opts ...grpc.CallOption) (*CreateTaprootAddressResponse, error)
 }
 
 type walletServiceClient struct {
@@ -121,6 +147,116 @@ func (c *walletServiceClient) BuildAndSignBatchTransaction(ctx context.Context,
 	return out, nil
 }
 
+func (c *walletServiceClient) SignPersonalMessage(ctx context.Context, in *SignPersonalMessageRequest, opts ...grpc.CallOption) (*SignPersonalMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SignPersonalMessageResponse)
+	err := c.cc.Invoke(ctx, WalletService_SignPersonalMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) SignTypedData(ctx context.Context, in *SignTypedDataRequest, opts ...grpc.CallOption) (*SignTypedDataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SignTypedDataResponse)
+	err := c.cc.Invoke(ctx, WalletService_SignTypedData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) BuildAndSignPermit(ctx context.Context, in *BuildAndSignPermitRequest, opts ...grpc.CallOption) (*BuildAndSignPermitResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BuildAndSignPermitResponse)
+	err := c.cc.Invoke(ctx, WalletService_BuildAndSignPermit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) BuildAndSignUserOperation(ctx context.Context, in *BuildAndSignUserOperationRequest, opts ...grpc.CallOption) (*BuildAndSignUserOperationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BuildAndSignUserOperationResponse)
+	err := c.cc.Invoke(ctx, WalletService_BuildAndSignUserOperation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) BuildAndSignSafeTransaction(ctx context.Context, in *BuildAndSignSafeTransactionRequest, opts ...grpc.CallOption) (*BuildAndSignSafeTransactionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BuildAndSignSafeTransactionResponse)
+	err := c.cc.Invoke(ctx, WalletService_BuildAndSignSafeTransaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) SignPsbt(ctx context.Context, in *SignPsbtRequest, opts ...grpc.CallOption) (*SignPsbtResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SignPsbtResponse)
+	err := c.cc.Invoke(ctx, WalletService_SignPsbt_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) CreateMultisigAddress(ctx context.Context, in *CreateMultisigAddressRequest, opts ...grpc.CallOption) (*CreateMultisigAddressResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateMultisigAddressResponse)
+	err := c.cc.Invoke(ctx, WalletService_CreateMultisigAddress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) CreateMusig2Address(ctx context.Context, in *CreateMusig2AddressRequest, opts ...grpc.CallOption) (*CreateMusig2AddressResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateMusig2AddressResponse)
+	err := c.cc.Invoke(ctx, WalletService_CreateMusig2Address_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) Musig2Nonce(ctx context.Context, in *Musig2NonceRequest, opts ...grpc.CallOption) (*Musig2NonceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Musig2NonceResponse)
+	err := c.cc.Invoke(ctx, WalletService_Musig2Nonce_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) Musig2PartialSign(ctx context.Context, in *Musig2PartialSignRequest, opts ...grpc.CallOption) (*Musig2PartialSignResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Musig2PartialSignResponse)
+	err := c.cc.Invoke(ctx, WalletService_Musig2PartialSign_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) CreateTaprootAddress(ctx context.Context, in *CreateTaprootAddressRequest, opts ...grpc.CallOption) (*CreateTaprootAddressResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateTaprootAddressResponse)
+	err := c.cc.Invoke(ctx, WalletService_CreateTaprootAddress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // WalletServiceServer is the server API for WalletService service.
 // All implementations should embed UnimplementedWalletServiceServer
 // for forward compatibility.
@@ -134,6 +270,21 @@ type WalletServiceServer interface {
 	// 完整的签名流程
 	BuildAndSignTransaction(context.Context, *BuildAndSignTransactionRequest) (*BuildAndSignTransactionResponse, error)
 	BuildAndSignBatchTransaction(context.Context, *BuildAndSignBatchTransactionRequest) (*BuildAndSignBatchTransactionResponse, error)
+	// EIP-191 personal_sign 和 EIP-712 eth_signTypedData_v4, 由服务端计算摘要
+	SignPersonalMessage(context.Context, *SignPersonalMessageRequest) (*SignPersonalMessageResponse, error)
+	SignTypedData(context.Context, *SignTypedDataRequest) (*SignTypedDataResponse, error)
+	// EIP-2612 permit 和 Uniswap Permit2 授权签名
+	BuildAndSignPermit(context.Context, *BuildAndSignPermitRequest) (*BuildAndSignPermitResponse, error)
+	// ERC-4337 UserOperation 签名
+	BuildAndSignUserOperation(context.Context, *BuildAndSignUserOperationRequest) (*BuildAndSignUserOperationResponse, error)
+	// Safe 多签交易 owner 签名
+	BuildAndSignSafeTransaction(context.Context, *BuildAndSignSafeTransactionRequest) (*BuildAndSignSafeTransactionResponse, error)
+	SignPsbt(context.Context, *SignPsbtRequest) (*SignPsbtResponse, error)
+	CreateMultisigAddress(context.Context, *CreateMultisigAddressRequest) (*CreateMultisigAddressResponse, error)
+	CreateMusig2Address(context.Context, *CreateMusig2AddressRequest) (*CreateMusig2AddressResponse, error)
+	Musig2Nonce(context.Context, *Musig2NonceRequest) (*Musig2NonceResponse, error)
+	Musig2PartialSign(context.Context, *Musig2PartialSignRequest) (*Musig2PartialSignResponse, error)
+	CreateTaprootAddress(context.Context, *CreateTaprootAddressRequest) (*CreateTaprootAddressResponse, error)
 }
 
 // UnimplementedWalletServiceServer should be embedded to have
@@ -164,6 +315,39 @@ func (UnimplementedWalletServiceServer) BuildAndSignTransaction(context.Context,
 func (UnimplementedWalletServiceServer) BuildAndSignBatchTransaction(context.Context, *BuildAndSignBatchTransactionRequest) (*BuildAndSignBatchTransactionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BuildAndSignBatchTransaction not implemented")
 }
+func (UnimplementedWalletServiceServer) SignPersonalMessage(context.Context, *SignPersonalMessageRequest) (*SignPersonalMessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SignPersonalMessage not implemented")
+}
+func (UnimplementedWalletServiceServer) SignTypedData(context.Context, *SignTypedDataRequest) (*SignTypedDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SignTypedData not implemented")
+}
+func (UnimplementedWalletServiceServer) BuildAndSignPermit(context.Context, *BuildAndSignPermitRequest) (*BuildAndSignPermitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuildAndSignPermit not implemented")
+}
+func (UnimplementedWalletServiceServer) BuildAndSignUserOperation(context.Context, *BuildAndSignUserOperationRequest) (*BuildAndSignUserOperationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuildAndSignUserOperation not implemented")
+}
+func (UnimplementedWalletServiceServer) BuildAndSignSafeTransaction(context.Context, *BuildAndSignSafeTransactionRequest) (*BuildAndSignSafeTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuildAndSignSafeTransaction not implemented")
+}
+func (UnimplementedWalletServiceServer) SignPsbt(context.Context, *SignPsbtRequest) (*SignPsbtResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SignPsbt not implemented")
+}
+func (UnimplementedWalletServiceServer) CreateMultisigAddress(context.Context, *CreateMultisigAddressRequest) (*CreateMultisigAddressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateMultisigAddress not implemented")
+}
+func (UnimplementedWalletServiceServer) CreateMusig2Address(context.Context, *CreateMusig2AddressRequest) (*CreateMusig2AddressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateMusig2Address not implemented")
+}
+func (UnimplementedWalletServiceServer) Musig2Nonce(context.Context, *Musig2NonceRequest) (*Musig2NonceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Musig2Nonce not implemented")
+}
+func (UnimplementedWalletServiceServer) Musig2PartialSign(context.Context, *Musig2PartialSignRequest) (*Musig2PartialSignResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Musig2PartialSign not implemented")
+}
+func (UnimplementedWalletServiceServer) CreateTaprootAddress(context.Context, *CreateTaprootAddressRequest) (*CreateTaprootAddressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTaprootAddress not implemented")
+}
 func (UnimplementedWalletServiceServer) testEmbeddedByValue() {}
 
 // UnsafeWalletServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -310,6 +494,204 @@ func _WalletService_BuildAndSignBatchTransaction_Handler(srv interface{}, ctx co
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WalletService_SignPersonalMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignPersonalMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).SignPersonalMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletService_SignPersonalMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).SignPersonalMessage(ctx, req.(*SignPersonalMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_SignTypedData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignTypedDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).SignTypedData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletService_SignTypedData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).SignTypedData(ctx, req.(*SignTypedDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_BuildAndSignPermit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BuildAndSignPermitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).BuildAndSignPermit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletService_BuildAndSignPermit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).BuildAndSignPermit(ctx, req.(*BuildAndSignPermitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_BuildAndSignUserOperation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BuildAndSignUserOperationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).BuildAndSignUserOperation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletService_BuildAndSignUserOperation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).BuildAndSignUserOperation(ctx, req.(*BuildAndSignUserOperationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_BuildAndSignSafeTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BuildAndSignSafeTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).BuildAndSignSafeTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletService_BuildAndSignSafeTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).BuildAndSignSafeTransaction(ctx, req.(*BuildAndSignSafeTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_SignPsbt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignPsbtRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).SignPsbt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletService_SignPsbt_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).SignPsbt(ctx, req.(*SignPsbtRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_CreateMultisigAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateMultisigAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).CreateMultisigAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletService_CreateMultisigAddress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).CreateMultisigAddress(ctx, req.(*CreateMultisigAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_CreateMusig2Address_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateMusig2AddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).CreateMusig2Address(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletService_CreateMusig2Address_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).CreateMusig2Address(ctx, req.(*CreateMusig2AddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_Musig2Nonce_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Musig2NonceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).Musig2Nonce(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletService_Musig2Nonce_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).Musig2Nonce(ctx, req.(*Musig2NonceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_Musig2PartialSign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Musig2PartialSignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).Musig2PartialSign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletService_Musig2PartialSign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).Musig2PartialSign(ctx, req.(*Musig2PartialSignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_CreateTaprootAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTaprootAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).CreateTaprootAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletService_CreateTaprootAddress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).CreateTaprootAddress(ctx, req.(*CreateTaprootAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // WalletService_ServiceDesc is the grpc.ServiceDesc for WalletService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -345,6 +727,50 @@ var WalletService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "buildAndSignBatchTransaction",
 			Handler:    _WalletService_BuildAndSignBatchTransaction_Handler,
 		},
+		{
+			MethodName: "signPersonalMessage",
+			Handler:    _WalletService_SignPersonalMessage_Handler,
+		},
+		{
+			MethodName: "signTypedData",
+			Handler:    _WalletService_SignTypedData_Handler,
+		},
+		{
+			MethodName: "buildAndSignPermit",
+			Handler:    _WalletService_BuildAndSignPermit_Handler,
+		},
+		{
+			MethodName: "buildAndSignUserOperation",
+			Handler:    _WalletService_BuildAndSignUserOperation_Handler,
+		},
+		{
+			MethodName: "buildAndSignSafeTransaction",
+			Handler:    _WalletService_BuildAndSignSafeTransaction_Handler,
+		},
+		{
+			MethodName: "signPsbt",
+			Handler:    _WalletService_SignPsbt_Handler,
+		},
+		{
+			MethodName: "createMultisigAddress",
+			Handler:    _WalletService_CreateMultisigAddress_Handler,
+		},
+		{
+			MethodName: "createMusig2Address",
+			Handler:    _WalletService_CreateMusig2Address_Handler,
+		},
+		{
+			MethodName: "musig2Nonce",
+			Handler:    _WalletService_Musig2Nonce_Handler,
+		},
+		{
+			MethodName: "musig2PartialSign",
+			Handler:    _WalletService_Musig2PartialSign_Handler,
+		},
+		{
+			MethodName: "createTaprootAddress",
+			Handler:    _WalletService_CreateTaprootAddress_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "protobuf/wallet.proto",
@@ -0,0 +1,67 @@
+package ssm
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const mnemonicEntropyBits = 256
+
+// HDSigner derives key pairs from a BIP39 seed instead of random bytes.
+type HDSigner interface {
+	Signer
+	DeriveKeyPair(seed []byte, path string) (privateKey string, publicKey string, compressPubKey string, err error)
+}
+
+// NewMnemonic creates a 24 word BIP39 mnemonic.
+func NewMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(mnemonicEntropyBits)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// MnemonicToSeed checks the mnemonic words and checksum and returns the seed.
+func MnemonicToSeed(mnemonic string, passphrase string) ([]byte, error) {
+	return bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+}
+
+// DeriveKeyPair derives the BIP32 secp256k1 key at path, for example
+// m/44'/60'/0'/0/0.
+func (ecdsa *ECDSASigner) DeriveKeyPair(seed []byte, path string) (string, string, string, error) {
+	derivationPath, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		log.Error("parse derivation path fail", "err", err, "path", path)
+		return EmptyHexString, EmptyHexString, EmptyHexString, err
+	}
+	// the network params only matter for xprv serialization, never used here
+	key, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		log.Error("new master key fail", "err", err)
+		return EmptyHexString, EmptyHexString, EmptyHexString, err
+	}
+	for _, index := range derivationPath {
+		key, err = key.Derive(index)
+		if err != nil {
+			log.Error("derive child key fail", "err", err, "path", path)
+			return EmptyHexString, EmptyHexString, EmptyHexString, fmt.Errorf("derive %s fail: %w", path, err)
+		}
+	}
+	privKey, err := key.ECPrivKey()
+	if err != nil {
+		return EmptyHexString, EmptyHexString, EmptyHexString, err
+	}
+	privateKey := privKey.ToECDSA()
+	priKeyStr := hex.EncodeToString(crypto.FromECDSA(privateKey))
+	pubKeyStr := hex.EncodeToString(crypto.FromECDSAPub(&privateKey.PublicKey))
+	compressPubkeyStr := hex.EncodeToString(crypto.CompressPubkey(&privateKey.PublicKey))
+	return priKeyStr, pubKeyStr, compressPubkeyStr, nil
+}
@@ -0,0 +1,34 @@
+package ssm
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// TestECDSADeriveKeyPairBIP32 checks secp256k1 derivation against test vector
+// 1 of BIP32.
+func TestECDSADeriveKeyPairBIP32(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	tests := []struct {
+		path       string
+		privateKey string
+	}{
+		{path: "m/0'", privateKey: "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{path: "m/0'/1", privateKey: "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{path: "m/0'/1/2'", privateKey: "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+		{path: "m/0'/1/2'/2", privateKey: "0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4"},
+		{path: "m/0'/1/2'/2/1000000000", privateKey: "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	}
+	signer := &ECDSASigner{}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			priKey, _, _, err := signer.DeriveKeyPair(seed, tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if priKey != tt.privateKey {
+				t.Fatalf("private key %s, want %s", priKey, tt.privateKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package ssm
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr/musig2"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Musig2Signer runs the local side of BIP327 MuSig2 signing over the sorted
+// key set of a taproot output. Like TaprootSigner, only the software signer
+// implements it.
+type Musig2Signer interface {
+	Signer
+	Musig2Nonce(privateKey string, msgHash string) (secNonce string, pubNonce string, err error)
+	SignMusig2(privateKey string, secNonce string, publicKeys []string, pubNonces []string, msgHash string, merkleRoot string) (partialSig string, finalNonce string, err error)
+}
+
+// Musig2Nonce generates a fresh nonce pair of the key for msgHash, the caller
+// must keep the secret nonce for exactly one SignMusig2 call.
+func (ecdsa *ECDSASigner) Musig2Nonce(privateKey string, msgHash string) (string, string, error) {
+	privKey, err := parsePrivKey(privateKey)
+	if err != nil {
+		return EmptyHexString, EmptyHexString, err
+	}
+	msg, err := decodeMsgHash(msgHash)
+	if err != nil {
+		return EmptyHexString, EmptyHexString, err
+	}
+	nonces, err := musig2.GenNonces(
+		musig2.WithPublicKey(privKey.PubKey()),
+		musig2.WithNonceSecretKeyAux(privKey),
+		musig2.WithNonceMessageAux(msg),
+	)
+	if err != nil {
+		log.Error("generate musig2 nonces fail", "err", err)
+		return EmptyHexString, EmptyHexString, err
+	}
+	return hex.EncodeToString(nonces.SecNonce[:]), hex.EncodeToString(nonces.PubNonce[:]), nil
+}
+
+// SignMusig2 signs msgHash for the aggregate of publicKeys tweaked for the
+// hex script tree merkleRoot, empty for BIP86. It returns the 32 byte partial
+// signature and the final nonce R that the partial signatures combine with.
+func (ecdsa *ECDSASigner) SignMusig2(privateKey string, secNonce string, publicKeys []string, pubNonces []string, msgHash string, merkleRoot string) (string, string, error) {
+	privKey, err := parsePrivKey(privateKey)
+	if err != nil {
+		return EmptyHexString, EmptyHexString, err
+	}
+	msg, err := decodeMsgHash(msgHash)
+	if err != nil {
+		return EmptyHexString, EmptyHexString, err
+	}
+	secNonceBytes, err := hex.DecodeString(secNonce)
+	if err != nil || len(secNonceBytes) != musig2.SecNonceSize {
+		return EmptyHexString, EmptyHexString, errors.New("invalid secret nonce")
+	}
+	var nonce [musig2.SecNonceSize]byte
+	copy(nonce[:], secNonceBytes)
+	keys := make([]*btcec.PublicKey, len(publicKeys))
+	for i, publicKey := range publicKeys {
+		pubKeyBytes, err := hex.DecodeString(publicKey)
+		if err != nil {
+			return EmptyHexString, EmptyHexString, fmt.Errorf("invalid public key: %s", publicKey)
+		}
+		if keys[i], err = btcec.ParsePubKey(pubKeyBytes); err != nil {
+			return EmptyHexString, EmptyHexString, fmt.Errorf("parse public key %s fail: %w", publicKey, err)
+		}
+	}
+	nonces := make([][musig2.PubNonceSize]byte, len(pubNonces))
+	for i, pubNonce := range pubNonces {
+		b, err := hex.DecodeString(pubNonce)
+		if err != nil || len(b) != musig2.PubNonceSize {
+			return EmptyHexString, EmptyHexString, fmt.Errorf("invalid public nonce: %s", pubNonce)
+		}
+		copy(nonces[i][:], b)
+	}
+	combinedNonce, err := musig2.AggregateNonces(nonces)
+	if err != nil {
+		return EmptyHexString, EmptyHexString, err
+	}
+	signOpts := []musig2.SignOption{musig2.WithSortedKeys(), musig2.WithBip86SignTweak()}
+	if merkleRoot != "" {
+		scriptRoot, err := hex.DecodeString(merkleRoot)
+		if err != nil || len(scriptRoot) != 32 {
+			return EmptyHexString, EmptyHexString, fmt.Errorf("invalid merkle root: %s", merkleRoot)
+		}
+		signOpts[1] = musig2.WithTaprootSignTweak(scriptRoot)
+	}
+	partialSig, err := musig2.Sign(nonce, privKey, combinedNonce, keys, msg, signOpts...)
+	if err != nil {
+		log.Error("musig2 sign fail", "err", err)
+		return EmptyHexString, EmptyHexString, err
+	}
+	var buf bytes.Buffer
+	if err := partialSig.Encode(&buf); err != nil {
+		return EmptyHexString, EmptyHexString, err
+	}
+	return hex.EncodeToString(buf.Bytes()), hex.EncodeToString(partialSig.R.SerializeCompressed()), nil
+}
+
+func parsePrivKey(privateKey string) (*btcec.PrivateKey, error) {
+	privateKeyByte, err := hex.DecodeString(privateKey)
+	if err != nil {
+		log.Error("decode private key fail", "err", err)
+		return nil, err
+	}
+	privKey, _ := btcec.PrivKeyFromBytes(privateKeyByte)
+	return privKey, nil
+}
+
+func decodeMsgHash(msgHash string) ([32]byte, error) {
+	var msg [32]byte
+	hash, err := hex.DecodeString(msgHash)
+	if err != nil || len(hash) != 32 {
+		return msg, fmt.Errorf("invalid message hash: %s", msgHash)
+	}
+	copy(msg[:], hash)
+	return msg, nil
+}
@@ -0,0 +1,328 @@
+package ssm
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/miekg/pkcs11"
+)
+
+// PKCS#11 v3.0 edwards curve identifiers, not exported by miekg/pkcs11
+const (
+	ckkEcEdwards           = 0x00000040
+	ckmEcEdwardsKeyPairGen = 0x00001055
+	ckmEddsa               = 0x00001057
+)
+
+const hsmKeyIdLen = 16
+
+var (
+	// secp256k1 named curve, OID 1.3.132.0.10
+	secp256k1Params = []byte{0x06, 0x05, 0x2b, 0x81, 0x04, 0x00, 0x0a}
+	// ed25519 named curve, OID 1.3.101.112
+	ed25519Params = []byte{0x06, 0x03, 0x2b, 0x65, 0x70}
+
+	secp256k1HalfN = new(big.Int).Rsh(crypto.S256().Params().N, 1)
+)
+
+// Hsm is a logged in session on a PKCS#11 token. A module can only be
+// initialized once per process, so every signer shares the same Hsm.
+type Hsm struct {
+	mu      sync.Mutex
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+	label   string
+}
+
+var (
+	hsmLock     sync.Mutex
+	hsmSessions = make(map[string]*Hsm)
+)
+
+// OpenHsm loads the PKCS#11 module and logs in to the token with the given
+// label, sessions are cached per module and token.
+func OpenHsm(module string, tokenLabel string, pin string) (*Hsm, error) {
+	hsmLock.Lock()
+	defer hsmLock.Unlock()
+	cacheKey := module + "|" + tokenLabel
+	if hsm, ok := hsmSessions[cacheKey]; ok {
+		return hsm, nil
+	}
+	ctx := pkcs11.New(module)
+	if ctx == nil {
+		return nil, fmt.Errorf("load pkcs11 module fail: %s", module)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("initialize pkcs11 module fail: %w", err)
+	}
+	slot, err := findTokenSlot(ctx, tokenLabel)
+	if err != nil {
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, err
+	}
+	session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, fmt.Errorf("open pkcs11 session fail: %w", err)
+	}
+	if err := ctx.Login(session, pkcs11.CKU_USER, pin); err != nil && !errors.Is(err, pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN)) {
+		ctx.CloseSession(session)
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, fmt.Errorf("login pkcs11 token fail: %w", err)
+	}
+	hsm := &Hsm{
+		ctx:     ctx,
+		session: session,
+		label:   tokenLabel,
+	}
+	hsmSessions[cacheKey] = hsm
+	log.Info("pkcs11 token opened", "module", module, "token", tokenLabel, "slot", slot)
+	return hsm, nil
+}
+
+func findTokenSlot(ctx *pkcs11.Ctx, tokenLabel string) (uint, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("get pkcs11 slot list fail: %w", err)
+	}
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			continue
+		}
+		if strings.TrimSpace(info.Label) == tokenLabel {
+			return slot, nil
+		}
+	}
+	return 0, fmt.Errorf("pkcs11 token not found: %s", tokenLabel)
+}
+
+func (h *Hsm) generateKeyPair(mechanism uint, keyType uint, ecParams []byte) ([]byte, []byte, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	keyId, err := h.ctx.GenerateRandom(h.session, hsmKeyIdLen)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate key id fail: %w", err)
+	}
+	label := h.label + "-" + hex.EncodeToString(keyId)
+	publicTemplate := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, keyType),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, ecParams),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, keyId),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	privateTemplate := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, keyType),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, keyId),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	pubHandle, _, err := h.ctx.GenerateKeyPair(h.session,
+		[]*pkcs11.Mechanism{pkcs11.NewMechanism(mechanism, nil)},
+		publicTemplate, privateTemplate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate key pair in token fail: %w", err)
+	}
+	point, err := h.ecPoint(pubHandle)
+	if err != nil {
+		return nil, nil, err
+	}
+	return keyId, point, nil
+}
+
+func (h *Hsm) ecPoint(pubHandle pkcs11.ObjectHandle) ([]byte, error) {
+	attrs, err := h.ctx.GetAttributeValue(h.session, pubHandle, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get public key point fail: %w", err)
+	}
+	// CKA_EC_POINT is a DER octet string, some tokens return the raw point
+	var point []byte
+	if rest, err := asn1.Unmarshal(attrs[0].Value, &point); err != nil || len(rest) != 0 {
+		point = attrs[0].Value
+	}
+	return point, nil
+}
+
+func (h *Hsm) findObject(class uint, keyId []byte) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, keyId),
+	}
+	if err := h.ctx.FindObjectsInit(h.session, template); err != nil {
+		return 0, err
+	}
+	defer h.ctx.FindObjectsFinal(h.session)
+	objects, _, err := h.ctx.FindObjects(h.session, 1)
+	if err != nil {
+		return 0, err
+	}
+	if len(objects) == 0 {
+		return 0, fmt.Errorf("key not found in token: %x", keyId)
+	}
+	return objects[0], nil
+}
+
+func (h *Hsm) sign(mechanism uint, keyId []byte, msg []byte) ([]byte, []byte, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	privHandle, err := h.findObject(pkcs11.CKO_PRIVATE_KEY, keyId)
+	if err != nil {
+		return nil, nil, err
+	}
+	pubHandle, err := h.findObject(pkcs11.CKO_PUBLIC_KEY, keyId)
+	if err != nil {
+		return nil, nil, err
+	}
+	point, err := h.ecPoint(pubHandle)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := h.ctx.SignInit(h.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(mechanism, nil)}, privHandle); err != nil {
+		return nil, nil, fmt.Errorf("init token sign fail: %w", err)
+	}
+	signature, err := h.ctx.Sign(h.session, msg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("token sign fail: %w", err)
+	}
+	return signature, point, nil
+}
+
+// HsmECDSASigner keeps secp256k1 keys inside the token, the private key
+// strings it hands out are hex encoded CKA_ID key handles.
+type HsmECDSASigner struct {
+	hsm *Hsm
+}
+
+func NewHsmECDSASigner(hsm *Hsm) *HsmECDSASigner {
+	return &HsmECDSASigner{hsm: hsm}
+}
+
+func (s *HsmECDSASigner) CreateKeyPair() (string, string, string, error) {
+	keyId, point, err := s.hsm.generateKeyPair(pkcs11.CKM_EC_KEY_PAIR_GEN, pkcs11.CKK_EC, secp256k1Params)
+	if err != nil {
+		log.Error("generate key in token fail", "err", err)
+		return EmptyHexString, EmptyHexString, EmptyHexString, err
+	}
+	pubKey, err := crypto.UnmarshalPubkey(point)
+	if err != nil {
+		log.Error("parse token public key fail", "err", err)
+		return EmptyHexString, EmptyHexString, EmptyHexString, err
+	}
+	return hex.EncodeToString(keyId), hex.EncodeToString(point), hex.EncodeToString(crypto.CompressPubkey(pubKey)), nil
+}
+
+// SignMessage returns the same 65 byte [R || S || V] signature as
+// ECDSASigner, the token only gives R || S so S is normalized and the
+// recovery id is found against the token public key.
+func (s *HsmECDSASigner) SignMessage(keyHandle string, txMsg string) (string, error) {
+	txHash := common.HexToHash(txMsg)
+	keyId, err := hex.DecodeString(keyHandle)
+	if err != nil {
+		log.Error("decode key handle fail", "err", err)
+		return EmptyHexString, err
+	}
+	rs, point, err := s.hsm.sign(pkcs11.CKM_ECDSA, keyId, txHash[:])
+	if err != nil {
+		log.Error("sign transaction in token fail", "err", err)
+		return EmptyHexString, err
+	}
+	if len(rs) != 64 {
+		return EmptyHexString, fmt.Errorf("invalid token signature length: %d", len(rs))
+	}
+	sigS := new(big.Int).SetBytes(rs[32:])
+	if sigS.Cmp(secp256k1HalfN) > 0 {
+		sigS.Sub(crypto.S256().Params().N, sigS)
+		sigS.FillBytes(rs[32:])
+	}
+	signature := append(rs, 0)
+	for v := byte(0); v < 2; v++ {
+		signature[64] = v
+		recovered, err := crypto.Ecrecover(txHash[:], signature)
+		if err == nil && bytes.Equal(recovered, point) {
+			return hex.EncodeToString(signature), nil
+		}
+	}
+	return EmptyHexString, errors.New("recover token signature fail")
+}
+
+func (s *HsmECDSASigner) VerifySignature(publicKey, txHash, signature string) (bool, error) {
+	return (&ECDSASigner{}).VerifySignature(publicKey, txHash, signature)
+}
+
+// HsmEdDSASigner keeps ed25519 keys inside the token.
+type HsmEdDSASigner struct {
+	hsm *Hsm
+}
+
+func NewHsmEdDSASigner(hsm *Hsm) *HsmEdDSASigner {
+	return &HsmEdDSASigner{hsm: hsm}
+}
+
+func (s *HsmEdDSASigner) CreateKeyPair() (string, string, string, error) {
+	keyId, point, err := s.hsm.generateKeyPair(ckmEcEdwardsKeyPairGen, ckkEcEdwards, ed25519Params)
+	if err != nil {
+		log.Error("generate key in token fail", "err", err)
+		return EmptyHexString, EmptyHexString, EmptyHexString, err
+	}
+	if len(point) != ed25519.PublicKeySize {
+		return EmptyHexString, EmptyHexString, EmptyHexString, fmt.Errorf("invalid token public key length: %d", len(point))
+	}
+	return hex.EncodeToString(keyId), hex.EncodeToString(point), hex.EncodeToString(point), nil
+}
+
+func (s *HsmEdDSASigner) SignMessage(keyHandle string, txMsg string) (string, error) {
+	keyId, err := hex.DecodeString(keyHandle)
+	if err != nil {
+		log.Error("Decode key handle fail", "err", err)
+		return "", err
+	}
+	txMsgByte, err := hex.DecodeString(txMsg)
+	if err != nil {
+		log.Error("Decode tx message fail", "err", err)
+		return "", err
+	}
+	signature, _, err := s.hsm.sign(ckmEddsa, keyId, txMsgByte)
+	if err != nil {
+		log.Error("sign message in token fail", "err", err)
+		return "", err
+	}
+	return hex.EncodeToString(signature), nil
+}
+
+func (s *HsmEdDSASigner) VerifySignature(pubKey, msgHash, sig string) (bool, error) {
+	return (&EdDSASigner{}).VerifySignature(pubKey, msgHash, sig)
+}
+
+// readPin loads the token user PIN from the credentials file.
+func readPin(credentialsFile string) (string, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return "", fmt.Errorf("read hsm credentials file fail: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,48 @@
+package ssm
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const slip10HardenedOffset = 0x80000000
+
+var slip10Ed25519Curve = []byte("ed25519 seed")
+
+// DeriveKeyPair derives the SLIP-10 ed25519 key at path. Ed25519 only has
+// hardened children, so every path element must be hardened, for example
+// m/44'/501'/0'/0' which Phantom and solana-keygen use for the first account.
+func (eddsa *EdDSASigner) DeriveKeyPair(seed []byte, path string) (string, string, string, error) {
+	derivationPath, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		log.Error("parse derivation path fail", "err", err, "path", path)
+		return EmptyHexString, EmptyHexString, EmptyHexString, err
+	}
+	mac := hmac.New(sha512.New, slip10Ed25519Curve)
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := sum[:32], sum[32:]
+	for _, index := range derivationPath {
+		if index < slip10HardenedOffset {
+			return EmptyHexString, EmptyHexString, EmptyHexString, fmt.Errorf("ed25519 only supports hardened derivation: %s", path)
+		}
+		data := make([]byte, 0, 37)
+		data = append(data, 0x00)
+		data = append(data, key...)
+		data = binary.BigEndian.AppendUint32(data, index)
+		mac = hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum = mac.Sum(nil)
+		key, chainCode = sum[:32], sum[32:]
+	}
+	privateKey := ed25519.NewKeyFromSeed(key)
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+	return hex.EncodeToString(privateKey), hex.EncodeToString(publicKey), hex.EncodeToString(publicKey), nil
+}
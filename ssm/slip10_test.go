@@ -0,0 +1,66 @@
+package ssm
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// TestEdDSADeriveKeyPairSLIP10 checks ed25519 derivation against test vector 1
+// of SLIP-10.
+func TestEdDSADeriveKeyPairSLIP10(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	tests := []struct {
+		path       string
+		privateKey string
+		publicKey  string
+	}{
+		{
+			path:       "m/0'",
+			privateKey: "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+			publicKey:  "8c8a13df77a28f3445213a0f432fde644acaa215fc72dcdf300d5efaa85d350c",
+		},
+		{
+			path:       "m/0'/1'",
+			privateKey: "b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2",
+			publicKey:  "1932a5270f335bed617d5b935c80aedb1a35bd9fc1e31acafd5372c30f5c1187",
+		},
+		{
+			path:       "m/0'/1'/2'",
+			privateKey: "92a5b23c0b8a99e37d07df3fb9966917f5d06e02ddbd909c7e184371463e9fc9",
+			publicKey:  "ae98736566d30ed0e9d2f4486a64bc95740d89c7db33f52121f8ea8f76ff0fc1",
+		},
+		{
+			path:       "m/0'/1'/2'/2'",
+			privateKey: "30d1dc7e5fc04c31219ab25a27ae00b50f6fd66622f6e9c913253d6511d1e662",
+			publicKey:  "8abae2d66361c879b900d204ad2cc4984fa2aa344dd7ddc46007329ac76c429c",
+		},
+		{
+			path:       "m/0'/1'/2'/2'/1000000000'",
+			privateKey: "8f94d394a8e8fd6b1bc2f3f49f5c47e385281d5c17e65324b0f62483e37e8793",
+			publicKey:  "3c24da049451555d51a7014a37337aa4e12d41e485abccfa46b47dfb2af54b7a",
+		},
+	}
+	signer := &EdDSASigner{}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			priKey, pubKey, _, err := signer.DeriveKeyPair(seed, tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the private key is the ed25519 seed followed by the public key
+			if priKey != tt.privateKey+tt.publicKey {
+				t.Fatalf("private key %s, want %s", priKey, tt.privateKey)
+			}
+			if pubKey != tt.publicKey {
+				t.Fatalf("public key %s, want %s", pubKey, tt.publicKey)
+			}
+		})
+	}
+}
+
+func TestEdDSADeriveKeyPairRejectsNonHardened(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	if _, _, _, err := (&EdDSASigner{}).DeriveKeyPair(seed, "m/44'/501'/0'/0"); err == nil {
+		t.Fatal("non hardened ed25519 path derived")
+	}
+}
@@ -1,7 +1,48 @@
 package ssm
 
+import (
+	"errors"
+
+	"github.com/DQYXACML/wallet-sign/config"
+)
+
 type Signer interface {
 	CreateKeyPair() (privateKey string, publicKey string, compressPubKey string, err error)
 	SignMessage(privateKey string, msg string) (signature string, err error)
 	VerifySignature(pubKey string, msgHash string, signature string) (bool, error)
 }
+
+// NewECDSASigner returns the secp256k1 signer for the configured key backend.
+func NewECDSASigner(conf *config.Config) (Signer, error) {
+	if !conf.HsmEnable {
+		return &ECDSASigner{}, nil
+	}
+	hsm, err := openConfiguredHsm(conf)
+	if err != nil {
+		return nil, err
+	}
+	return NewHsmECDSASigner(hsm), nil
+}
+
+// NewEdDSASigner returns the ed25519 signer for the configured key backend.
+func NewEdDSASigner(conf *config.Config) (Signer, error) {
+	if !conf.HsmEnable {
+		return &EdDSASigner{}, nil
+	}
+	hsm, err := openConfiguredHsm(conf)
+	if err != nil {
+		return nil, err
+	}
+	return NewHsmEdDSASigner(hsm), nil
+}
+
+func openConfiguredHsm(conf *config.Config) (*Hsm, error) {
+	if conf.HsmModulePath == "" || conf.KeyName == "" {
+		return nil, errors.New("hsm_module_path and key_name are required when hsm_enable is set")
+	}
+	pin, err := readPin(conf.CredentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	return OpenHsm(conf.HsmModulePath, conf.KeyName, pin)
+}
@@ -0,0 +1,66 @@
+package ssm
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// TaprootSigner signs BIP340 schnorr signatures for taproot spends. Tokens
+// have no schnorr mechanism, so only the software signer implements it.
+type TaprootSigner interface {
+	Signer
+	SignTaproot(privateKey string, msgHash string, merkleRoot string) (signature string, err error)
+	SignSchnorr(privateKey string, msgHash string) (signature string, err error)
+}
+
+// SignTaproot signs msgHash with the BIP341 key path tweak of the key for the
+// hex script tree merkleRoot, empty for a BIP86 key without script tree.
+func (ecdsa *ECDSASigner) SignTaproot(privateKey string, msgHash string, merkleRoot string) (string, error) {
+	privateKeyByte, err := hex.DecodeString(privateKey)
+	if err != nil {
+		log.Error("decode private key fail", "err", err)
+		return EmptyHexString, err
+	}
+	hash, err := hex.DecodeString(msgHash)
+	if err != nil || len(hash) != 32 {
+		return EmptyHexString, fmt.Errorf("invalid message hash: %s", msgHash)
+	}
+	var scriptRoot []byte
+	if merkleRoot != "" {
+		if scriptRoot, err = hex.DecodeString(merkleRoot); err != nil || len(scriptRoot) != 32 {
+			return EmptyHexString, fmt.Errorf("invalid merkle root: %s", merkleRoot)
+		}
+	}
+	privKey, _ := btcec.PrivKeyFromBytes(privateKeyByte)
+	tweakedKey := txscript.TweakTaprootPrivKey(*privKey, scriptRoot)
+	signature, err := schnorr.Sign(tweakedKey, hash)
+	if err != nil {
+		log.Error("schnorr sign fail", "err", err)
+		return EmptyHexString, err
+	}
+	return hex.EncodeToString(signature.Serialize()), nil
+}
+
+// SignSchnorr signs msgHash with the untweaked key, as a key of a tapscript
+// leaf signs in a script path spend.
+func (ecdsa *ECDSASigner) SignSchnorr(privateKey string, msgHash string) (string, error) {
+	privKey, err := parsePrivKey(privateKey)
+	if err != nil {
+		return EmptyHexString, err
+	}
+	hash, err := decodeMsgHash(msgHash)
+	if err != nil {
+		return EmptyHexString, err
+	}
+	signature, err := schnorr.Sign(privKey, hash[:])
+	if err != nil {
+		log.Error("schnorr sign fail", "err", err)
+		return EmptyHexString, err
+	}
+	return hex.EncodeToString(signature.Serialize()), nil
+}